@@ -0,0 +1,251 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/leep-frog/command/cache"
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/command/sourcerer"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// defaultAliases are the built-in aliases (from alias to `g` args).
+	defaultAliases = map[string][]string{
+		"gp": {"p"},
+		// Don't include 'gl' since that is an alias of goleep
+		"gpl":  {"pl"},
+		"gs":   {"s"},
+		"guco": {"uco"},
+		"gb":   {"b"},
+		"gc":   {"c"},
+		"gcnv": {"c", "-n"},
+		"cm":   {"m"},
+		"gcb":  {"ch"},
+		"gnb":  {"ch", "-n"},
+		"gbn":  {"ch", "-n"},
+		"gmm":  {"mm"},
+		"mm":   {"mm"},
+		"gcp":  {"cp"},
+		"gd":   {"d"},
+		"gdm":  {"d", "-m"},
+		"ga":   {"a"},
+		"guc":  {"uc"},
+		"gua":  {"ua"},
+		"ch":   {"ch"},
+		"sq":   {"q"},
+		"gbd":  {"bd"},
+		"glg":  {"lg"},
+		"gam":  {"am"},
+		"gop":  {"op"},
+		"gush": {"ush"},
+	}
+
+	aliasNameArg   = commander.Arg[string]("ALIAS", "Name of the alias", commander.MatchesRegex("^[a-zA-Z0-9_-]+$"))
+	aliasArgsArg   = commander.ListArg[string]("ARGS", "Args to pass to the `g` command", 1, command.UnboundedList)
+	forceAliasFlag = commander.BoolFlag("force", 'f', "Whether or not to add the alias even if it conflicts with an existing command")
+
+	// lookPath is a stub for exec.LookPath
+	lookPath = exec.LookPath
+	// shellType is a stub for describing a command with the user's shell (which,
+	// unlike `lookPath`, also finds shell aliases, functions, and builtins). An
+	// error is returned if the shell doesn't recognize the command.
+	shellType = func(name string) (string, error) {
+		var cmd *exec.Cmd
+		if sourcerer.CurrentOS.Name() == "windows" {
+			cmd = exec.Command("powershell", "-Command", fmt.Sprintf("(Get-Command %s -ErrorAction Stop).CommandType", name))
+		} else {
+			shell, ok := os.LookupEnv("SHELL")
+			if !ok {
+				shell = "bash"
+			}
+			// Interactive, so the user's rc file (with their aliases) is loaded.
+			cmd = exec.Command(shell, "-ic", "type "+name)
+		}
+		out, err := cmd.Output()
+		if err != nil {
+			return "", err
+		}
+		// Functions are printed in full, so only the description is kept.
+		desc, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		return desc, nil
+	}
+	// loadCache is a stub for loading the sourcerer cache.
+	loadCache = func() (*cache.Cache, error) {
+		root, ok := os.LookupEnv(sourcerer.RootDirectoryEnvVar)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %q is not set", sourcerer.RootDirectoryEnvVar)
+		}
+		return cache.FromDir(filepath.Join(root, "cache"))
+	}
+)
+
+// GitAliasers returns the built-in aliases merged with the aliases in the
+// user's config.
+func GitAliasers() sourcerer.Option {
+	return sourcerer.Aliasers(loadConfig().aliases())
+}
+
+// cliCacheKey is the cache key that sourcerer saves the CLI's config to (which
+// sourcerer doesn't export).
+func (g *git) cliCacheKey() string {
+	return fmt.Sprintf("leep-frog-cache-key-%s.json", g.Name())
+}
+
+// loadConfig loads the CLI's config. Aliases are generated when sourcing,
+// before sourcerer loads any CLI config, so the config is read from the CLI's
+// cache entry here. If it can't be loaded, then the default config is
+// returned.
+func loadConfig() *git {
+	g := CLI()
+	c, err := loadCache()
+	if err != nil {
+		return g
+	}
+	if _, err := c.GetStruct(g.cliCacheKey(), g); err != nil {
+		return CLI()
+	}
+	return g
+}
+
+// aliases returns all enabled aliases (from alias to command args).
+func (g *git) aliases() map[string][]string {
+	m := map[string][]string{}
+	for a, args := range defaultAliases {
+		if !g.DisabledAliases[a] {
+			m[a] = append([]string{g.Name()}, args...)
+		}
+	}
+	for a, args := range g.Aliases {
+		m[a] = append([]string{g.Name()}, args...)
+	}
+	return m
+}
+
+// aliasConflict returns an error if the alias is already an alias or command.
+func (g *git) aliasConflict(alias string) error {
+	if args, ok := g.Aliases[alias]; ok {
+		return fmt.Errorf("alias %q already exists (%s %s)", alias, g.Name(), strings.Join(args, " "))
+	}
+	if args, ok := defaultAliases[alias]; ok && !g.DisabledAliases[alias] {
+		return fmt.Errorf("alias %q is a built-in alias (%s %s); disable it with `g alias disable %s` first", alias, g.Name(), strings.Join(args, " "), alias)
+	}
+	if desc, err := shellType(alias); err == nil {
+		return fmt.Errorf("alias %q conflicts with an existing command (%s)", alias, desc)
+	}
+	if p, err := lookPath(alias); err == nil {
+		return fmt.Errorf("alias %q conflicts with an existing command (%s)", alias, p)
+	}
+	return nil
+}
+
+func (g *git) showAliases(o command.Output) {
+	keys := append(maps.Keys(defaultAliases), maps.Keys(g.Aliases)...)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	for _, k := range keys {
+		if args, ok := g.Aliases[k]; ok {
+			o.Stdoutf("%s: %s %s\n", k, g.Name(), strings.Join(args, " "))
+		} else if g.DisabledAliases[k] {
+			o.Stdoutf("%s: %s %s (built-in, disabled)\n", k, g.Name(), strings.Join(defaultAliases[k], " "))
+		} else {
+			o.Stdoutf("%s: %s %s (built-in)\n", k, g.Name(), strings.Join(defaultAliases[k], " "))
+		}
+	}
+}
+
+func (g *git) aliasNode() command.Node {
+	var enabled, disabled []string
+	for a := range defaultAliases {
+		if g.DisabledAliases[a] {
+			disabled = append(disabled, a)
+		} else {
+			enabled = append(enabled, a)
+		}
+	}
+
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"add": commander.SerialNodes(
+				commander.Description("Add an alias for a `g` command"),
+				commander.FlagProcessor(forceAliasFlag),
+				aliasNameArg,
+				aliasArgsArg,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					alias := aliasNameArg.Get(d)
+					if err := g.aliasConflict(alias); err != nil && !forceAliasFlag.Get(d) {
+						return o.Annotatef(err, "failed to add alias (use --%s to add it anyway)", forceAliasFlag.Name())
+					}
+					if g.Aliases == nil {
+						g.Aliases = map[string][]string{}
+					}
+					g.Aliases[alias] = aliasArgsArg.Get(d)
+					g.changed = true
+					o.Stdoutf("Adding alias %s: %s %s (re-source to use it)\n", alias, g.Name(), strings.Join(aliasArgsArg.Get(d), " "))
+					return nil
+				}},
+			),
+			"rm": commander.SerialNodes(
+				commander.Description("Remove an alias"),
+				commander.Arg[string](aliasNameArg.Name(), aliasNameArg.Desc(), commander.SimpleCompleter[string](maps.Keys(g.Aliases)...)),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					alias := aliasNameArg.Get(d)
+					if _, ok := g.Aliases[alias]; !ok {
+						if _, ok := defaultAliases[alias]; ok {
+							return o.Stderrf("%s is a built-in alias; use `g alias disable %s` instead\n", alias, alias)
+						}
+						return o.Stderrf("%s is not an alias\n", alias)
+					}
+					delete(g.Aliases, alias)
+					g.changed = true
+					o.Stdoutln("Removing alias", alias)
+					return nil
+				}},
+			),
+			"disable": commander.SerialNodes(
+				commander.Description("Disable a built-in alias"),
+				commander.Arg[string](aliasNameArg.Name(), aliasNameArg.Desc(), commander.SimpleCompleter[string](enabled...)),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					alias := aliasNameArg.Get(d)
+					if _, ok := defaultAliases[alias]; !ok {
+						return o.Stderrf("%s is not a built-in alias\n", alias)
+					}
+					if g.DisabledAliases == nil {
+						g.DisabledAliases = map[string]bool{}
+					}
+					g.DisabledAliases[alias] = true
+					g.changed = true
+					o.Stdoutln("Disabling built-in alias", alias)
+					return nil
+				}},
+			),
+			"enable": commander.SerialNodes(
+				commander.Description("Re-enable a disabled built-in alias"),
+				commander.Arg[string](aliasNameArg.Name(), aliasNameArg.Desc(), commander.SimpleCompleter[string](disabled...)),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					alias := aliasNameArg.Get(d)
+					if !g.DisabledAliases[alias] {
+						return o.Stderrf("%s is not a disabled built-in alias\n", alias)
+					}
+					delete(g.DisabledAliases, alias)
+					g.changed = true
+					o.Stdoutln("Enabling built-in alias", alias)
+					return nil
+				}},
+			),
+		},
+		Default: commander.SerialNodes(
+			commander.Description("List aliases"),
+			&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+				g.showAliases(o)
+				return nil
+			}},
+		),
+	}
+}
@@ -0,0 +1,481 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/leep-frog/command/cache"
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commandertest"
+	"github.com/leep-frog/command/commandtest"
+)
+
+func TestAliasExecution(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		g    *git
+		want *git
+		// paths are the executables that exist in the PATH
+		paths map[string]string
+		// shell are the commands (from name to description) that the shell knows
+		shell map[string]string
+		etc   *commandtest.ExecuteTestCase
+	}{
+		{
+			name: "Lists aliases",
+			g: &git{
+				Aliases: map[string][]string{
+					"gsync": {"sync"},
+				},
+				DisabledAliases: map[string]bool{
+					"mm": true,
+				},
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias"},
+				WantStdout: joinLines(
+					"ch: g ch (built-in)",
+					"cm: g m (built-in)",
+					"ga: g a (built-in)",
+					"gam: g am (built-in)",
+					"gb: g b (built-in)",
+					"gbd: g bd (built-in)",
+					"gbn: g ch -n (built-in)",
+					"gc: g c (built-in)",
+					"gcb: g ch (built-in)",
+					"gcnv: g c -n (built-in)",
+					"gcp: g cp (built-in)",
+					"gd: g d (built-in)",
+					"gdm: g d -m (built-in)",
+					"glg: g lg (built-in)",
+					"gmm: g mm (built-in)",
+					"gnb: g ch -n (built-in)",
+					"gop: g op (built-in)",
+					"gp: g p (built-in)",
+					"gpl: g pl (built-in)",
+					"gs: g s (built-in)",
+					"gsync: g sync",
+					"gua: g ua (built-in)",
+					"guc: g uc (built-in)",
+					"guco: g uco (built-in)",
+					"gush: g ush (built-in)",
+					"mm: g mm (built-in, disabled)",
+					"sq: g q (built-in)",
+				),
+			},
+		},
+		{
+			name: "Adds alias",
+			g:    &git{},
+			want: &git{
+				Aliases: map[string][]string{
+					"gdw": {"d", "-w"},
+				},
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "gdw", "d", "-w"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gdw",
+					aliasArgsArg.Name(): []string{"d", "-w"},
+				}},
+				WantStdout: "Adding alias gdw: g d -w (re-source to use it)\n",
+			},
+		},
+		{
+			name: "Fails if invalid alias name",
+			g:    &git{},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "g dw", "d"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "g dw",
+				}},
+				WantStderr: "validation for \"ALIAS\" failed: [MatchesRegex] value \"g dw\" doesn't match regex \"^[a-zA-Z0-9_-]+$\"\n",
+				WantErr:    fmt.Errorf("validation for \"ALIAS\" failed: [MatchesRegex] value \"g dw\" doesn't match regex \"^[a-zA-Z0-9_-]+$\""),
+			},
+		},
+		{
+			name: "Fails if alias already exists",
+			g: &git{
+				Aliases: map[string][]string{
+					"gdw": {"d", "-w"},
+				},
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "gdw", "d"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gdw",
+					aliasArgsArg.Name(): []string{"d"},
+				}},
+				WantStderr: "failed to add alias (use --force to add it anyway): alias \"gdw\" already exists (g d -w)\n",
+				WantErr:    fmt.Errorf("failed to add alias (use --force to add it anyway): alias \"gdw\" already exists (g d -w)"),
+			},
+		},
+		{
+			name: "Fails if alias is a built-in alias",
+			g:    &git{},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "gd", "d", "-w"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gd",
+					aliasArgsArg.Name(): []string{"d", "-w"},
+				}},
+				WantStderr: "failed to add alias (use --force to add it anyway): alias \"gd\" is a built-in alias (g d); disable it with `g alias disable gd` first\n",
+				WantErr:    fmt.Errorf("failed to add alias (use --force to add it anyway): alias \"gd\" is a built-in alias (g d); disable it with `g alias disable gd` first"),
+			},
+		},
+		{
+			name: "Adds alias if built-in alias is disabled",
+			g: &git{
+				DisabledAliases: map[string]bool{"gd": true},
+			},
+			want: &git{
+				Aliases: map[string][]string{
+					"gd": {"d", "-w"},
+				},
+				DisabledAliases: map[string]bool{"gd": true},
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "gd", "d", "-w"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gd",
+					aliasArgsArg.Name(): []string{"d", "-w"},
+				}},
+				WantStdout: "Adding alias gd: g d -w (re-source to use it)\n",
+			},
+		},
+		{
+			name:  "Fails if alias is an existing command",
+			g:     &git{},
+			paths: map[string]string{"gdw": "/usr/bin/gdw"},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "gdw", "d", "-w"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gdw",
+					aliasArgsArg.Name(): []string{"d", "-w"},
+				}},
+				WantStderr: "failed to add alias (use --force to add it anyway): alias \"gdw\" conflicts with an existing command (/usr/bin/gdw)\n",
+				WantErr:    fmt.Errorf("failed to add alias (use --force to add it anyway): alias \"gdw\" conflicts with an existing command (/usr/bin/gdw)"),
+			},
+		},
+		{
+			name:  "Fails if alias is a shell builtin",
+			g:     &git{},
+			shell: map[string]string{"cd": "cd is a shell builtin"},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "cd", "ch"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "cd",
+					aliasArgsArg.Name(): []string{"ch"},
+				}},
+				WantStderr: "failed to add alias (use --force to add it anyway): alias \"cd\" conflicts with an existing command (cd is a shell builtin)\n",
+				WantErr:    fmt.Errorf("failed to add alias (use --force to add it anyway): alias \"cd\" conflicts with an existing command (cd is a shell builtin)"),
+			},
+		},
+		{
+			name:  "Fails if alias is a shell alias",
+			g:     &git{},
+			shell: map[string]string{"ll": "ll is aliased to `ls -alF'"},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "ll", "lg"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "ll",
+					aliasArgsArg.Name(): []string{"lg"},
+				}},
+				WantStderr: "failed to add alias (use --force to add it anyway): alias \"ll\" conflicts with an existing command (ll is aliased to `ls -alF')\n",
+				WantErr:    fmt.Errorf("failed to add alias (use --force to add it anyway): alias \"ll\" conflicts with an existing command (ll is aliased to `ls -alF')"),
+			},
+		},
+		{
+			name:  "Adds conflicting alias with force flag",
+			g:     &git{},
+			paths: map[string]string{"gdw": "/usr/bin/gdw"},
+			want: &git{
+				Aliases: map[string][]string{
+					"gdw": {"d", "-w"},
+				},
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "add", "gdw", "d", "-w", "-f"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name():   "gdw",
+					aliasArgsArg.Name():   []string{"d", "-w"},
+					forceAliasFlag.Name(): true,
+				}},
+				WantStdout: "Adding alias gdw: g d -w (re-source to use it)\n",
+			},
+		},
+		{
+			name: "Removes alias",
+			g: &git{
+				Aliases: map[string][]string{
+					"gdw":   {"d", "-w"},
+					"gsync": {"sync"},
+				},
+			},
+			want: &git{
+				Aliases: map[string][]string{
+					"gsync": {"sync"},
+				},
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "rm", "gdw"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gdw",
+				}},
+				WantStdout: "Removing alias gdw\n",
+			},
+		},
+		{
+			name: "Remove fails for built-in alias",
+			g:    &git{},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "rm", "gd"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gd",
+				}},
+				WantStderr: "gd is a built-in alias; use `g alias disable gd` instead\n",
+				WantErr:    fmt.Errorf("gd is a built-in alias; use `g alias disable gd` instead"),
+			},
+		},
+		{
+			name: "Remove fails for unknown alias",
+			g:    &git{},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "rm", "gdw"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gdw",
+				}},
+				WantStderr: "gdw is not an alias\n",
+				WantErr:    fmt.Errorf("gdw is not an alias"),
+			},
+		},
+		{
+			name: "Disables built-in alias",
+			g:    &git{},
+			want: &git{
+				DisabledAliases: map[string]bool{"ch": true},
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "disable", "ch"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "ch",
+				}},
+				WantStdout: "Disabling built-in alias ch\n",
+			},
+		},
+		{
+			name: "Disable fails for unknown built-in alias",
+			g:    &git{},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "disable", "gdw"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "gdw",
+				}},
+				WantStderr: "gdw is not a built-in alias\n",
+				WantErr:    fmt.Errorf("gdw is not a built-in alias"),
+			},
+		},
+		{
+			name: "Enables built-in alias",
+			g: &git{
+				DisabledAliases: map[string]bool{"ch": true, "mm": true},
+			},
+			want: &git{
+				DisabledAliases: map[string]bool{"mm": true},
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "enable", "ch"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "ch",
+				}},
+				WantStdout: "Enabling built-in alias ch\n",
+			},
+		},
+		{
+			name: "Enable fails if alias isn't disabled",
+			g:    &git{},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"alias", "enable", "ch"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					aliasNameArg.Name(): "ch",
+				}},
+				WantStderr: "ch is not a disabled built-in alias\n",
+				WantErr:    fmt.Errorf("ch is not a disabled built-in alias"),
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			commandtest.StubValue(t, &lookPath, func(file string) (string, error) {
+				if p, ok := test.paths[file]; ok {
+					return p, nil
+				}
+				return "", fmt.Errorf("not found")
+			})
+			commandtest.StubValue(t, &shellType, func(name string) (string, error) {
+				if desc, ok := test.shell[name]; ok {
+					return desc, nil
+				}
+				return "", fmt.Errorf("exit status 1")
+			})
+			test.etc.Node = test.g.Node()
+			commandertest.ExecuteTest(t, test.etc)
+			commandertest.ChangeTest(t, test.want, test.g, cmpopts.IgnoreUnexported(git{}), cmpopts.EquateEmpty())
+			if test.want != nil && !test.g.changed {
+				t.Errorf("alias command didn't mark the config as changed")
+			}
+		})
+	}
+}
+
+func TestAliasAutocomplete(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		g    *git
+		ctc  *commandtest.CompleteTestCase
+	}{
+		{
+			name: "Completes alias subcommands",
+			g:    &git{},
+			ctc: &commandtest.CompleteTestCase{
+				Args: "cmd alias ",
+				Want: &command.Autocompletion{
+					Suggestions: []string{"add", "disable", "enable", "rm"},
+				},
+			},
+		},
+		{
+			name: "Completes user-defined aliases for rm",
+			g: &git{
+				Aliases: map[string][]string{
+					"gdw":   {"d", "-w"},
+					"gsync": {"sync"},
+				},
+			},
+			ctc: &commandtest.CompleteTestCase{
+				Args: "cmd alias rm ",
+				Want: &command.Autocompletion{
+					Suggestions: []string{"gdw", "gsync"},
+				},
+			},
+		},
+		{
+			name: "Completes enabled built-in aliases for disable",
+			g: &git{
+				DisabledAliases: map[string]bool{"gua": true},
+			},
+			ctc: &commandtest.CompleteTestCase{
+				Args: "cmd alias disable gu",
+				Want: &command.Autocompletion{
+					Suggestions: []string{"guc", "guco", "gush"},
+				},
+			},
+		},
+		{
+			name: "Completes disabled built-in aliases for enable",
+			g: &git{
+				DisabledAliases: map[string]bool{"gua": true, "mm": true},
+			},
+			ctc: &commandtest.CompleteTestCase{
+				Args: "cmd alias enable ",
+				Want: &command.Autocompletion{
+					Suggestions: []string{"gua", "mm"},
+				},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.ctc.Node = test.g.Node()
+			test.ctc.SkipDataCheck = true
+			commandertest.AutocompleteTest(t, test.ctc)
+		})
+	}
+}
+
+func TestAliases(t *testing.T) {
+	g := &git{
+		Aliases: map[string][]string{
+			"gd":    {"d", "-w"},
+			"gsync": {"sync"},
+		},
+		DisabledAliases: map[string]bool{
+			"ch": true,
+			"gd": true,
+			"mm": true,
+		},
+	}
+	got := g.aliases()
+
+	want := map[string][]string{}
+	for a, args := range defaultAliases {
+		want[a] = append([]string{"g"}, args...)
+	}
+	delete(want, "ch")
+	delete(want, "mm")
+	want["gd"] = []string{"g", "d", "-w"}
+	want["gsync"] = []string{"g", "sync"}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("aliases() returned incorrect aliases (-want, +got):\n%s", diff)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		config   *git
+		cacheErr error
+		want     *git
+	}{
+		{
+			name: "Loads config from cache",
+			config: &git{
+				Aliases:         map[string][]string{"gsync": {"sync"}},
+				DisabledAliases: map[string]bool{"ch": true},
+				DefaultBranch:   "trunk",
+			},
+			want: &git{
+				Aliases:         map[string][]string{"gsync": {"sync"}},
+				DisabledAliases: map[string]bool{"ch": true},
+				DefaultBranch:   "trunk",
+			},
+		},
+		{
+			name: "Returns default config if not in cache",
+			want: &git{},
+		},
+		{
+			name:     "Returns default config if cache fails",
+			cacheErr: fmt.Errorf("oops"),
+			want:     &git{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := cache.FromDir(t.TempDir())
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			if test.config != nil {
+				if err := c.PutStruct(test.config.cliCacheKey(), test.config); err != nil {
+					t.Fatalf("failed to put config in cache: %v", err)
+				}
+			}
+			commandtest.StubValue(t, &loadCache, func() (*cache.Cache, error) {
+				return c, test.cacheErr
+			})
+
+			if diff := cmp.Diff(test.want, loadConfig(), cmpopts.IgnoreUnexported(git{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("loadConfig() returned incorrect config (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func joinLines(lines ...string) string {
+	var r string
+	for _, l := range lines {
+		r += l + "\n"
+	}
+	return r
+}
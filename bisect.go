@@ -0,0 +1,135 @@
+package sourcecontrol
+
+import (
+	"strings"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+)
+
+var (
+	bisectBadArg  = commander.Arg[string]("BAD", "Commit that has the bug", refCompleter[string](true))
+	bisectGoodArg = commander.OptionalArg[string]("GOOD", "Commit that doesn't have the bug (defaults to the merge-base of BAD and the default branch)", refCompleter[string](true))
+	bisectRevsArg = commander.ListArg[string]("COMMITS", "Commits to mark (defaults to the current commit)", 0, command.UnboundedList, refCompleter[[]string](true))
+	bisectCmdArg  = commander.ListArg[string]("CMD", "Command to run on each commit (exit code 0 is good, 125 is skip, and anything else below 128 is bad)", 1, command.UnboundedList)
+)
+
+// showBisect is a processor that prints the state of the in-progress bisect
+// and the steps that remain.
+var showBisect = &commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+	bs, err := gitRepo(d).Bisect()
+	if err != nil {
+		return o.Annotatef(err, "failed to get bisect state")
+	}
+	if bs == nil {
+		o.Stdoutln("Not bisecting")
+		return nil
+	}
+
+	switch {
+	case bs.Bad == "":
+		o.Stdoutln("Waiting for a bad commit (run `g bs bad`)")
+	case len(bs.Good) == 0:
+		o.Stdoutln("Waiting for a good commit (run `g bs good`)")
+	case bs.Found():
+		o.Stdoutf("Found the first bad commit: %s\n", shortSHA(bs.Next))
+	default:
+		steps := "steps"
+		if bs.Steps == 1 {
+			steps = "step"
+		}
+		o.Stdoutf("Testing %s: %d %s left to test after this (roughly %d %s)\n", shortSHA(bs.Next), bs.Remaining, pluralCommits(bs.Remaining), bs.Steps, steps)
+	}
+
+	if bs.Bad != "" {
+		o.Stdoutf("  Bad: %s\n", shortSHA(bs.Bad))
+	}
+	for _, l := range []struct {
+		name string
+		shas []string
+	}{
+		{"Good", bs.Good},
+		{"Skipped", bs.Skipped},
+	} {
+		if len(l.shas) == 0 {
+			continue
+		}
+		var short []string
+		for _, sha := range l.shas {
+			short = append(short, shortSHA(sha))
+		}
+		o.Stdoutf("  %s: %s\n", l.name, strings.Join(short, ", "))
+	}
+	return nil
+}}
+
+// bisectMarkNode returns a node that marks commits with the provided term.
+func bisectMarkNode(term, desc string) command.Node {
+	return commander.SerialNodes(
+		commander.Description(desc),
+		bisectRevsArg,
+		commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+			return []string{commandString(gitrepo.BisectMark(term, bisectRevsArg.Get(d)...))}, nil
+		}),
+	)
+}
+
+func (g *git) bisectNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"start": commander.SerialNodes(
+				commander.Description("Start bisecting to find the commit between GOOD and BAD that introduced a bug"),
+				bisectBadArg,
+				bisectGoodArg,
+				// Only fetch the repo name if there is a default branch to look up.
+				commander.If(
+					optionalRepoName,
+					func(i *command.Input, d *command.Data) bool {
+						return !bisectGoodArg.Provided(d) && len(g.MainBranches) > 0
+					},
+				),
+				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+					bad := bisectBadArg.Get(d)
+					if bisectGoodArg.Provided(d) {
+						return []string{commandString(gitrepo.BisectStart(bad, bisectGoodArg.Get(d)))}, nil
+					}
+
+					repo := gitRepo(d)
+					def := g.GetDefaultBranch(d)
+					good, err := repo.MergeBase(bad, def)
+					if err != nil {
+						return nil, o.Annotatef(err, "failed to get merge-base of %s and %s", bad, def)
+					}
+					badSHA, err := repo.RevParse(bad)
+					if err != nil {
+						return nil, o.Annotatef(err, "failed to get commit for %s", bad)
+					}
+					if good == badSHA {
+						return nil, o.Stderrf("%s is already in %s, so a GOOD commit must be provided\n", bad, def)
+					}
+					o.Stdoutf("Using the merge-base of %s and %s (%s) as the good commit\n", bad, def, shortSHA(good))
+					return []string{commandString(gitrepo.BisectStart(bad, good))}, nil
+				}),
+			),
+			"good": bisectMarkNode("good", "Mark the current commit (or the provided commits) as good"),
+			"bad":  bisectMarkNode("bad", "Mark the current commit (or the provided commits) as bad"),
+			"skip": bisectMarkNode("skip", "Skip the current commit (or the provided commits) because it can't be tested"),
+			"run": commander.SerialNodes(
+				commander.Description("Bisect automatically by running a command on each commit"),
+				bisectCmdArg,
+				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+					return []string{commandString(gitrepo.BisectRun(bisectCmdArg.Get(d)...))}, nil
+				}),
+			),
+			"reset": commander.SerialNodes(
+				commander.Description("Stop bisecting and check out the original branch"),
+				executableCommands(gitrepo.BisectReset()),
+			),
+		},
+		Default: commander.SerialNodes(
+			commander.Description("Show the bisect status and the remaining steps"),
+			showBisect,
+		),
+	}
+}
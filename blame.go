@@ -0,0 +1,161 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	blameFileArg  = commander.Arg[string]("FILE", "File to blame", trackedFileCompleter[string]())
+	blameLinesArg = commander.OptionalArg[string](
+		"LINES", "Lines to blame (START or START:END)",
+		commander.MatchesRegex(`^[1-9][0-9]*(:[1-9][0-9]*)?$`),
+	)
+	blameIgnoreRevsArg = commander.Arg[string]("IGNORE_REVS_FILE", "File (relative to the root of the repo) that lists commits for `g bl` to ignore (e.g. formatting changes)")
+)
+
+// trackedFileCompleter completes tracked files.
+func trackedFileCompleter[T any]() commander.Completer[T] {
+	return commander.CompleterFromFunc(func(t T, d *command.Data) (*command.Completion, error) {
+		repo := completionRepo(d)
+		files, err := repo.TrackedFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracked files: %v", err)
+		}
+		if files, err = repo.RelativePaths(files); err != nil {
+			return nil, fmt.Errorf("failed to get relative paths: %v", err)
+		}
+		return fileCompletion(t, d, &command.Completion{
+			Suggestions:     files,
+			Distinct:        true,
+			CaseInsensitive: true,
+		}), nil
+	})
+}
+
+// parseLineRange parses a `START[:END]` line range. Zeros are returned if
+// the range is empty.
+func parseLineRange(s string) (int, int, error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	startStr, endStr, hasEnd := strings.Cut(s, ":")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line range %q: %v", s, err)
+	}
+	if !hasEnd {
+		return start, 0, nil
+	}
+	end, err := strconv.Atoi(endStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line range %q: %v", s, err)
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid line range %q: END is before START", s)
+	}
+	return start, end, nil
+}
+
+func (g *git) blameNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("Blame a file, showing the commit, author, and relative date that last changed each line"),
+		commander.FlagProcessor(whitespaceFlag),
+		blameFileArg,
+		blameLinesArg,
+		// Only fetch the repo name if there is an ignore-revs file to look up.
+		commander.If(
+			optionalRepoName,
+			func(i *command.Input, d *command.Data) bool {
+				return len(g.BlameIgnoreRevs) > 0
+			},
+		),
+		commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+			start, end, err := parseLineRange(blameLinesArg.Get(d))
+			if err != nil {
+				return nil, o.Err(err)
+			}
+			opts := &gitrepo.BlameOptions{
+				Path:             blameFileArg.Get(d),
+				Start:            start,
+				End:              end,
+				IgnoreWhitespace: whitespaceFlag.Provided(d),
+			}
+
+			if f, ok := g.BlameIgnoreRevs[repoName.Get(d)]; ok {
+				// The file is relative to the root of the repo, but blame may be run
+				// from a subdirectory.
+				top, err := gitRepo(d).TopLevel()
+				if err != nil {
+					return nil, o.Annotatef(err, "failed to get repo root")
+				}
+				opts.IgnoreRevsFile = filepath.Join(top, filepath.FromSlash(f))
+			}
+			return []string{commandString(gitrepo.Blame(opts))}, nil
+		}),
+	)
+}
+
+func (g *git) showBlameIgnoreRevs(o command.Output) {
+	if len(g.BlameIgnoreRevs) == 0 {
+		o.Stdoutln("No blame ignore-revs files set")
+		return
+	}
+
+	keys := maps.Keys(g.BlameIgnoreRevs)
+	slices.Sort(keys)
+	for _, k := range keys {
+		o.Stdoutf("%s: blame ignoring commits in %s\n", k, g.BlameIgnoreRevs[k])
+	}
+}
+
+func (g *git) blameIgnoreRevsConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show blame ignore-revs files"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showBlameIgnoreRevs(o)
+					return nil
+				}},
+			),
+			"set": commander.SerialNodes(
+				commander.Description("Set the file of commits for `g bl` to ignore in this repo"),
+				repoName,
+				blameIgnoreRevsArg,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					if g.BlameIgnoreRevs == nil {
+						g.BlameIgnoreRevs = map[string]string{}
+					}
+					g.BlameIgnoreRevs[repoName.Get(d)] = blameIgnoreRevsArg.Get(d)
+					g.changed = true
+					o.Stdoutf("Setting blame ignore-revs file for %s to %s\n", repoName.Get(d), blameIgnoreRevsArg.Get(d))
+					return nil
+				}},
+			),
+			"unset": commander.SerialNodes(
+				commander.Description("Don't ignore any commits in `g bl` in this repo"),
+				repoName,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					rn := repoName.Get(d)
+					if _, ok := g.BlameIgnoreRevs[rn]; !ok {
+						o.Stdoutln("No blame ignore-revs file set for this repo")
+						return nil
+					}
+					delete(g.BlameIgnoreRevs, rn)
+					g.changed = true
+					o.Stdoutln("Deleting blame ignore-revs file for", rn)
+					return nil
+				}},
+			),
+		},
+	}
+}
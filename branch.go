@@ -0,0 +1,272 @@
+package sourcecontrol
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	branchDateFormat = "2006-01-02 15:04"
+
+	// defaultRecentBranchCount is the number of branches listed by `g recent`
+	// and suggested by checkout completion.
+	defaultRecentBranchCount = 10
+)
+
+var (
+	branchJSONFlag = commander.BoolFlag("json", 'j', "Whether or not to print the branches as JSON")
+	branchDateFlag = commander.BoolFlag("date", 'd', "Whether or not to sort the branches by last commit date (most recent first)")
+
+	renameOldArg   = commander.Arg[string]("OLD", "Branch to rename (or the new name of the current branch if NEW isn't provided)", BranchCompleter())
+	renameNewArg   = commander.OptionalArg[string]("NEW", "New branch name")
+	renamePushFlag = commander.BoolFlag("push", 'p', "Whether or not to push the new branch, delete the old remote branch, and set the new upstream")
+
+	recentCountArg    = commander.OptionalArg[int]("N", "Number of recent branches to list", commander.Positive[int](), commander.Default(defaultRecentBranchCount))
+	checkoutBranchArg = commander.Arg[string](
+		"BRANCH",
+		"Branch (or - for the previously checked out branch)",
+		recentBranchCompleter(),
+	)
+)
+
+func (g *git) branchNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("List local branches with their last commit, upstream state, and state relative to the default branch"),
+		commander.FlagProcessor(
+			branchJSONFlag,
+			branchDateFlag,
+		),
+		// Only fetch the repo name if there is a default branch to look up.
+		commander.If(
+			optionalRepoName,
+			func(i *command.Input, d *command.Data) bool {
+				return len(g.MainBranches) > 0
+			},
+		),
+		&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+			def := g.GetDefaultBranch(d)
+			infos, err := gitRepo(d).BranchInfos(def)
+			if err != nil {
+				return o.Annotatef(err, "failed to get branches")
+			}
+
+			if branchDateFlag.Get(d) {
+				slices.SortStableFunc(infos, func(a, b *gitrepo.BranchInfo) int {
+					return b.LastCommit.Compare(a.LastCommit)
+				})
+			}
+
+			if branchJSONFlag.Get(d) {
+				if infos == nil {
+					infos = []*gitrepo.BranchInfo{}
+				}
+				b, err := json.MarshalIndent(infos, "", "  ")
+				if err != nil {
+					return o.Annotatef(err, "failed to marshal branches")
+				}
+				o.Stdoutln(string(b))
+				return nil
+			}
+
+			printBranchTable(o, def, infos)
+			return nil
+		}},
+	)
+}
+
+func printBranchTable(o command.Output, def string, infos []*gitrepo.BranchInfo) {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  BRANCH\tLAST COMMIT\tAUTHOR\tUPSTREAM\tVS %s\tMERGED\n", def)
+	for _, bi := range infos {
+		cur := " "
+		if bi.Current {
+			cur = "*"
+		}
+		merged := "no"
+		if bi.Merged {
+			merged = "yes"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t+%d -%d\t%s\n", cur, bi.Name, bi.LastCommit.Format(branchDateFormat), bi.Author, upstreamSummary(bi), bi.BaseAhead, bi.BaseBehind, merged)
+	}
+	w.Flush()
+	o.Stdout(sb.String())
+}
+
+// upstreamSummary returns a short description of a branch's upstream state.
+func upstreamSummary(bi *gitrepo.BranchInfo) string {
+	switch {
+	case bi.Upstream == "":
+		return "-"
+	case bi.UpstreamGone:
+		return fmt.Sprintf("%s gone", bi.Upstream)
+	case bi.Ahead == 0 && bi.Behind == 0:
+		return bi.Upstream
+	}
+	return fmt.Sprintf("%s +%d -%d", bi.Upstream, bi.Ahead, bi.Behind)
+}
+
+func (g *git) renameBranchNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("Rename a branch (and its remote branch and any config that references it)"),
+		commander.FlagProcessor(renamePushFlag),
+		renameOldArg,
+		renameNewArg,
+		// Only fetch the repo name if there is config that may reference the branch.
+		commander.If(
+			optionalRepoName,
+			func(i *command.Input, d *command.Data) bool {
+				return len(g.MainBranches) > 0 || len(g.ProtectedBranches) > 0
+			},
+		),
+		&commander.ExecutorProcessor{F: g.renameBranch},
+	)
+}
+
+func (g *git) renameBranch(o command.Output, d *command.Data) error {
+	repo := gitRepo(d)
+	oldName, newName := renameOldArg.Get(d), renameNewArg.Get(d)
+	if !renameNewArg.Provided(d) {
+		cur, err := repo.CurrentBranch()
+		if err != nil {
+			return o.Annotatef(err, "failed to get current branch")
+		}
+		oldName, newName = cur, oldName
+	}
+	if oldName == newName {
+		return o.Stderrf("%s is already named %s\n", oldName, newName)
+	}
+
+	// The upstream must be fetched before renaming (which moves the branch config).
+	upstream, _ := repo.Upstream(oldName)
+
+	o.Stdoutf("Renaming %s to %s\n", oldName, newName)
+	if _, err := repo.Run(gitrepo.RenameBranch(oldName, newName)); err != nil {
+		return o.Annotatef(err, "failed to rename %s", oldName)
+	}
+	g.renameBranchConfig(o, d, oldName, newName)
+
+	if !renamePushFlag.Get(d) {
+		if upstream != "" {
+			o.Stdoutf("Keeping upstream %s (use --%s to rename the remote branch too)\n", upstream, renamePushFlag.Name())
+		}
+		return nil
+	}
+
+	remote, remoteBranch := syncRemote, ""
+	if upstream != "" {
+		remote, remoteBranch, _ = strings.Cut(upstream, "/")
+	}
+
+	o.Stdoutf("Pushing %s to %s\n", newName, remote)
+	if _, err := repo.Run(gitrepo.Push(&gitrepo.PushOptions{SetUpstream: true, Remote: remote, Branch: newName})); err != nil {
+		return o.Annotatef(err, "failed to push %s", newName)
+	}
+
+	if remoteBranch != "" && remoteBranch != newName {
+		o.Stdoutf("Deleting %s\n", upstream)
+		if _, err := repo.Run(gitrepo.DeleteRemoteBranch(remote, remoteBranch)); err != nil {
+			return o.Annotatef(err, "failed to delete %s", upstream)
+		}
+	}
+	return nil
+}
+
+// renameBranchConfig updates the current repo's config that references the
+// old branch name.
+func (g *git) renameBranchConfig(o command.Output, d *command.Data, oldName, newName string) {
+	rn := repoName.Get(d)
+	if g.MainBranches[rn] == oldName {
+		g.MainBranches[rn] = newName
+		g.changed = true
+		o.Stdoutf("Updating default branch for %s to %s\n", rn, newName)
+	}
+
+	pbs := g.ProtectedBranches[rn]
+	if i := slices.Index(pbs, oldName); i >= 0 {
+		pbs[i] = newName
+		g.changed = true
+		o.Stdoutf("Updating protected branch %s to %s in %s\n", oldName, newName, rn)
+	}
+}
+
+// recentBranches returns up to `n` existing branches (other than the current
+// one) that were recently checked out, most recent first.
+func recentBranches(repo *gitrepo.Repo, branches []*gitrepo.Branch, n int) ([]string, error) {
+	recent, err := repo.RecentBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	var r []string
+	for _, rb := range recent {
+		if len(r) == n {
+			break
+		}
+		if slices.ContainsFunc(branches, func(b *gitrepo.Branch) bool { return b.Name == rb && !b.Current }) {
+			r = append(r, rb)
+		}
+	}
+	return r, nil
+}
+
+// recentBranchCompleter completes branches other than the current one.
+// Completions are always sorted, so recently checked out branches are ranked
+// first by only suggesting them until a prefix is typed.
+func recentBranchCompleter() commander.Completer[string] {
+	return commander.CompleterFromFunc(func(t string, d *command.Data) (*command.Completion, error) {
+		repo := gitRepo(d)
+		branches, err := repo.Branches()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches: %v", err)
+		}
+
+		if t == "" && len(branches) > 0 {
+			// Fall back to all branches if the reflog can't be read.
+			if recent, err := recentBranches(repo, branches, defaultRecentBranchCount); err == nil && len(recent) > 0 {
+				return matchCompletion(t, d, &command.Completion{
+					Suggestions: recent,
+				}), nil
+			}
+		}
+
+		var r []string
+		for _, b := range branches {
+			if !b.Current {
+				r = append(r, b.Name)
+			}
+		}
+		return matchCompletion(t, d, &command.Completion{
+			Suggestions: r,
+		}), nil
+	})
+}
+
+func recentNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("List recently checked out branches (most recent first)"),
+		recentCountArg,
+		&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+			repo := gitRepo(d)
+			branches, err := repo.Branches()
+			if err != nil {
+				return o.Annotatef(err, "failed to list branches")
+			}
+			recent, err := recentBranches(repo, branches, recentCountArg.Get(d))
+			if err != nil {
+				return o.Annotatef(err, "failed to get recent branches")
+			}
+			for _, b := range recent {
+				o.Stdoutln(b)
+			}
+			return nil
+		}},
+	)
+}
@@ -0,0 +1,197 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandertest"
+	"github.com/leep-frog/command/commandtest"
+	"github.com/leep-frog/sourcecontrol/gitrepo/gitrepotest"
+)
+
+func TestRenameBranchWithRealRepo(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		setup func(*gitrepotest.TestRepo)
+		// config sets the per-repo config (keyed by the repo name) before running.
+		config     func(g *git, rn string)
+		args       []string
+		wantStdout []string
+		wantErr    error
+		// wantConfig verifies the per-repo config after running.
+		wantConfig func(t *testing.T, g *git, rn string)
+		check      func(*testing.T, *gitrepotest.TestRepo)
+	}{
+		{
+			name: "renames the current branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("checkout", "-b", "feature")
+			},
+			args:       []string{"bmv", "feat"},
+			wantStdout: []string{"Renaming feature to feat"},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff("feat", tr.CurrentBranch()); diff != "" {
+					t.Errorf("Rename produced incorrect current branch (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "renames another branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("branch", "feature")
+			},
+			args:       []string{"bmv", "feature", "feat"},
+			wantStdout: []string{"Renaming feature to feat"},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"feat", "main"}, tr.Git("branch", "--format=%(refname:short)")); diff != "" {
+					t.Errorf("Rename produced incorrect branches (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "fails if the new branch exists",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("branch", "feature")
+			},
+			args:       []string{"bmv", "feature", "main"},
+			wantStdout: []string{"Renaming feature to main"},
+			wantErr:    fmt.Errorf(`failed to rename feature: failed to run "git branch -m feature main": exit status 128: fatal: a branch named 'main' already exists`),
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"feature", "main"}, tr.Git("branch", "--format=%(refname:short)")); diff != "" {
+					t.Errorf("Rename produced incorrect branches (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "keeps the upstream when not pushing",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+				tr.Git("checkout", "-b", "feature")
+				tr.Git("push", "-u", "origin", "feature")
+			},
+			args: []string{"bmv", "feat"},
+			wantStdout: []string{
+				"Renaming feature to feat",
+				"Keeping upstream origin/feature (use --push to rename the remote branch too)",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"origin/feature"}, tr.Git("rev-parse", "--abbrev-ref", "feat@{upstream}")); diff != "" {
+					t.Errorf("Rename changed the upstream (-want, +got):\n%s", diff)
+				}
+				if err := tr.GitErr("rev-parse", "--verify", "--quiet", "origin/feature"); err != nil {
+					t.Errorf("Rename deleted the remote branch without --push")
+				}
+			},
+		},
+		{
+			name: "pushes the new branch and deletes the old remote branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+				tr.Git("checkout", "-b", "feature")
+				tr.Git("push", "-u", "origin", "feature")
+				tr.Git("checkout", "main")
+			},
+			args: []string{"bmv", "feature", "feat", "--push"},
+			wantStdout: []string{
+				"Renaming feature to feat",
+				"Pushing feat to origin",
+				"Deleting origin/feature",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"origin/feat"}, tr.Git("rev-parse", "--abbrev-ref", "feat@{upstream}")); diff != "" {
+					t.Errorf("Rename set incorrect upstream (-want, +got):\n%s", diff)
+				}
+				if diff := cmp.Diff([]string{"origin/feat"}, tr.Git("branch", "-r", "--format=%(refname:short)")); diff != "" {
+					t.Errorf("Rename produced incorrect remote branches (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "pushes a branch without an upstream",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+				tr.Git("checkout", "-b", "feature")
+			},
+			args: []string{"bmv", "-p", "feat"},
+			wantStdout: []string{
+				"Renaming feature to feat",
+				"Pushing feat to origin",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"origin/feat"}, tr.Git("rev-parse", "--abbrev-ref", "@{upstream}")); diff != "" {
+					t.Errorf("Rename set incorrect upstream (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "updates config that references the old branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+			},
+			config: func(g *git, rn string) {
+				g.MainBranches = map[string]string{rn: "main", "other": "main"}
+				g.ProtectedBranches = map[string][]string{
+					rn:      {"staging", "main"},
+					"other": {"main"},
+				}
+			},
+			args: []string{"bmv", "trunk"},
+			wantStdout: []string{
+				"Renaming main to trunk",
+				"Updating default branch for REPO to trunk",
+				"Updating protected branch main to trunk in REPO",
+			},
+			wantConfig: func(t *testing.T, g *git, rn string) {
+				if diff := cmp.Diff(map[string]string{rn: "trunk", "other": "main"}, g.MainBranches); diff != "" {
+					t.Errorf("Rename produced incorrect main branches (-want, +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(map[string][]string{rn: {"staging", "trunk"}, "other": {"main"}}, g.ProtectedBranches); diff != "" {
+					t.Errorf("Rename produced incorrect protected branches (-want, +got):\n%s", diff)
+				}
+				if !g.changed {
+					t.Errorf("Rename didn't mark the config as changed")
+				}
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff("trunk", tr.CurrentBranch()); diff != "" {
+					t.Errorf("Rename produced incorrect current branch (-want, +got):\n%s", diff)
+				}
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tr := gitrepotest.NewWithCommit(t)
+			test.setup(tr)
+			stubRepo(t, tr)
+
+			g := &git{}
+			var rn string
+			if test.config != nil {
+				// The repo name is the path of the remote.
+				rn = tr.Git("config", "--get", "remote.origin.url")[0]
+				test.config(g, rn)
+			}
+
+			etc := &commandtest.ExecuteTestCase{
+				Node:          g.Node(),
+				Args:          test.args,
+				SkipDataCheck: true,
+				WantErr:       test.wantErr,
+			}
+			if len(test.wantStdout) > 0 {
+				// REPO is replaced with the repo name.
+				etc.WantStdout = strings.ReplaceAll(strings.Join(test.wantStdout, "\n")+"\n", "REPO", rn)
+			}
+			if test.wantErr != nil {
+				etc.WantStderr = test.wantErr.Error() + "\n"
+			}
+			commandertest.ExecuteTest(t, etc)
+			if test.wantConfig != nil {
+				test.wantConfig(t, g, rn)
+			}
+			test.check(t, tr)
+		})
+	}
+}
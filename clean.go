@@ -0,0 +1,222 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	cleanIgnoredFlag = commander.BoolFlag("ignored", 'i', "Whether or not to include ignored files")
+	cleanForceFlag   = commander.BoolFlag("force", 'f', "Whether or not to remove the files (instead of only listing them)")
+	cleanFilesArg    = commander.ListArg[string](
+		"FILES", "Untracked files to remove (defaults to all untracked files)",
+		0, command.UnboundedList,
+		cleanFileCompleter[[]string](),
+	)
+)
+
+// cleanableFiles returns the untracked (and ignored, if `ignored` is true)
+// files and directories relative to the root of the repo.
+func cleanableFiles(repo *gitrepo.Repo, ignored bool) ([]string, error) {
+	entries, err := repo.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %v", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type == gitrepo.Untracked {
+			files = append(files, e.Path)
+		}
+	}
+
+	if ignored {
+		ignoredFiles, err := repo.IgnoredFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ignored files: %v", err)
+		}
+		files = append(files, ignoredFiles...)
+		slices.Sort(files)
+		files = withoutNestedPaths(files)
+	}
+	return files, nil
+}
+
+// withoutNestedPaths removes the paths that are in one of the other
+// directories (which end with a slash) from the sorted paths.
+func withoutNestedPaths(paths []string) []string {
+	var r []string
+	for _, p := range paths {
+		if len(r) > 0 {
+			if last := r[len(r)-1]; strings.HasSuffix(last, "/") && strings.HasPrefix(p, last) {
+				continue
+			}
+		}
+		r = append(r, p)
+	}
+	return r
+}
+
+// cleanFileCompleter completes untracked (and ignored, if the --ignored flag
+// is set) files and directories.
+func cleanFileCompleter[T any]() commander.Completer[T] {
+	return commander.CompleterFromFunc(func(t T, d *command.Data) (*command.Completion, error) {
+		repo := completionRepo(d)
+		files, err := cleanableFiles(repo, cleanIgnoredFlag.Get(d))
+		if err != nil {
+			return nil, err
+		}
+		if files, err = repo.RelativePaths(files); err != nil {
+			return nil, fmt.Errorf("failed to get relative paths: %v", err)
+		}
+		return fileCompletion(t, d, &command.Completion{
+			Suggestions: files,
+			Distinct:    true,
+		}), nil
+	})
+}
+
+// repoRelativePath converts a path relative to the directory with the
+// provided prefix into a path relative to the root of the repo.
+func repoRelativePath(prefix, p string) (string, error) {
+	rp := path.Clean(path.Join(prefix, filepath.ToSlash(p)))
+	if rp == ".." || strings.HasPrefix(rp, "../") || path.IsAbs(rp) {
+		return "", fmt.Errorf("%s is outside of the repo", p)
+	}
+	if strings.HasSuffix(p, "/") && rp != "." {
+		rp += "/"
+	}
+	return rp, nil
+}
+
+// selectCleanFiles returns the repo-relative paths of the provided files
+// (relative to the directory with the provided prefix), verifying that each
+// one is (or is in) one of the cleanable files. All cleanable files are
+// returned if no files are provided.
+func selectCleanFiles(prefix string, cleanable, files []string) ([]string, error) {
+	if len(files) == 0 {
+		return cleanable, nil
+	}
+
+	var selected []string
+	for _, f := range files {
+		rp, err := repoRelativePath(prefix, f)
+		if err != nil {
+			return nil, err
+		}
+		// Directories are listed with a trailing slash.
+		if dir := strings.TrimSuffix(rp, "/") + "/"; slices.Contains(cleanable, dir) {
+			rp = dir
+		}
+		ok := slices.ContainsFunc(cleanable, func(c string) bool {
+			return rp == c || (strings.HasSuffix(c, "/") && strings.HasPrefix(rp, c))
+		})
+		if !ok {
+			return nil, fmt.Errorf("%s is not an untracked file", f)
+		}
+		if !slices.Contains(selected, rp) {
+			selected = append(selected, rp)
+		}
+	}
+	slices.Sort(selected)
+	return withoutNestedPaths(selected), nil
+}
+
+// trashFiles moves the provided files (relative to the root of the repo) to a
+// new directory in the repo's git directory and returns that directory. If a
+// file can't be moved, then the directory is still returned (along with the
+// number of files that were moved) so the moved files can be restored.
+func trashFiles(repo *gitrepo.Repo, top string, files []string) (string, int, error) {
+	// Check every file first so a missing file doesn't leave the clean half done.
+	for _, f := range files {
+		if _, err := os.Lstat(filepath.Join(top, filepath.FromSlash(f))); err != nil {
+			return "", 0, fmt.Errorf("failed to find %s: %v", f, err)
+		}
+	}
+
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return "", 0, err
+	}
+
+	trash := filepath.Join(gitDir, "g", "trash", now().Format("20060102-150405.000000"))
+	for i, f := range files {
+		f = strings.TrimSuffix(f, "/")
+		dst := filepath.Join(trash, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return trash, i, fmt.Errorf("failed to create directory: %v", err)
+		}
+		if err := os.Rename(filepath.Join(top, filepath.FromSlash(f)), dst); err != nil {
+			return trash, i, fmt.Errorf("failed to move %s to the trash: %v", f, err)
+		}
+	}
+	return trash, len(files), nil
+}
+
+func (g *git) cleanNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("Remove untracked files (only lists them unless --force is provided). Removed files are saved to a trash directory in the repo's git directory"),
+		commander.FlagProcessor(
+			cleanIgnoredFlag,
+			cleanForceFlag,
+		),
+		cleanFilesArg,
+		g.clearCompletionCache(),
+		&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+			repo := gitRepo(d)
+			prefix, err := repo.Prefix()
+			if err != nil {
+				return o.Annotatef(err, "failed to get current directory")
+			}
+			cleanable, err := cleanableFiles(repo, cleanIgnoredFlag.Get(d))
+			if err != nil {
+				return o.Err(err)
+			}
+			files, err := selectCleanFiles(prefix, cleanable, cleanFilesArg.Get(d))
+			if err != nil {
+				return o.Err(err)
+			}
+			if len(files) == 0 {
+				o.Stdoutln("No untracked files to remove")
+				return nil
+			}
+
+			rel, err := repo.RelativePaths(files)
+			if err != nil {
+				return o.Annotatef(err, "failed to get relative paths")
+			}
+			if !cleanForceFlag.Get(d) {
+				o.Stdoutln("Would remove:")
+				for _, f := range rel {
+					o.Stdoutf("  %s\n", f)
+				}
+				o.Stdoutln("Run with --force to remove these files")
+				return nil
+			}
+
+			top, err := repo.TopLevel()
+			if err != nil {
+				return o.Annotatef(err, "failed to get repo root")
+			}
+			trash, moved, err := trashFiles(repo, top, files)
+			if err != nil {
+				if moved > 0 {
+					o.Stderrf("Moved %d of %d files to %s before failing\n", moved, len(files), trash)
+				}
+				return o.Annotatef(err, "failed to remove files")
+			}
+			for _, f := range rel {
+				o.Stdoutf("Removed %s\n", f)
+			}
+			o.Stdoutf("Saved removed files to %s (restore them by moving them back to the root of the repo)\n", trash)
+			return nil
+		}},
+	)
+}
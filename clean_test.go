@@ -0,0 +1,141 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandtest"
+	"github.com/leep-frog/sourcecontrol/gitrepo/gitrepotest"
+)
+
+func TestSelectCleanFiles(t *testing.T) {
+	cleanable := []string{"a.txt", "build/", "sub/b.txt", "sub/dir/"}
+	for _, test := range []struct {
+		name    string
+		prefix  string
+		files   []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "defaults to all files",
+			want: cleanable,
+		},
+		{
+			name:  "selects files",
+			files: []string{"sub/b.txt", "a.txt"},
+			want:  []string{"a.txt", "sub/b.txt"},
+		},
+		{
+			name:   "selects files relative to the current directory",
+			prefix: "sub/",
+			files:  []string{"b.txt", "../a.txt", "./dir"},
+			want:   []string{"a.txt", "sub/b.txt", "sub/dir/"},
+		},
+		{
+			name:  "selects files in untracked directories",
+			files: []string{"build/out/c.txt", "sub/dir/d.txt"},
+			want:  []string{"build/out/c.txt", "sub/dir/d.txt"},
+		},
+		{
+			name:  "removes files in other selected directories",
+			files: []string{"build/out/c.txt", "build", "a.txt", "a.txt"},
+			want:  []string{"a.txt", "build/"},
+		},
+		{
+			name:    "fails for tracked files",
+			prefix:  "sub/",
+			files:   []string{"b.txt", "c.txt"},
+			wantErr: fmt.Errorf("c.txt is not an untracked file"),
+		},
+		{
+			name:    "fails for the untracked directory's parent",
+			files:   []string{"sub"},
+			wantErr: fmt.Errorf("sub is not an untracked file"),
+		},
+		{
+			name:    "fails for files outside of the repo",
+			prefix:  "sub/",
+			files:   []string{"../../a.txt"},
+			wantErr: fmt.Errorf("../../a.txt is outside of the repo"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := selectCleanFiles(test.prefix, cleanable, test.files)
+			commandtest.CmpError(t, fmt.Sprintf("selectCleanFiles(%q, %v)", test.prefix, test.files), test.wantErr, err)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("selectCleanFiles(%q, %v) returned incorrect files (-want, +got):\n%s", test.prefix, test.files, diff)
+			}
+		})
+	}
+}
+
+func TestTrashFiles(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		files []string
+		// wantMoved is the number of files that should be moved to the trash.
+		wantMoved int
+		// wantErr is the prefix of the expected error.
+		wantErr string
+		// wantTrash is the files that should be in the trash.
+		wantTrash []string
+		// wantRemaining is the files that should still be in the repo.
+		wantRemaining []string
+	}{
+		{
+			name:      "moves files to the trash",
+			files:     []string{"a.txt", "sub/"},
+			wantMoved: 2,
+			wantTrash: []string{"a.txt", "sub/b.txt"},
+		},
+		{
+			name:          "moves nothing if a file doesn't exist",
+			files:         []string{"a.txt", "missing.txt"},
+			wantErr:       "failed to find missing.txt: ",
+			wantRemaining: []string{"a.txt", "sub/b.txt"},
+		},
+		{
+			name:      "returns the trash if a file can't be moved",
+			files:     []string{"a.txt", "sub/", "sub/b.txt"},
+			wantMoved: 2,
+			wantErr:   "failed to move sub/b.txt to the trash: ",
+			wantTrash: []string{"a.txt", "sub/b.txt"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tr := gitrepotest.NewWithCommit(t)
+			tr.WriteFile("a.txt", "a\n")
+			tr.WriteFile("sub/b.txt", "b\n")
+
+			trash, moved, err := trashFiles(tr.Repo(), tr.Dir, test.files)
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("trashFiles(%v) returned error: %v", test.files, err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), test.wantErr)) {
+				t.Errorf("trashFiles(%v) returned error %v; want error starting with %q", test.files, err, test.wantErr)
+			}
+			if moved != test.wantMoved {
+				t.Errorf("trashFiles(%v) moved %d files; want %d", test.files, moved, test.wantMoved)
+			}
+			if (trash != "") != (test.wantMoved > 0) {
+				t.Errorf("trashFiles(%v) returned trash %q when %d files were moved", test.files, trash, test.wantMoved)
+			}
+
+			for _, f := range test.wantTrash {
+				if _, err := os.Stat(filepath.Join(trash, filepath.FromSlash(f))); err != nil {
+					t.Errorf("%s is not in the trash: %v", f, err)
+				}
+			}
+			for _, f := range test.wantRemaining {
+				if _, err := os.Stat(tr.Path(f)); err != nil {
+					t.Errorf("%s was removed from the repo: %v", f, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultMaxSubjectLength = 72
+
+	coAuthorFlagName = "co"
+)
+
+var (
+	ignorePolicyFlag       = commander.BoolFlag("ignore-policy", 'i', "Whether or not to skip the repo's commit message policy checks")
+	commitTypesFlag        = commander.ListFlag[string]("types", 't', "Allowed commit types", 1, command.UnboundedList)
+	maxSubjectLengthFlag   = commander.Flag[int]("max-subject-length", 'l', "Maximum length of the commit message header", commander.Positive[int](), commander.Default(defaultMaxSubjectLength))
+	requireCommitScopeFlag = commander.BoolFlag("require-scope", 'r', "Whether or not commit messages must include a scope")
+	ticketPatternArg       = commander.Arg[string]("TICKET_REGEX", "Regex that extracts a ticket ID from a branch name (the first capture group is used if one exists)", commander.IsRegex())
+	rosterNameArg          = commander.Arg[string]("NAME", "Name of the teammate")
+	rosterEmailArg         = commander.Arg[string]("EMAIL", "Email of the teammate", commander.Contains("@"))
+
+	// Matches authors of the form `Name <email>`
+	authorRegex = regexp.MustCompile(`^[^<>]+ <[^<>]+>$`)
+
+	// optionalRepoName sets the repoName value if the repo has a remote origin.
+	// Unlike repoName, it doesn't fail if one isn't configured.
+	optionalRepoName = commander.SimpleProcessor(
+		func(i *command.Input, o command.Output, d *command.Data, ed *command.ExecuteData) error {
+			setOptionalRepoName(d)
+			return nil
+		},
+		func(i *command.Input, d *command.Data) (*command.Completion, error) {
+			setOptionalRepoName(d)
+			return nil, nil
+		},
+	)
+)
+
+func setOptionalRepoName(d *command.Data) {
+	if rn, err := repoName.Run(d); err == nil {
+		d.Set(repoName.Name(), rn)
+	}
+}
+
+// commitPolicy returns the commit policy for the current repo (or nil if none is set).
+func (g *git) commitPolicy(d *command.Data) *gitrepo.CommitPolicy {
+	if g.CommitPolicies == nil || !d.Has(repoName.Name()) {
+		return nil
+	}
+	return g.CommitPolicies[repoName.Get(d)]
+}
+
+// hasRepoCommitConfig returns whether or not any per-repo commit settings
+// exist. This is used to avoid fetching the repo name on every commit when
+// there is nothing to look up.
+func (g *git) hasRepoCommitConfig() bool {
+	return len(g.CommitPolicies) > 0 || len(g.TicketPatterns) > 0
+}
+
+// commitRepoNode fetches the repo name if it's needed for commit settings.
+func (g *git) commitRepoNode() command.Processor {
+	return commander.If(
+		optionalRepoName,
+		func(i *command.Input, d *command.Data) bool {
+			return g.hasRepoCommitConfig()
+		},
+	)
+}
+
+// ticketPattern returns the ticket regex for the current repo (or nil if none is set).
+func (g *git) ticketPattern(d *command.Data) *regexp.Regexp {
+	if g.TicketPatterns == nil || !d.Has(repoName.Name()) {
+		return nil
+	}
+	p, ok := g.TicketPatterns[repoName.Get(d)]
+	if !ok {
+		return nil
+	}
+	// Patterns are validated when they are set, so this should only fail if the
+	// config was modified by hand.
+	r, err := regexp.Compile(p)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// ticketBranchNode fetches the current branch if the repo has a ticket pattern.
+func (g *git) ticketBranchNode() command.Processor {
+	return commander.If(
+		currentBranchArg,
+		func(i *command.Input, d *command.Data) bool {
+			return g.ticketPattern(d) != nil
+		},
+	)
+}
+
+// branchTicket returns the ticket ID extracted from the current branch name
+// (or an empty string if there isn't one).
+func (g *git) branchTicket(d *command.Data) string {
+	r := g.ticketPattern(d)
+	if r == nil {
+		return ""
+	}
+	m := r.FindStringSubmatch(currentBranchArg.Get(d))
+	switch len(m) {
+	case 0:
+		return ""
+	case 1:
+		return m[0]
+	}
+	return m[1]
+}
+
+// commitMessageArg returns an argument for the commit message. The
+// argument's completer suggests commit types from the repo's commit policy
+// for the first word of the message.
+func (g *git) commitMessageArg() *commander.Argument[[]string] {
+	return commander.ListArg[string](messageArg.Name(), messageArg.Desc(), 1, command.UnboundedList,
+		commander.CompleterFromFunc(func(sl []string, d *command.Data) (*command.Completion, error) {
+			if len(sl) > 1 {
+				return nil, nil
+			}
+			cp := g.commitPolicy(d)
+			if cp == nil {
+				return nil, nil
+			}
+
+			var suggestions []string
+			for _, t := range cp.AllowedTypes() {
+				suggestions = append(suggestions, fmt.Sprintf("%s:", t), fmt.Sprintf("%s(", t))
+			}
+			return &command.Completion{
+				Suggestions:         suggestions,
+				SpacelessCompletion: true,
+			}, nil
+		}),
+	)
+}
+
+// coAuthorFlag returns a flag for adding co-authors to a commit.
+func (g *git) coAuthorFlag() commander.FlagWithType[[]string] {
+	return commander.ListFlag[string](coAuthorFlagName, 'a', "Co-authors (from the roster or in `Name <email>` format) to add as commit trailers", 1, command.UnboundedList,
+		flagValuesBreaker,
+		commander.CompleterFromFunc(func(sl []string, d *command.Data) (*command.Completion, error) {
+			suggestions := maps.Keys(g.Roster)
+
+			// Authors are only a convenience, so ignore any errors.
+			authors, _ := gitRepo(d).Authors()
+			suggestions = append(suggestions, authors...)
+			return &command.Completion{
+				Suggestions:     suggestions,
+				Distinct:        true,
+				CaseInsensitive: true,
+			}, nil
+		}),
+	)
+}
+
+var (
+	// flagValuesBreaker stops a list flag from consuming the next flag.
+	flagValuesBreaker = commander.ListUntil(commander.Not(commander.MatchesRegex("^-")))
+	trailerFlag       = commander.ListFlag[string]("trailer", 't', "Trailers (in `KEY=VALUE` format) to add to the commit", 1, command.UnboundedList,
+		flagValuesBreaker,
+		commander.ListifyValidatorOption(commander.MatchesRegex(`^[A-Za-z0-9-]+=.+$`)),
+	)
+)
+
+// commitTrailers returns the trailers (in `Key: Value` format) to add to the commit.
+func (g *git) commitTrailers(d *command.Data) ([]string, error) {
+	var trailers []string
+	for _, co := range d.StringList(coAuthorFlagName) {
+		author := co
+		if email, ok := g.Roster[co]; ok {
+			author = fmt.Sprintf("%s <%s>", co, email)
+		} else if !authorRegex.MatchString(co) {
+			return nil, fmt.Errorf("unknown co-author %q; add them to the roster with `g cfg roster add` or use the `Name <email>` format", co)
+		}
+		trailers = append(trailers, gitrepo.CoAuthorTrailer(author))
+	}
+
+	for _, t := range trailerFlag.Get(d) {
+		kv := strings.SplitN(t, "=", 2)
+		trailers = append(trailers, fmt.Sprintf("%s: %s", kv[0], kv[1]))
+	}
+	return trailers, nil
+}
+
+// commitMessage returns the commit message, prefixed with the branch's ticket
+// ID (if relevant) and validated against the repo's commit policy.
+func (g *git) commitMessage(o command.Output, d *command.Data) (string, error) {
+	msg := strings.Join(messageArg.Get(d), " ")
+	ticket := g.branchTicket(d)
+	if ticket != "" && !strings.HasPrefix(msg, ticket+":") {
+		msg = fmt.Sprintf("%s: %s", ticket, msg)
+	}
+
+	if cp := g.commitPolicy(d); cp != nil && !ignorePolicyFlag.Get(d) {
+		// The ticket prefix isn't part of the conventional commit format, but it
+		// still counts toward the header length.
+		subject := msg
+		if ticket != "" {
+			subject = strings.TrimLeft(strings.TrimPrefix(msg, ticket+":"), " ")
+		}
+		prefix := msg[:len(msg)-len(subject)]
+		if err := cp.ValidatePrefixed(prefix, strings.ReplaceAll(subject, `\n`, "\n")); err != nil {
+			return "", o.Annotatef(err, "commit message violates the commit policy for this repo (use --%s to commit anyway)", ignorePolicyFlag.Name())
+		}
+	}
+	return msg, nil
+}
+
+func (g *git) showCommitPolicies(o command.Output) {
+	if len(g.CommitPolicies) == 0 {
+		o.Stdoutln("No commit policies set")
+		return
+	}
+
+	keys := maps.Keys(g.CommitPolicies)
+	slices.Sort(keys)
+	for _, k := range keys {
+		o.Stdoutf("%s: %v\n", k, g.CommitPolicies[k])
+	}
+}
+
+func (g *git) commitPolicyConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show commit policies"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showCommitPolicies(o)
+					return nil
+				}},
+			),
+			"set": commander.SerialNodes(
+				commander.Description("Enforce conventional commit messages in this repo"),
+				commander.FlagProcessor(
+					commitTypesFlag,
+					maxSubjectLengthFlag,
+					requireCommitScopeFlag,
+				),
+				repoName,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					if g.CommitPolicies == nil {
+						g.CommitPolicies = map[string]*gitrepo.CommitPolicy{}
+					}
+					cp := &gitrepo.CommitPolicy{
+						Types:            commitTypesFlag.Get(d),
+						MaxSubjectLength: maxSubjectLengthFlag.Get(d),
+						RequireScope:     requireCommitScopeFlag.Get(d),
+					}
+					g.CommitPolicies[repoName.Get(d)] = cp
+					g.changed = true
+					o.Stdoutf("Setting commit policy for %s to %v\n", repoName.Get(d), cp)
+					return nil
+				}},
+			),
+			"unset": commander.SerialNodes(
+				commander.Description("Stop enforcing conventional commit messages in this repo"),
+				repoName,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					rn := repoName.Get(d)
+					if _, ok := g.CommitPolicies[rn]; !ok {
+						o.Stdoutln("No commit policy set for this repo")
+						return nil
+					}
+					delete(g.CommitPolicies, rn)
+					g.changed = true
+					o.Stdoutln("Deleting commit policy for", rn)
+					return nil
+				}},
+			),
+		},
+	}
+}
+
+func (g *git) showTicketPatterns(o command.Output) {
+	if len(g.TicketPatterns) == 0 {
+		o.Stdoutln("No ticket patterns set")
+		return
+	}
+
+	keys := maps.Keys(g.TicketPatterns)
+	slices.Sort(keys)
+	for _, k := range keys {
+		o.Stdoutf("%s: ticket ID from branch matching %q\n", k, g.TicketPatterns[k])
+	}
+}
+
+func (g *git) ticketPatternConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show ticket patterns"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showTicketPatterns(o)
+					return nil
+				}},
+			),
+			"set": commander.SerialNodes(
+				commander.Description("Prefix commit messages with the ticket ID in the branch name"),
+				repoName,
+				ticketPatternArg,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					if g.TicketPatterns == nil {
+						g.TicketPatterns = map[string]string{}
+					}
+					g.TicketPatterns[repoName.Get(d)] = ticketPatternArg.Get(d)
+					g.changed = true
+					o.Stdoutf("Setting ticket pattern for %s to %q\n", repoName.Get(d), ticketPatternArg.Get(d))
+					return nil
+				}},
+			),
+			"unset": commander.SerialNodes(
+				commander.Description("Stop prefixing commit messages with ticket IDs"),
+				repoName,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					rn := repoName.Get(d)
+					if _, ok := g.TicketPatterns[rn]; !ok {
+						o.Stdoutln("No ticket pattern set for this repo")
+						return nil
+					}
+					delete(g.TicketPatterns, rn)
+					g.changed = true
+					o.Stdoutln("Deleting ticket pattern for", rn)
+					return nil
+				}},
+			),
+		},
+	}
+}
+
+func (g *git) showRoster(o command.Output) {
+	if len(g.Roster) == 0 {
+		o.Stdoutln("No teammates in roster")
+		return
+	}
+
+	keys := maps.Keys(g.Roster)
+	slices.Sort(keys)
+	for _, k := range keys {
+		o.Stdoutf("%s <%s>\n", k, g.Roster[k])
+	}
+}
+
+func (g *git) rosterConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show teammates in the co-author roster"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showRoster(o)
+					return nil
+				}},
+			),
+			"add": commander.SerialNodes(
+				commander.Description("Add a teammate to the co-author roster"),
+				rosterNameArg,
+				rosterEmailArg,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					if g.Roster == nil {
+						g.Roster = map[string]string{}
+					}
+					g.Roster[rosterNameArg.Get(d)] = rosterEmailArg.Get(d)
+					g.changed = true
+					o.Stdoutf("Adding %s <%s> to roster\n", rosterNameArg.Get(d), rosterEmailArg.Get(d))
+					return nil
+				}},
+			),
+			"rm": commander.SerialNodes(
+				commander.Description("Remove a teammate from the co-author roster"),
+				commander.Arg[string](rosterNameArg.Name(), rosterNameArg.Desc(), commander.SimpleCompleter[string](maps.Keys(g.Roster)...)),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					name := rosterNameArg.Get(d)
+					if _, ok := g.Roster[name]; !ok {
+						return o.Stderrf("%s is not in the roster\n", name)
+					}
+					delete(g.Roster, name)
+					g.changed = true
+					o.Stdoutln("Removing", name, "from roster")
+					return nil
+				}},
+			),
+		},
+	}
+}
@@ -0,0 +1,318 @@
+package sourcecontrol
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	fuzzyCompletionDataKey = "FUZZY_COMPLETION"
+	completionCacheDataKey = "COMPLETION_CACHE"
+
+	// completionCacheTTL is how long cached git output is used for completions.
+	completionCacheTTL = 10 * time.Second
+
+	// directoryCompletionThreshold is the number of matching files above which
+	// file completion suggests the next directory segment instead of full paths.
+	directoryCompletionThreshold = 10
+)
+
+// completionConfig is a `command.Processor` that stores the completion config
+// in `command.Data` so it is available to the (package-level) completers.
+type completionConfig struct {
+	g *git
+}
+
+func (cc *completionConfig) Execute(*command.Input, command.Output, *command.Data, *command.ExecuteData) error {
+	return nil
+}
+
+func (cc *completionConfig) Complete(i *command.Input, d *command.Data) (*command.Completion, error) {
+	if cc.g.FuzzyCompletion {
+		d.Set(fuzzyCompletionDataKey, true)
+	}
+	if cc.g.CompletionCache {
+		d.Set(completionCacheDataKey, true)
+	}
+	return nil, nil
+}
+
+func (cc *completionConfig) Usage(*command.Input, *command.Data, *command.Usage) error {
+	return nil
+}
+
+var (
+	// completionCacheDir returns the directory in which git output is cached for
+	// completions. It is a variable so tests can use a temporary directory.
+	completionCacheDir = func() (string, error) {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "leep-frog-sourcecontrol", "completion"), nil
+	}
+)
+
+// completionRepo returns the repo used by completers, which caches git output
+// if the completion cache is enabled. Cached output is keyed by the repo's
+// HEAD and index modification time, and expires after `completionCacheTTL`.
+func completionRepo(d *command.Data) *gitrepo.Repo {
+	r := newRunner(d)
+	if !command.GetData[bool](d, completionCacheDataKey) {
+		return gitrepo.New(r)
+	}
+	dir, err := completionCacheDir()
+	if err != nil {
+		return gitrepo.New(r)
+	}
+	return gitrepo.New(&gitrepo.CachingRunner{
+		Runner: r,
+		Dir:    dir,
+		TTL:    completionCacheTTL,
+	})
+}
+
+// clearCompletionCache is a processor that clears the completion cache (if
+// enabled). It is included in commands that modify the index or working tree,
+// since not all of those changes are detected by the cache key.
+func (g *git) clearCompletionCache() command.Processor {
+	return &commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+		if !g.CompletionCache {
+			return nil
+		}
+		dir, err := completionCacheDir()
+		if err != nil {
+			return o.Annotatef(err, "failed to get completion cache directory")
+		}
+		if err := gitrepo.ClearCache(dir); err != nil {
+			return o.Annotatef(err, "failed to clear completion cache")
+		}
+		return nil
+	}}
+}
+
+// partialArg returns the (partially typed) arg that is being completed.
+func partialArg[T any](t T) string {
+	switch v := any(t).(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[len(v)-1]
+		}
+	}
+	return ""
+}
+
+// useFuzzyMatching returns whether or not the partial arg should be fuzzy matched.
+func useFuzzyMatching(partial string, d *command.Data) bool {
+	return partial != "" && command.GetData[bool](d, fuzzyCompletionDataKey)
+}
+
+// matchCompletion filters the completion's suggestions with fuzzy matching
+// (if enabled). Suggestions are always sorted alphabetically, so only the
+// suggestions in the best ranked group of matches are kept.
+func matchCompletion[T any](t T, d *command.Data, c *command.Completion) *command.Completion {
+	partial := partialArg(t)
+	if !useFuzzyMatching(partial, d) {
+		return c
+	}
+	c.Suggestions = fuzzyMatches(partial, c.Suggestions)
+	c.IgnoreFilter = true
+	return c
+}
+
+// fileCompletion is `matchCompletion` for file paths. When prefix matching,
+// many matches are reduced to their next directory segment (like regular
+// path completion).
+func fileCompletion[T any](t T, d *command.Data, c *command.Completion) *command.Completion {
+	partial := partialArg(t)
+	if useFuzzyMatching(partial, d) {
+		return matchCompletion(t, d, c)
+	}
+
+	c.Suggestions = directorySegments(partial, c.Suggestions)
+	return c
+}
+
+// directorySegments returns the paths that (case-insensitively) start with
+// the partial path. If there are more than `directoryCompletionThreshold`
+// matches, paths in subdirectories are replaced with the next directory
+// segment after the partial path. Directories that contain every match are
+// descended into.
+func directorySegments(partial string, paths []string) []string {
+	var matches []string
+	for _, p := range paths {
+		if strings.HasPrefix(strings.ToLower(p), strings.ToLower(partial)) {
+			matches = append(matches, p)
+		}
+	}
+	if len(matches) <= directoryCompletionThreshold {
+		return matches
+	}
+
+	dir := partial[:strings.LastIndex(partial, "/")+1]
+	for {
+		var segments []string
+		for _, m := range matches {
+			seg := m
+			rest := m[len(dir):]
+			// Untracked directories end with a slash, so they aren't reduced.
+			if i := strings.Index(rest, "/"); i >= 0 && i < len(rest)-1 {
+				seg = m[:len(dir)+i+1]
+			}
+			if !slices.Contains(segments, seg) {
+				segments = append(segments, seg)
+			}
+		}
+
+		if len(segments) != 1 || !strings.HasSuffix(segments[0], "/") || len(segments[0]) == len(dir) {
+			return segments
+		}
+		dir = segments[0]
+	}
+}
+
+const (
+	prefixMatch = iota
+	basenamePrefixMatch
+	substringMatch
+	subsequenceMatch
+	noMatch
+)
+
+// fuzzyMatches returns the suggestions that best match the partial arg,
+// ranking (case-insensitive) prefix matches first, then prefix matches of the
+// last path segment, then substring matches, and lastly subsequence matches
+// (e.g. `srctst` matches `sourcecontrol_test.go`).
+func fuzzyMatches(partial string, suggestions []string) []string {
+	best := noMatch
+	var matches []string
+	for _, s := range suggestions {
+		rank := fuzzyRank(strings.ToLower(partial), strings.ToLower(s))
+		if rank < best {
+			best = rank
+			matches = nil
+		}
+		if rank == best && rank != noMatch {
+			matches = append(matches, s)
+		}
+	}
+	return matches
+}
+
+func fuzzyRank(partial, s string) int {
+	switch {
+	case strings.HasPrefix(s, partial):
+		return prefixMatch
+	case strings.HasPrefix(s[strings.LastIndex(s, "/")+1:], partial):
+		return basenamePrefixMatch
+	case strings.Contains(s, partial):
+		return substringMatch
+	case isSubsequence(partial, s):
+		return subsequenceMatch
+	}
+	return noMatch
+}
+
+// isSubsequence returns whether or not all characters in `partial` appear in
+// `s` in order.
+func isSubsequence(partial, s string) bool {
+	rs := []rune(partial)
+	for _, c := range s {
+		if len(rs) == 0 {
+			break
+		}
+		if c == rs[0] {
+			rs = rs[1:]
+		}
+	}
+	return len(rs) == 0
+}
+
+func (g *git) showFuzzyCompletion(o command.Output) {
+	if g.FuzzyCompletion {
+		o.Stdoutln("Fuzzy completion: on")
+	} else {
+		o.Stdoutln("Fuzzy completion: off")
+	}
+}
+
+func (g *git) fuzzyCompletionConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show whether fuzzy completion is enabled"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showFuzzyCompletion(o)
+					return nil
+				}},
+			),
+			"on": commander.SerialNodes(
+				commander.Description("Use fuzzy (subsequence) matching in file and branch completions"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.FuzzyCompletion = true
+					g.changed = true
+					o.Stdoutln("Enabling fuzzy completion")
+					return nil
+				}},
+			),
+			"off": commander.SerialNodes(
+				commander.Description("Use prefix matching in file and branch completions"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.FuzzyCompletion = false
+					g.changed = true
+					o.Stdoutln("Disabling fuzzy completion")
+					return nil
+				}},
+			),
+		},
+	}
+}
+
+func (g *git) showCompletionCache(o command.Output) {
+	if g.CompletionCache {
+		o.Stdoutln("Completion cache: on")
+	} else {
+		o.Stdoutln("Completion cache: off")
+	}
+}
+
+func (g *git) completionCacheConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show whether the completion cache is enabled"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showCompletionCache(o)
+					return nil
+				}},
+			),
+			"on": commander.SerialNodes(
+				commander.Description("Cache git output used by file completions (useful in large repos)"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.CompletionCache = true
+					g.changed = true
+					o.Stdoutln("Enabling completion cache")
+					return nil
+				}},
+			),
+			"off": commander.SerialNodes(
+				commander.Description("Run git for every file completion"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.CompletionCache = false
+					g.changed = true
+					o.Stdoutln("Disabling completion cache")
+					return nil
+				}},
+			),
+		},
+	}
+}
@@ -0,0 +1,142 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFuzzyMatches(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		partial     string
+		suggestions []string
+		want        []string
+	}{
+		{
+			name:        "no suggestions",
+			partial:     "abc",
+			suggestions: nil,
+		},
+		{
+			name:        "no matches",
+			partial:     "xyz",
+			suggestions: []string{"abc", "def"},
+		},
+		{
+			name:        "prefix matches are ranked first",
+			partial:     "so",
+			suggestions: []string{"sourcecontrol.go", "cmd/source.go", "also.go", "s/o.go"},
+			want:        []string{"sourcecontrol.go"},
+		},
+		{
+			name:        "prefix matches are case insensitive",
+			partial:     "READ",
+			suggestions: []string{"readme.md", "README.md", "docs/README.md"},
+			want:        []string{"readme.md", "README.md"},
+		},
+		{
+			name:        "file name prefix matches are ranked second",
+			partial:     "source",
+			suggestions: []string{"cmd/source.go", "opensource.go", "s/o/u/r/c/e.go"},
+			want:        []string{"cmd/source.go"},
+		},
+		{
+			name:        "substring matches are ranked third",
+			partial:     "fix-log",
+			suggestions: []string{"abc-123-fix-login-bug", "fix-other-log", "main"},
+			want:        []string{"abc-123-fix-login-bug"},
+		},
+		{
+			name:        "subsequence matches",
+			partial:     "srctst",
+			suggestions: []string{"sourcecontrol_test.go", "sourcecontrol.go", "src/test.go"},
+			want:        []string{"sourcecontrol_test.go", "src/test.go"},
+		},
+		{
+			name:        "subsequence matches require order",
+			partial:     "ba",
+			suggestions: []string{"abc"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, fuzzyMatches(test.partial, test.suggestions)); diff != "" {
+				t.Errorf("fuzzyMatches(%q, %v) returned incorrect matches (-want, +got):\n%s", test.partial, test.suggestions, diff)
+			}
+		})
+	}
+}
+
+func TestDirectorySegments(t *testing.T) {
+	// manyFiles returns more files in the provided directory than the threshold.
+	manyFiles := func(dir string) []string {
+		var files []string
+		for i := 0; i <= directoryCompletionThreshold; i++ {
+			files = append(files, fmt.Sprintf("%sf%d.go", dir, i))
+		}
+		return files
+	}
+
+	for _, test := range []struct {
+		name    string
+		partial string
+		paths   []string
+		want    []string
+	}{
+		{
+			name:    "few matches use full paths",
+			partial: "",
+			paths:   []string{"a/b/c.go", "a/d.go", "e.go"},
+			want:    []string{"a/b/c.go", "a/d.go", "e.go"},
+		},
+		{
+			name:    "filters by prefix",
+			partial: "A/",
+			paths:   []string{"a/b/c.go", "a/d.go", "e.go"},
+			want:    []string{"a/b/c.go", "a/d.go"},
+		},
+		{
+			name:    "many matches use the next directory segment",
+			partial: "",
+			paths:   append(append(manyFiles("a/b/"), manyFiles("c/")...), "d.go", "e/"),
+			want:    []string{"a/", "c/", "d.go", "e/"},
+		},
+		{
+			name:    "segments are relative to the partial directory",
+			partial: "a/",
+			paths:   append(append(manyFiles("a/b/"), manyFiles("a/c/d/")...), "a/e.go", "f.go"),
+			want:    []string{"a/b/", "a/c/", "a/e.go"},
+		},
+		{
+			name:    "segments keep the case of the paths",
+			partial: "A/",
+			paths:   append(manyFiles("a/B/"), "a/c.go"),
+			want:    []string{"a/B/", "a/c.go"},
+		},
+		{
+			name:    "descends into directories that contain every match",
+			partial: "",
+			paths:   append(manyFiles("a/b/c/"), manyFiles("a/b/d/")...),
+			want:    []string{"a/b/c/", "a/b/d/"},
+		},
+		{
+			name:    "descends to files",
+			partial: "a",
+			paths:   manyFiles("a/b/"),
+			want:    manyFiles("a/b/"),
+		},
+		{
+			name:    "works with parent directories",
+			partial: "../",
+			paths:   append(manyFiles("../a/"), "../b.go", "c.go"),
+			want:    []string{"../a/", "../b.go"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, directorySegments(test.partial, test.paths)); diff != "" {
+				t.Errorf("directorySegments(%q, %v) returned incorrect paths (-want, +got):\n%s", test.partial, test.paths, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package gitrepo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BisectState is the state of an in-progress bisect.
+type BisectState struct {
+	// Bad is the commit marked as bad (if any).
+	Bad string
+	// Good are the commits marked as good.
+	Good []string
+	// Skipped are the commits that were skipped.
+	Skipped []string
+	// The remaining fields are only set once both a bad and a good commit are
+	// marked. Skipped commits are still counted.
+
+	// Next is the commit to test next (or the first bad commit if `Candidates`
+	// is one).
+	Next string
+	// Candidates is the number of commits that could be the first bad commit.
+	Candidates int
+	// Remaining is the number of commits left to test after `Next`.
+	Remaining int
+	// Steps is the approximate number of steps left.
+	Steps int
+}
+
+// Found returns whether or not the first bad commit has been found.
+func (bs *BisectState) Found() bool {
+	return bs.Candidates == 1
+}
+
+// Bisect returns the state of the in-progress bisect, or nil if the repo
+// isn't being bisected (or no commits have been marked yet).
+func (r *Repo) Bisect() (*BisectState, error) {
+	out, err := r.Run(NewCommand("for-each-ref", "--format=%(objectname) %(refname)", "refs/bisect/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var bs *BisectState
+	var goodRefs []string
+	for _, line := range out {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		sha, ref, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("malformed bisect ref: %q", line)
+		}
+		if bs == nil {
+			bs = &BisectState{}
+		}
+		switch {
+		case ref == "refs/bisect/bad":
+			bs.Bad = sha
+		case strings.HasPrefix(ref, "refs/bisect/good-"):
+			bs.Good = append(bs.Good, sha)
+			goodRefs = append(goodRefs, ref)
+		case strings.HasPrefix(ref, "refs/bisect/skip-"):
+			bs.Skipped = append(bs.Skipped, sha)
+		}
+	}
+	if bs == nil || bs.Bad == "" || len(bs.Good) == 0 {
+		return bs, nil
+	}
+
+	vars, err := r.Run(NewCommand(append([]string{"rev-list", "--bisect-vars", "refs/bisect/bad", "--not"}, goodRefs...)...))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range vars {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		var dst *int
+		switch k {
+		case "bisect_rev":
+			bs.Next = strings.Trim(v, "'")
+		case "bisect_all":
+			dst = &bs.Candidates
+		case "bisect_nr":
+			dst = &bs.Remaining
+		case "bisect_steps":
+			dst = &bs.Steps
+		}
+		if dst != nil {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("unexpected bisect var %q: %v", line, err)
+			}
+		}
+	}
+	return bs, nil
+}
+
+// MergeBase returns the best common ancestor of the provided refs.
+func (r *Repo) MergeBase(a, b string) (string, error) {
+	return r.single(NewCommand("merge-base", a, b))
+}
+
+// BisectStart returns a command that starts bisecting between the provided
+// bad and good commits.
+func BisectStart(bad, good string) *Command {
+	return NewCommand("bisect", "start", bad, good)
+}
+
+// BisectMark returns a command that marks the provided commits (or the
+// current commit if none are provided) with the provided term (i.e. "good",
+// "bad", or "skip").
+func BisectMark(term string, revs ...string) *Command {
+	return NewCommand(append([]string{"bisect", term}, revs...)...)
+}
+
+// BisectRun returns a command that bisects automatically by running the
+// provided command on each commit.
+func BisectRun(cmd ...string) *Command {
+	return NewCommand(append([]string{"bisect", "run"}, cmd...)...)
+}
+
+// BisectReset returns a command that stops bisecting and checks out the
+// original branch.
+func BisectReset() *Command {
+	return NewCommand("bisect", "reset")
+}
@@ -0,0 +1,131 @@
+package gitrepo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandtest"
+)
+
+func TestBisect(t *testing.T) {
+	refsRun := []string{"for-each-ref", "--format=%(objectname) %(refname)", "refs/bisect/"}
+	for _, test := range []struct {
+		name      string
+		responses []*fakeResponse
+		want      *BisectState
+		wantErr   error
+		wantRuns  [][]string
+	}{
+		{
+			name:      "not bisecting",
+			responses: []*fakeResponse{{}},
+			wantRuns:  [][]string{refsRun},
+		},
+		{
+			name: "only a bad commit",
+			responses: []*fakeResponse{{stdout: []string{
+				"bbb refs/bisect/bad",
+				"",
+			}}},
+			want:     &BisectState{Bad: "bbb"},
+			wantRuns: [][]string{refsRun},
+		},
+		{
+			name: "bisecting",
+			responses: []*fakeResponse{
+				{stdout: []string{
+					"bbb refs/bisect/bad",
+					"ggg refs/bisect/good-ggg",
+					"hhh refs/bisect/good-hhh",
+					"sss refs/bisect/skip-sss",
+				}},
+				{stdout: []string{
+					"bisect_rev='nnn'",
+					"bisect_nr=3",
+					"bisect_good=3",
+					"bisect_bad=2",
+					"bisect_all=7",
+					"bisect_steps=2",
+				}},
+			},
+			want: &BisectState{
+				Bad:        "bbb",
+				Good:       []string{"ggg", "hhh"},
+				Skipped:    []string{"sss"},
+				Next:       "nnn",
+				Candidates: 7,
+				Remaining:  3,
+				Steps:      2,
+			},
+			wantRuns: [][]string{
+				refsRun,
+				{"rev-list", "--bisect-vars", "refs/bisect/bad", "--not", "refs/bisect/good-ggg", "refs/bisect/good-hhh"},
+			},
+		},
+		{
+			name: "found the first bad commit",
+			responses: []*fakeResponse{
+				{stdout: []string{
+					"bbb refs/bisect/bad",
+					"ggg refs/bisect/good-ggg",
+				}},
+				{stdout: []string{
+					"bisect_rev='bbb'",
+					"bisect_nr=0",
+					"bisect_all=1",
+					"bisect_steps=0",
+				}},
+			},
+			want: &BisectState{
+				Bad:        "bbb",
+				Good:       []string{"ggg"},
+				Next:       "bbb",
+				Candidates: 1,
+			},
+			wantRuns: [][]string{
+				refsRun,
+				{"rev-list", "--bisect-vars", "refs/bisect/bad", "--not", "refs/bisect/good-ggg"},
+			},
+		},
+		{
+			name:      "fails on malformed refs",
+			responses: []*fakeResponse{{stdout: []string{"bbb"}}},
+			wantErr:   fmt.Errorf(`malformed bisect ref: "bbb"`),
+			wantRuns:  [][]string{refsRun},
+		},
+		{
+			name: "fails on malformed bisect vars",
+			responses: []*fakeResponse{
+				{stdout: []string{
+					"bbb refs/bisect/bad",
+					"ggg refs/bisect/good-ggg",
+				}},
+				{stdout: []string{"bisect_nr=many"}},
+			},
+			wantErr: fmt.Errorf(`unexpected bisect var "bisect_nr=many": strconv.Atoi: parsing "many": invalid syntax`),
+			wantRuns: [][]string{
+				refsRun,
+				{"rev-list", "--bisect-vars", "refs/bisect/bad", "--not", "refs/bisect/good-ggg"},
+			},
+		},
+		{
+			name:      "fails if git fails",
+			responses: []*fakeResponse{{err: fmt.Errorf("oops")}},
+			wantErr:   fmt.Errorf("oops"),
+			wantRuns:  [][]string{refsRun},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fr := &fakeRunner{responses: test.responses}
+			got, err := New(fr).Bisect()
+			commandtest.CmpError(t, "Bisect()", test.wantErr, err)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Bisect() returned incorrect state (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantRuns, fr.got); diff != "" {
+				t.Errorf("Bisect() ran incorrect commands (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package gitrepo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Branch is a local git branch.
+type Branch struct {
+	// Name is the name of the branch.
+	Name string
+	// Current is whether or not the branch is checked out.
+	Current bool
+}
+
+// Branches returns the local branches of the repo.
+func (r *Repo) Branches() ([]*Branch, error) {
+	out, err := r.Run(NewCommand("branch", "--list"))
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []*Branch
+	for _, line := range out {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		b := &Branch{}
+		// "*" indicates the current branch and "+" indicates a branch checked
+		// out in another worktree.
+		if strings.HasPrefix(line, "*") {
+			b.Current = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "+") {
+			line = line[1:]
+		}
+		b.Name = strings.TrimSpace(line)
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+// Tags returns the names of the repo's tags.
+func (r *Repo) Tags() ([]string, error) {
+	out, err := r.Run(NewCommand("tag", "--list"))
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, t := range out {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags, nil
+}
+
+// Checkout returns a command that checks out the provided branch (creating it if `create` is true).
+func Checkout(branch string, create bool) *Command {
+	if create {
+		return NewCommand("checkout", "-b", branch)
+	}
+	return NewCommand("checkout", branch)
+}
+
+// DeleteBranch returns a command that deletes the provided branch.
+func DeleteBranch(branch string, force bool) *Command {
+	if force {
+		return NewCommand("branch", "-D", branch)
+	}
+	return NewCommand("branch", "-d", branch)
+}
+
+// RenameBranch returns a command that renames the provided branch.
+func RenameBranch(oldName, newName string) *Command {
+	return NewCommand("branch", "-m", oldName, newName)
+}
+
+// Merge returns a command that merges the provided ref into the current branch.
+func Merge(ref string) *Command {
+	return NewCommand("merge", ref)
+}
+
+// BranchInfo is metadata about a local branch.
+type BranchInfo struct {
+	// Name is the name of the branch.
+	Name string `json:"name"`
+	// Current is whether or not the branch is checked out.
+	Current bool `json:"current"`
+	// LastCommit is the commit time of the branch's last commit.
+	LastCommit time.Time `json:"lastCommit"`
+	// Author is the author of the branch's last commit.
+	Author string `json:"author"`
+	// Upstream is the upstream branch (or an empty string if there isn't one).
+	Upstream string `json:"upstream,omitempty"`
+	// UpstreamGone is whether or not the upstream branch was deleted.
+	UpstreamGone bool `json:"upstreamGone"`
+	// Ahead and Behind are the number of commits the branch is ahead of and
+	// behind its upstream.
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+	// BaseAhead and BaseBehind are the number of commits the branch is ahead
+	// of and behind the base branch.
+	BaseAhead  int `json:"baseAhead"`
+	BaseBehind int `json:"baseBehind"`
+	// Merged is whether or not the branch is merged into the base branch.
+	Merged bool `json:"merged"`
+}
+
+const branchInfoFormat = "%(refname:short)%00%(HEAD)%00%(committerdate:unix)%00%(authorname)%00%(upstream:short)%00%(upstream:track,nobracket)"
+
+// BranchInfos returns metadata about the local branches of the repo, compared
+// against the provided base branch. Base comparisons are skipped if the base
+// branch doesn't exist.
+func (r *Repo) BranchInfos(base string) ([]*BranchInfo, error) {
+	out, err := r.Run(NewCommand("for-each-ref", "--format="+branchInfoFormat, "refs/heads"))
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []*BranchInfo
+	for _, line := range out {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		bi, err := parseBranchInfo(line)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, bi)
+	}
+
+	if len(infos) == 0 || !r.RefExists(base) {
+		return infos, nil
+	}
+
+	merged, err := r.Run(NewCommand("for-each-ref", "--format=%(refname:short)", "--merged="+base, "refs/heads"))
+	if err != nil {
+		return nil, err
+	}
+	for _, bi := range infos {
+		bi.Merged = slices.Contains(merged, bi.Name)
+		if bi.BaseAhead, bi.BaseBehind, err = r.AheadBehind(bi.Name, base); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+func parseBranchInfo(line string) (*BranchInfo, error) {
+	parts := strings.Split(line, "\x00")
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("unexpected for-each-ref output: %q", line)
+	}
+	ts, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected commit time %q: %v", parts[2], err)
+	}
+
+	bi := &BranchInfo{
+		Name:       parts[0],
+		Current:    parts[1] == "*",
+		LastCommit: time.Unix(ts, 0),
+		Author:     parts[3],
+		Upstream:   parts[4],
+	}
+
+	// The track is one of "", "gone", "ahead N", "behind N", or "ahead N, behind M".
+	for _, t := range strings.Split(parts[5], ", ") {
+		k, v, _ := strings.Cut(t, " ")
+		switch k {
+		case "gone":
+			bi.UpstreamGone = true
+		case "ahead":
+			bi.Ahead, err = strconv.Atoi(v)
+		case "behind":
+			bi.Behind, err = strconv.Atoi(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unexpected upstream track %q: %v", parts[5], err)
+		}
+	}
+	return bi, nil
+}
+
+// RecentBranches returns the branches that were checked out (according to the
+// reflog), most recent first and without duplicates. The result may include
+// branches that have since been deleted and commit SHAs (for detached HEADs).
+func (r *Repo) RecentBranches() ([]string, error) {
+	out, err := r.Run(NewCommand("reflog", "--format=%gs"))
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []string
+	add := func(b string) {
+		if !slices.Contains(recent, b) {
+			recent = append(recent, b)
+		}
+	}
+	for _, line := range out {
+		from, to, ok := strings.Cut(strings.TrimPrefix(line, "checkout: moving from "), " to ")
+		if !ok || !strings.HasPrefix(line, "checkout: ") {
+			continue
+		}
+		// The branch that was moved to was checked out more recently.
+		add(to)
+		add(from)
+	}
+	return recent, nil
+}
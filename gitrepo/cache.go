@@ -0,0 +1,148 @@
+package gitrepo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CachingRunner is a `Runner` that caches command output in files (one per
+// repo). Cached output is only used if it is younger than `TTL` and neither
+// HEAD nor the index has changed since it was cached. Caching is best effort:
+// if the cache can't be read or written, commands are simply run.
+type CachingRunner struct {
+	// Runner runs the commands whose output isn't cached.
+	Runner Runner
+	// Dir is the directory in which the cache files are stored.
+	Dir string
+	// TTL is how long cached output is used for.
+	TTL time.Duration
+	// Now returns the current time. Defaults to `time.Now`.
+	Now func() time.Time
+}
+
+// runnerCache is the contents of a repo's cache file.
+type runnerCache struct {
+	Head       string
+	IndexMTime time.Time
+	Entries    map[string]*runnerCacheEntry
+}
+
+type runnerCacheEntry struct {
+	Time   time.Time
+	Output []string
+}
+
+// Run returns the cached output of the command if it is still valid, and
+// otherwise runs (and caches) it.
+func (cr *CachingRunner) Run(args ...string) ([]string, error) {
+	// One command gets everything needed to identify the repo state. The
+	// prefix is included in the key since output can depend on the directory.
+	out, err := cr.Runner.Run("rev-parse", "--absolute-git-dir", "--show-prefix", "HEAD")
+	if err != nil || len(out) != 3 {
+		// No cache for repos without commits (or outside of repos).
+		return cr.Runner.Run(args...)
+	}
+	gitDir, prefix, head := out[0], out[1], out[2]
+
+	indexMTime := indexModTime(gitDir)
+
+	now := time.Now
+	if cr.Now != nil {
+		now = cr.Now
+	}
+
+	file := cr.file(gitDir)
+	key := strings.Join(append([]string{prefix}, args...), "\x00")
+	cache := readRunnerCache(file)
+	if cache == nil || cache.Head != head || !cache.IndexMTime.Equal(indexMTime) {
+		cache = newRunnerCache(head, indexMTime)
+	}
+	if e, ok := cache.Entries[key]; ok && now().Sub(e.Time) < cr.TTL {
+		return e.Output, nil
+	}
+
+	if out, err = cr.Runner.Run(args...); err != nil {
+		return nil, err
+	}
+	// Commands like `git status` refresh the index, so the modification time
+	// is checked again to avoid invalidating the output right away.
+	if mt := indexModTime(gitDir); !mt.Equal(indexMTime) {
+		cache = newRunnerCache(head, mt)
+	}
+	cache.Entries[key] = &runnerCacheEntry{now(), out}
+	writeRunnerCache(file, cache)
+	return out, nil
+}
+
+func newRunnerCache(head string, indexMTime time.Time) *runnerCache {
+	return &runnerCache{
+		Head:       head,
+		IndexMTime: indexMTime,
+		Entries:    map[string]*runnerCacheEntry{},
+	}
+}
+
+// indexModTime returns the modification time of the repo's index (or the zero
+// time if there is no index).
+func indexModTime(gitDir string) time.Time {
+	fi, err := os.Stat(filepath.Join(gitDir, "index"))
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// file returns the path of the cache file for the repo.
+func (cr *CachingRunner) file(gitDir string) string {
+	sum := sha256.Sum256([]byte(gitDir))
+	return filepath.Join(cr.Dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+func readRunnerCache(file string) *runnerCache {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var c runnerCache
+	if err := json.Unmarshal(b, &c); err != nil || c.Entries == nil {
+		return nil
+	}
+	return &c
+}
+
+func writeRunnerCache(file string, c *runnerCache) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return
+	}
+	// Write to a temporary file first so concurrent completions never read a
+	// partially written cache.
+	tmp, err := os.CreateTemp(filepath.Dir(file), "cache-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// ClearCache deletes all output cached by `CachingRunner`s that use the
+// provided directory.
+func ClearCache(dir string) error {
+	return os.RemoveAll(dir)
+}
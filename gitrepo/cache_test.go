@@ -0,0 +1,135 @@
+package gitrepo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"github.com/leep-frog/sourcecontrol/gitrepo/gitrepotest"
+)
+
+// countingRunner is a `gitrepo.Runner` that records the commands it runs.
+type countingRunner struct {
+	gitrepo.Runner
+	statusRuns int
+}
+
+func (cr *countingRunner) Run(args ...string) ([]string, error) {
+	if len(args) > 0 && args[0] == "status" {
+		cr.statusRuns++
+	}
+	return cr.Runner.Run(args...)
+}
+
+func TestCachingRunnerWithRealRepo(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		// initial creates the repo.
+		initial func(*testing.T) *gitrepotest.TestRepo
+		// between is run between the two status commands.
+		between func(*testing.T, *gitrepotest.TestRepo, *gitrepo.CachingRunner)
+		// subdir is the directory in which the second status command is run.
+		subdir         string
+		wantStatusRuns int
+		wantSecond     []string
+	}{
+		{
+			name:           "uses cached output",
+			wantStatusRuns: 1,
+			wantSecond:     []string{"?? untracked.txt"},
+		},
+		{
+			name: "cached output expires",
+			between: func(t *testing.T, tr *gitrepotest.TestRepo, cr *gitrepo.CachingRunner) {
+				cr.Now = func() time.Time { return time.Now().Add(time.Minute) }
+			},
+			wantStatusRuns: 2,
+			wantSecond:     []string{"?? untracked.txt"},
+		},
+		{
+			name: "index changes invalidate the cache",
+			between: func(t *testing.T, tr *gitrepotest.TestRepo, cr *gitrepo.CachingRunner) {
+				// Ensure the index modification time changes.
+				time.Sleep(10 * time.Millisecond)
+				tr.Git("add", "untracked.txt")
+			},
+			wantStatusRuns: 2,
+			wantSecond:     []string{"A  untracked.txt"},
+		},
+		{
+			name: "HEAD changes invalidate the cache",
+			between: func(t *testing.T, tr *gitrepotest.TestRepo, cr *gitrepo.CachingRunner) {
+				tr.Git("commit", "--allow-empty", "--message", "Empty commit")
+			},
+			wantStatusRuns: 2,
+			wantSecond:     []string{"?? untracked.txt"},
+		},
+		{
+			name: "clearing the cache",
+			between: func(t *testing.T, tr *gitrepotest.TestRepo, cr *gitrepo.CachingRunner) {
+				if err := gitrepo.ClearCache(cr.Dir); err != nil {
+					t.Fatalf("ClearCache() returned error: %v", err)
+				}
+			},
+			wantStatusRuns: 2,
+			wantSecond:     []string{"?? untracked.txt"},
+		},
+		{
+			name:           "output is cached per directory",
+			subdir:         "sub",
+			wantStatusRuns: 2,
+			wantSecond:     []string{"?? ../untracked.txt"},
+		},
+		{
+			name:           "output isn't cached in repos without commits",
+			initial:        gitrepotest.New,
+			wantStatusRuns: 2,
+			wantSecond:     []string{"?? untracked.txt"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			initial := gitrepotest.NewWithCommit
+			if test.initial != nil {
+				initial = test.initial
+			}
+			tr := initial(t)
+			tr.WriteFile("sub/.gitkeep", "")
+			tr.Git("add", "sub/.gitkeep")
+			tr.WriteFile("untracked.txt", "new\n")
+
+			cacheDir := t.TempDir()
+			counter := &countingRunner{Runner: tr.Runner()}
+			cr := &gitrepo.CachingRunner{
+				Runner: counter,
+				Dir:    cacheDir,
+				TTL:    time.Second,
+			}
+			if _, err := cr.Run("status", "--short", "--", "untracked.txt"); err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+
+			if test.between != nil {
+				test.between(t, tr, cr)
+			}
+
+			if test.subdir != "" {
+				counter.Runner = tr.SubdirRunner(test.subdir)
+			}
+			args := []string{"status", "--short", "--", "untracked.txt"}
+			if test.subdir != "" {
+				args[len(args)-1] = "../untracked.txt"
+			}
+			got, err := cr.Run(args...)
+			if err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+			if diff := cmp.Diff(test.wantSecond, got); diff != "" {
+				t.Errorf("Run() returned incorrect output (-want, +got):\n%s", diff)
+			}
+			if counter.statusRuns != test.wantStatusRuns {
+				t.Errorf("Run() ran status %d times; want %d", counter.statusRuns, test.wantStatusRuns)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package gitrepo
+
+import (
+	"fmt"
+)
+
+// PushOptions are the options for a `Push` command.
+type PushOptions struct {
+	// SetUpstream is whether or not to set the upstream of `Branch` to `Remote`.
+	SetUpstream bool
+	// Remote is the remote to push to.
+	Remote string
+	// Branch is the branch to push.
+	Branch string
+	// ForceWithLease is whether or not to force push (only if the remote branch
+	// is where we last saw it).
+	ForceWithLease bool
+	// LeaseRef and LeaseSHA pin the lease so the force push only succeeds if
+	// the remote `LeaseRef` is at `LeaseSHA`. If unset, the lease is on the
+	// remote-tracking branch.
+	LeaseRef string
+	LeaseSHA string
+}
+
+// Push returns a command that pushes to the remote.
+func Push(opts *PushOptions) *Command {
+	if opts == nil {
+		return NewCommand("push")
+	}
+
+	args := []string{"push"}
+	if opts.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	if opts.ForceWithLease {
+		if opts.LeaseRef != "" {
+			args = append(args, fmt.Sprintf("--force-with-lease=%s:%s", opts.LeaseRef, opts.LeaseSHA))
+		} else {
+			args = append(args, "--force-with-lease")
+		}
+	}
+	if opts.Remote != "" {
+		args = append(args, opts.Remote)
+	}
+	if opts.Branch != "" {
+		args = append(args, opts.Branch)
+	}
+	return NewCommand(args...)
+}
+
+// DeleteRemoteBranch returns a command that deletes the provided branch from the remote.
+func DeleteRemoteBranch(remote, branch string) *Command {
+	return NewCommand("push", remote, "--delete", branch)
+}
+
+// PullOptions are the options for a `Pull` command.
+type PullOptions struct {
+	// Rebase is whether or not to rebase the current branch onto the upstream
+	// branch (instead of merging).
+	Rebase bool
+	// FFOnly is whether or not to only pull if the current branch can be
+	// fast-forwarded.
+	FFOnly bool
+}
+
+// Pull returns a command that pulls from the upstream branch.
+func Pull(opts *PullOptions) *Command {
+	args := []string{"pull"}
+	if opts != nil && opts.Rebase {
+		args = append(args, "--rebase")
+	}
+	if opts != nil && opts.FFOnly {
+		args = append(args, "--ff-only")
+	}
+	return NewCommand(args...)
+}
+
+// Fetch returns a command that fetches from the remote.
+func Fetch() *Command {
+	return NewCommand("fetch")
+}
+
+// FetchRemote returns a command that fetches from the provided remote.
+func FetchRemote(remote string) *Command {
+	return NewCommand("fetch", remote)
+}
+
+// Add returns a command that stages the provided paths (or all changes if none are provided).
+func Add(paths ...string) *Command {
+	if len(paths) == 0 {
+		return NewCommand("add", ".")
+	}
+	return NewCommand(append([]string{"add"}, paths...)...)
+}
+
+// Discard returns a command that discards unstaged changes to the provided paths.
+func Discard(paths ...string) *Command {
+	return NewCommand(append([]string{"checkout", "--"}, paths...)...)
+}
+
+// Unstage returns a command that unstages the provided paths.
+func Unstage(paths ...string) *Command {
+	return NewCommand(append([]string{"reset", "--"}, paths...)...)
+}
+
+// DiffOptions are the options for a `Diff` command.
+type DiffOptions struct {
+	// IgnoreWhitespace is whether or not to ignore whitespace changes.
+	IgnoreWhitespace bool
+	// Base is the ref to diff against. If empty, the working tree is diffed
+	// against the index.
+	Base string
+	// Paths limits the diff to the provided paths.
+	Paths []string
+}
+
+// Diff returns a command that shows changes.
+func Diff(opts *DiffOptions) *Command {
+	args := []string{"diff"}
+	if opts.IgnoreWhitespace {
+		args = append(args, "-w")
+	}
+	if opts.Base != "" {
+		args = append(args, opts.Base)
+	}
+	return NewCommand(append(append(args, "--"), opts.Paths...)...)
+}
+
+// Log returns a command that shows the last `n` commits.
+func Log(n int) *Command {
+	return NewCommand("log", "-n", fmt.Sprintf("%d", n))
+}
+
+// BlameOptions are options for `Blame`.
+type BlameOptions struct {
+	// Path is the file to blame.
+	Path string
+	// Start is the first line to blame (if positive).
+	Start int
+	// End is the last line to blame (if positive). Lines through the end of
+	// the file are blamed if only `Start` is set.
+	End int
+	// IgnoreWhitespace is whether or not to ignore whitespace changes.
+	IgnoreWhitespace bool
+	// IgnoreRevsFile is a file with commits to ignore (e.g. formatting changes).
+	IgnoreRevsFile string
+}
+
+// Blame returns a command that shows the commit, author, and relative date
+// that last changed each line of a file.
+func Blame(opts *BlameOptions) *Command {
+	args := []string{"blame", "--date=relative"}
+	if opts.IgnoreWhitespace {
+		args = append(args, "-w")
+	}
+	if opts.IgnoreRevsFile != "" {
+		args = append(args, "--ignore-revs-file", opts.IgnoreRevsFile)
+	}
+	if opts.Start > 0 {
+		end := ""
+		if opts.End > 0 {
+			end = fmt.Sprintf("%d", opts.End)
+		}
+		args = append(args, "-L", fmt.Sprintf("%d,%s", opts.Start, end))
+	}
+	return NewCommand(append(args, "--", opts.Path)...)
+}
+
+// GrepOptions are options for `Grep`.
+type GrepOptions struct {
+	// Pattern is the pattern to search for.
+	Pattern string
+	// Paths limits the search to the provided files and directories.
+	Paths []string
+	// Ref is the branch, tag, or commit to search (instead of the working
+	// tree). Each result is prefixed with `Ref:`.
+	Ref string
+	// IgnoreCase is whether or not to ignore case differences.
+	IgnoreCase bool
+	// WordRegexp is whether or not to only match whole words.
+	WordRegexp bool
+	// FixedStrings is whether or not to match `Pattern` as a literal string.
+	FixedStrings bool
+	// ExtendedRegexp is whether or not `Pattern` is an extended regex.
+	ExtendedRegexp bool
+}
+
+// Grep returns a command that searches tracked files and prints each match
+// as `file:line:text` (binary files are skipped).
+func Grep(opts *GrepOptions) *Command {
+	args := []string{"grep", "-n", "-I"}
+	if opts.IgnoreCase {
+		args = append(args, "-i")
+	}
+	if opts.WordRegexp {
+		args = append(args, "-w")
+	}
+	if opts.FixedStrings {
+		args = append(args, "-F")
+	}
+	if opts.ExtendedRegexp {
+		args = append(args, "-E")
+	}
+	// `-e` allows patterns that start with a dash.
+	args = append(args, "-e", opts.Pattern)
+	if opts.Ref != "" {
+		args = append(args, opts.Ref)
+	}
+	return NewCommand(append(append(args, "--"), opts.Paths...)...)
+}
+
+// StashPush returns a command that stashes changes.
+func StashPush(args ...string) *Command {
+	return NewCommand(append([]string{"stash", "push"}, args...)...)
+}
+
+// StashPop returns a command that pops stashed changes.
+func StashPop(args ...string) *Command {
+	return NewCommand(append([]string{"stash", "pop"}, args...)...)
+}
+
+// RebaseAbort returns a command that aborts the in-progress rebase.
+func RebaseAbort() *Command {
+	return NewCommand("rebase", "--abort")
+}
+
+// RebaseContinue returns a command that continues the in-progress rebase.
+func RebaseContinue() *Command {
+	return NewCommand("rebase", "--continue")
+}
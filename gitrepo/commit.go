@@ -0,0 +1,177 @@
+package gitrepo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// CommitOptions are the options for a `Commit` command.
+type CommitOptions struct {
+	// Message is the commit message.
+	Message string
+	// NoVerify is whether or not to skip pre-commit hooks.
+	NoVerify bool
+	// Amend is whether or not to amend the previous commit.
+	Amend bool
+	// NoEdit is whether or not to reuse the previous commit message (only relevant with `Amend`).
+	NoEdit bool
+	// Trailers are `Key: Value` trailers to add to the commit message.
+	Trailers []string
+}
+
+// Commit returns a command that commits the staged changes.
+func Commit(opts *CommitOptions) *Command {
+	args := []string{"commit"}
+	if opts.Amend {
+		args = append(args, "--amend")
+	}
+	if opts.NoEdit {
+		args = append(args, "--no-edit")
+	}
+	if opts.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	if opts.Message != "" {
+		args = append(args, "-m", opts.Message)
+	}
+	for _, t := range opts.Trailers {
+		args = append(args, "--trailer", t)
+	}
+	return NewCommand(args...)
+}
+
+// CoAuthorTrailer returns a commit trailer that credits the provided
+// author (in `Name <email>` format).
+func CoAuthorTrailer(author string) string {
+	return fmt.Sprintf("Co-authored-by: %s", author)
+}
+
+// Authors returns the authors (in `Name <email>` format) of the commits
+// reachable from HEAD, ordered by number of commits.
+func (r *Repo) Authors() ([]string, error) {
+	out, err := r.Run(NewCommand("shortlog", "-sne", "HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	var authors []string
+	for _, line := range out {
+		if m := shortlogRegex.FindStringSubmatch(line); m != nil {
+			authors = append(authors, strings.TrimSpace(m[1]))
+		}
+	}
+	return authors, nil
+}
+
+// RecentCommits returns the short SHAs of the `n` most recent commits
+// reachable from HEAD.
+func (r *Repo) RecentCommits(n int) ([]string, error) {
+	out, err := r.Run(NewCommand("log", "--format=%h", fmt.Sprintf("-n%d", n)))
+	if err != nil {
+		return nil, err
+	}
+	var shas []string
+	for _, sha := range out {
+		if sha = strings.TrimSpace(sha); sha != "" {
+			shas = append(shas, sha)
+		}
+	}
+	return shas, nil
+}
+
+// UndoCommit returns a command that undoes the last commit, but keeps its changes.
+func UndoCommit() *Command {
+	return NewCommand("reset", "HEAD~")
+}
+
+var (
+	// DefaultCommitTypes are the commit types used when a `CommitPolicy`
+	// doesn't explicitly define any.
+	DefaultCommitTypes = []string{
+		"build",
+		"chore",
+		"ci",
+		"docs",
+		"feat",
+		"fix",
+		"perf",
+		"refactor",
+		"revert",
+		"style",
+		"test",
+	}
+
+	// Matches lines output by `git shortlog -sne` (e.g. "    12\tName <email>")
+	shortlogRegex = regexp.MustCompile(`^\s*[0-9]+\s+(.+)$`)
+
+	// Matches headers of the form `type(scope)!: subject`
+	conventionalHeaderRegex = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+)
+
+// CommitPolicy is a policy that enforces the Conventional Commits format
+// (https://www.conventionalcommits.org) for commit messages.
+type CommitPolicy struct {
+	// Types is the set of allowed commit types. If empty, DefaultCommitTypes is used.
+	Types []string
+	// MaxSubjectLength is the maximum length of the commit message header.
+	MaxSubjectLength int
+	// RequireScope is whether or not a scope must be provided in the header.
+	RequireScope bool
+}
+
+// AllowedTypes returns the commit types allowed by the policy.
+func (cp *CommitPolicy) AllowedTypes() []string {
+	if len(cp.Types) == 0 {
+		return DefaultCommitTypes
+	}
+	return cp.Types
+}
+
+// Validate returns an error if the provided commit message doesn't satisfy the policy.
+func (cp *CommitPolicy) Validate(message string) error {
+	return cp.ValidatePrefixed("", message)
+}
+
+// ValidatePrefixed is `Validate` for a commit message that will be committed
+// with the provided prefix (e.g. a ticket ID). The prefix isn't part of the
+// Conventional Commits format, but it counts toward `MaxSubjectLength`.
+func (cp *CommitPolicy) ValidatePrefixed(prefix, message string) error {
+	lines := strings.Split(message, "\n")
+	header := lines[0]
+
+	m := conventionalHeaderRegex.FindStringSubmatch(header)
+	if m == nil {
+		return fmt.Errorf(`commit message header %q is not of the form "type(scope): subject"`, header)
+	}
+
+	if !slices.Contains(cp.AllowedTypes(), m[1]) {
+		return fmt.Errorf("commit type %q is not one of %v", m[1], cp.AllowedTypes())
+	}
+
+	if cp.RequireScope && strings.TrimSpace(m[2]) == "" {
+		return fmt.Errorf("commit message header %q is missing a scope", header)
+	}
+
+	if strings.TrimSpace(m[4]) == "" {
+		return fmt.Errorf("commit message header %q is missing a subject", header)
+	}
+
+	if n := len(prefix) + len(header); cp.MaxSubjectLength > 0 && n > cp.MaxSubjectLength {
+		return fmt.Errorf("commit message header is %d characters long (max %d)", n, cp.MaxSubjectLength)
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		return fmt.Errorf("commit message header must be followed by a blank line")
+	}
+	return nil
+}
+
+func (cp *CommitPolicy) String() string {
+	scope := ""
+	if cp.RequireScope {
+		scope = ", scope required"
+	}
+	return fmt.Sprintf("types=%v, max subject length=%d%s", cp.AllowedTypes(), cp.MaxSubjectLength, scope)
+}
@@ -0,0 +1,102 @@
+package gitrepo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leep-frog/command/commandtest"
+)
+
+func TestCommitPolicyValidate(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		cp      *CommitPolicy
+		prefix  string
+		message string
+		wantErr error
+	}{
+		{
+			name:    "accepts simple header",
+			cp:      &CommitPolicy{},
+			message: "feat: add a thing",
+		},
+		{
+			name:    "accepts header with scope and breaking change",
+			cp:      &CommitPolicy{RequireScope: true},
+			message: "fix(parser)!: handle empty input",
+		},
+		{
+			name:    "accepts header and body",
+			cp:      &CommitPolicy{MaxSubjectLength: 72},
+			message: "docs: update README\n\nAdd a section on config.",
+		},
+		{
+			name:    "rejects header without type",
+			cp:      &CommitPolicy{},
+			message: "add a thing",
+			wantErr: fmt.Errorf(`commit message header "add a thing" is not of the form "type(scope): subject"`),
+		},
+		{
+			name:    "rejects unknown type",
+			cp:      &CommitPolicy{},
+			message: "feature: add a thing",
+			wantErr: fmt.Errorf(`commit type "feature" is not one of [build chore ci docs feat fix perf refactor revert style test]`),
+		},
+		{
+			name:    "rejects type not in custom types",
+			cp:      &CommitPolicy{Types: []string{"feat"}},
+			message: "fix: a thing",
+			wantErr: fmt.Errorf(`commit type "fix" is not one of [feat]`),
+		},
+		{
+			name:    "rejects missing scope",
+			cp:      &CommitPolicy{RequireScope: true},
+			message: "fix: a thing",
+			wantErr: fmt.Errorf(`commit message header "fix: a thing" is missing a scope`),
+		},
+		{
+			name:    "rejects empty scope",
+			cp:      &CommitPolicy{RequireScope: true},
+			message: "fix(): a thing",
+			wantErr: fmt.Errorf(`commit message header "fix(): a thing" is missing a scope`),
+		},
+		{
+			name:    "rejects missing subject",
+			cp:      &CommitPolicy{},
+			message: "fix: ",
+			wantErr: fmt.Errorf(`commit message header "fix: " is missing a subject`),
+		},
+		{
+			name:    "rejects long header",
+			cp:      &CommitPolicy{MaxSubjectLength: 12},
+			message: "fix: a long thing",
+			wantErr: fmt.Errorf("commit message header is 17 characters long (max 12)"),
+		},
+		{
+			name:    "accepts prefixed header",
+			cp:      &CommitPolicy{MaxSubjectLength: 21},
+			prefix:  "ABC-123: ",
+			message: "fix: a thing",
+		},
+		{
+			name:    "rejects long header with prefix",
+			cp:      &CommitPolicy{MaxSubjectLength: 20},
+			prefix:  "ABC-123: ",
+			message: "fix: a thing",
+			wantErr: fmt.Errorf("commit message header is 21 characters long (max 20)"),
+		},
+		{
+			name:    "rejects body without blank line",
+			cp:      &CommitPolicy{},
+			message: "fix: a thing\nmore details",
+			wantErr: fmt.Errorf("commit message header must be followed by a blank line"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if test.prefix == "" {
+				commandtest.CmpError(t, fmt.Sprintf("Validate(%q)", test.message), test.wantErr, test.cp.Validate(test.message))
+			}
+			commandtest.CmpError(t, fmt.Sprintf("ValidatePrefixed(%q, %q)", test.prefix, test.message), test.wantErr, test.cp.ValidatePrefixed(test.prefix, test.message))
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Package gitrepotest contains helpers for testing against real git repos.
+package gitrepotest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+)
+
+var (
+	// env isolates test repos from the user's (and system's) git config.
+	env = []string{
+		"GIT_CONFIG_GLOBAL=" + os.DevNull,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Test User",
+		"GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test User",
+		"GIT_COMMITTER_EMAIL=test@example.com",
+	}
+)
+
+// TestRepo is a git repo in a temporary directory.
+type TestRepo struct {
+	// Dir is the root directory of the repo.
+	Dir string
+
+	t *testing.T
+}
+
+// New creates an empty repo (with `main` as the initial branch) in a
+// temporary directory. The test is skipped if git isn't installed.
+func New(t *testing.T) *TestRepo {
+	t.Helper()
+	tr := newRepo(t)
+	tr.Git("init", "--initial-branch=main")
+	return tr
+}
+
+// NewWithCommit creates a repo with a single committed README file.
+func NewWithCommit(t *testing.T) *TestRepo {
+	t.Helper()
+	tr := New(t)
+	tr.WriteFile("README.md", "# Test repo\n")
+	tr.Commit("Initial commit")
+	return tr
+}
+
+func newRepo(t *testing.T) *TestRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skipf("git is not installed: %v", err)
+	}
+	return &TestRepo{
+		Dir: t.TempDir(),
+		t:   t,
+	}
+}
+
+// AddRemote creates a bare repo, adds it as a remote of this repo, and
+// returns it.
+func (tr *TestRepo) AddRemote(name string) *TestRepo {
+	tr.t.Helper()
+	remote := newRepo(tr.t)
+	remote.Git("init", "--bare", "--initial-branch=main")
+	tr.Git("remote", "add", name, remote.Dir)
+	return remote
+}
+
+// AddSubmodule creates a repo with a single commit, adds it as a submodule
+// at the provided path, commits the submodule, and returns it.
+func (tr *TestRepo) AddSubmodule(path string) *TestRepo {
+	tr.t.Helper()
+	sub := NewWithCommit(tr.t)
+	// Local submodules are disallowed by default.
+	tr.Git("-c", "protocol.file.allow=always", "submodule", "add", sub.Dir, path)
+	tr.Commit(fmt.Sprintf("Add %s submodule", path))
+	return sub
+}
+
+// Runner returns a `gitrepo.Runner` that runs git in the repo.
+func (tr *TestRepo) Runner() gitrepo.Runner {
+	return &gitrepo.ExecRunner{
+		Dir: tr.Dir,
+		Env: env,
+	}
+}
+
+// SubdirRunner returns a `gitrepo.Runner` that runs git in the provided
+// subdirectory of the repo.
+func (tr *TestRepo) SubdirRunner(dir string) gitrepo.Runner {
+	return &gitrepo.ExecRunner{
+		Dir: tr.Path(dir),
+		Env: env,
+	}
+}
+
+// Repo returns a `gitrepo.Repo` for the repo.
+func (tr *TestRepo) Repo() *gitrepo.Repo {
+	return gitrepo.New(tr.Runner())
+}
+
+// Git runs git with the provided arguments and fails the test if it fails.
+func (tr *TestRepo) Git(args ...string) []string {
+	tr.t.Helper()
+	out, err := tr.Runner().Run(args...)
+	if err != nil {
+		tr.t.Fatalf("git command failed: %v", err)
+	}
+	return out
+}
+
+// GitErr runs git with the provided arguments and returns any error (for
+// commands that are expected to fail, like merges with conflicts).
+func (tr *TestRepo) GitErr(args ...string) error {
+	_, err := tr.Runner().Run(args...)
+	return err
+}
+
+// Exec runs the provided shell commands (e.g. a command's `Executable`) in the
+// repo and fails the test if they fail.
+func (tr *TestRepo) Exec(cmds ...string) string {
+	tr.t.Helper()
+	cmd := exec.Command("bash", "-c", strings.Join(cmds, "\n"))
+	cmd.Dir = tr.Dir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		tr.t.Fatalf("failed to run %q: %v: %s", cmds, err, out)
+	}
+	return string(out)
+}
+
+// Path returns the absolute path of the provided repo-relative path.
+func (tr *TestRepo) Path(path string) string {
+	return filepath.Join(tr.Dir, filepath.FromSlash(path))
+}
+
+// WriteFile writes the contents to the provided path (creating any parent
+// directories).
+func (tr *TestRepo) WriteFile(path, contents string) {
+	tr.t.Helper()
+	p := tr.Path(path)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		tr.t.Fatalf("failed to create directory for %q: %v", path, err)
+	}
+	if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+		tr.t.Fatalf("failed to write file %q: %v", path, err)
+	}
+}
+
+// ReadFile returns the contents of the provided file.
+func (tr *TestRepo) ReadFile(path string) string {
+	tr.t.Helper()
+	b, err := os.ReadFile(tr.Path(path))
+	if err != nil {
+		tr.t.Fatalf("failed to read file %q: %v", path, err)
+	}
+	return string(b)
+}
+
+// RemoveFile removes the provided file.
+func (tr *TestRepo) RemoveFile(path string) {
+	tr.t.Helper()
+	if err := os.Remove(tr.Path(path)); err != nil {
+		tr.t.Fatalf("failed to remove file %q: %v", path, err)
+	}
+}
+
+// Commit stages all changes and commits them.
+func (tr *TestRepo) Commit(message string) {
+	tr.t.Helper()
+	tr.Git("add", "-A")
+	tr.Git("commit", "-m", message)
+}
+
+// Conflict checks out a new branch that changes the provided file, changes
+// the same file on the current branch, and then merges the new branch so the
+// file is left in a conflicted state.
+func (tr *TestRepo) Conflict(path string) {
+	tr.t.Helper()
+	branch := tr.CurrentBranch()
+	tr.Git("checkout", "-b", "conflict-"+branch)
+	tr.WriteFile(path, "theirs\n")
+	tr.Commit("Their change")
+	tr.Git("checkout", branch)
+	tr.WriteFile(path, "ours\n")
+	tr.Commit("Our change")
+	if err := tr.GitErr("merge", "conflict-"+branch); err == nil {
+		tr.t.Fatalf("merge unexpectedly succeeded")
+	}
+}
+
+// CurrentBranch returns the checked out branch.
+func (tr *TestRepo) CurrentBranch() string {
+	tr.t.Helper()
+	b, err := tr.Repo().CurrentBranch()
+	if err != nil {
+		tr.t.Fatalf("failed to get current branch: %v", err)
+	}
+	return b
+}
+
+// Status returns the status entries of the repo.
+func (tr *TestRepo) Status() []*gitrepo.StatusEntry {
+	tr.t.Helper()
+	entries, err := tr.Repo().Status()
+	if err != nil {
+		tr.t.Fatalf("failed to get status: %v", err)
+	}
+	return entries
+}
+
+// Log returns the subjects of the last n commits (most recent first).
+func (tr *TestRepo) Log(n int) []string {
+	tr.t.Helper()
+	return tr.Git("log", "-n", strconv.Itoa(n), "--format=%s")
+}
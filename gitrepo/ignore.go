@@ -0,0 +1,110 @@
+package gitrepo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Matches the rule in `check-ignore --verbose` output (`<source>:<line>:<pattern>`)
+	ignoreRuleRegex = regexp.MustCompile(`^(.+?):([0-9]+):(.*)$`)
+)
+
+// IgnoreRule is the ignore rule that matches a path.
+type IgnoreRule struct {
+	// Path is the ignored path (as provided to `IgnoreRules`).
+	Path string
+	// Source is the file that contains the rule (e.g. ".gitignore" or
+	// ".git/info/exclude").
+	Source string
+	// Line is the line number of the rule in the source file.
+	Line int
+	// Pattern is the pattern that matches the path.
+	Pattern string
+}
+
+// IgnoredFiles returns the ignored files (and directories, with a trailing
+// slash) relative to the root of the repo.
+func (r *Repo) IgnoredFiles() ([]string, error) {
+	out, err := r.Run(NewCommand("-c", "status.relativePaths=false", "status", "--porcelain=v2", "--ignored"))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ParseStatus(out)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.Type == Ignored {
+			files = append(files, e.Path)
+		}
+	}
+	return files, nil
+}
+
+// IgnoreRules returns the rules that match the provided (ignored) paths.
+// Paths that aren't ignored are excluded from the result.
+func (r *Repo) IgnoreRules(paths ...string) ([]*IgnoreRule, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	// check-ignore fails if none of the paths are ignored, so --non-matching
+	// is used and the non-matching paths (which have no source) are skipped.
+	out, err := r.Run(NewCommand(append([]string{"check-ignore", "--verbose", "--non-matching", "--"}, paths...)...))
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*IgnoreRule
+	for _, line := range out {
+		if line == "" {
+			continue
+		}
+		rule, err := parseIgnoreRule(line)
+		if err != nil {
+			return nil, err
+		}
+		if rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func parseIgnoreRule(line string) (*IgnoreRule, error) {
+	// <source>:<line>:<pattern><tab><path>
+	rule, path, ok := strings.Cut(line, "\t")
+	if !ok {
+		return nil, fmt.Errorf("malformed check-ignore output: %q", line)
+	}
+	if rule == "::" {
+		return nil, nil
+	}
+
+	// Both the source (e.g. `C:\repo\.gitignore`) and the pattern may contain
+	// colons, so the rule is split around the first `:<line>:`.
+	m := ignoreRuleRegex.FindStringSubmatch(rule)
+	if m == nil {
+		return nil, fmt.Errorf("malformed check-ignore output: %q", line)
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed check-ignore line number: %q", line)
+	}
+	return &IgnoreRule{
+		Path:    unquotePath(path),
+		Source:  unquotePath(m[1]),
+		Line:    n,
+		Pattern: m[3],
+	}, nil
+}
+
+// GitPath returns the absolute path of the provided path in the repo's git
+// directory (e.g. "info/exclude").
+func (r *Repo) GitPath(path string) (string, error) {
+	return r.single(NewCommand("rev-parse", "--path-format=absolute", "--git-path", path))
+}
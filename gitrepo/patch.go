@@ -0,0 +1,305 @@
+package gitrepo
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Matches hunk headers of the form `@@ -oldStart,oldLines +newStart,newLines @@ section`
+	hunkHeaderRegex = regexp.MustCompile(`^@@ -([0-9]+)(?:,([0-9]+))? \+([0-9]+)(?:,([0-9]+))? @@(.*)$`)
+)
+
+// FileDiff is the diff of a single file.
+type FileDiff struct {
+	// Header contains the lines before the first hunk (`diff --git ...`,
+	// `index ...`, `--- a/...`, `+++ b/...`).
+	Header []string
+	// Path is the path of the file (relative to the root of the repo).
+	Path string
+	// Hunks are the hunks of the diff.
+	Hunks []*Hunk
+}
+
+// Hunk is a single hunk of a diff.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Section is the text after the hunk range (usually the enclosing function).
+	Section string
+	// Lines are the lines of the hunk, each prefixed with ' ', '-', '+', or '\'.
+	Lines []string
+}
+
+// FileDiffs returns the unstaged (or staged, if `cached` is true) diffs of
+// the provided paths (or all files, if none are provided).
+func (r *Repo) FileDiffs(cached bool, paths ...string) ([]*FileDiff, error) {
+	args := []string{"diff"}
+	if cached {
+		args = append(args, "--cached")
+	}
+	args = append(args, "--no-color", "--no-ext-diff", "--")
+	out, err := r.Run(NewCommand(append(args, paths...)...))
+	if err != nil {
+		return nil, err
+	}
+	return ParseDiff(out)
+}
+
+// ParseDiff parses the output of `git diff`.
+func ParseDiff(lines []string) ([]*FileDiff, error) {
+	var diffs []*FileDiff
+	var fd *FileDiff
+	var h *Hunk
+	for _, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			fd = &FileDiff{Header: []string{line}}
+			h = nil
+			diffs = append(diffs, fd)
+			continue
+		}
+
+		if fd == nil {
+			return nil, fmt.Errorf("diff line is not part of a file diff: %q", line)
+		}
+
+		if strings.HasPrefix(line, "@@") {
+			var err error
+			if h, err = parseHunkHeader(line); err != nil {
+				return nil, err
+			}
+			fd.Hunks = append(fd.Hunks, h)
+			continue
+		}
+
+		if h == nil {
+			fd.Header = append(fd.Header, line)
+			if p, ok := strings.CutPrefix(line, "+++ "); ok && p != "/dev/null" {
+				fd.Path = unquotePath(strings.TrimPrefix(unquotePath(p), "b/"))
+			} else if p, ok := strings.CutPrefix(line, "--- "); ok && p != "/dev/null" && fd.Path == "" {
+				fd.Path = unquotePath(strings.TrimPrefix(unquotePath(p), "a/"))
+			}
+			continue
+		}
+
+		// Empty context lines may have their trailing space stripped.
+		if line == "" {
+			line = " "
+		}
+		h.Lines = append(h.Lines, line)
+	}
+	return diffs, nil
+}
+
+func parseHunkHeader(line string) (*Hunk, error) {
+	m := hunkHeaderRegex.FindStringSubmatch(line)
+	if m == nil {
+		return nil, fmt.Errorf("malformed hunk header: %q", line)
+	}
+	atoi := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		i, _ := strconv.Atoi(s)
+		return i
+	}
+	return &Hunk{
+		OldStart: atoi(m[1]),
+		OldLines: atoi(m[2]),
+		NewStart: atoi(m[3]),
+		NewLines: atoi(m[4]),
+		Section:  m[5],
+	}, nil
+}
+
+// Header returns the hunk's `@@ ... @@` header line.
+func (h *Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", h.OldStart, h.OldLines, h.NewStart, h.NewLines, h.Section)
+}
+
+// String returns the hunk as it appears in a patch.
+func (h *Hunk) String() string {
+	return strings.Join(append([]string{h.Header()}, h.Lines...), "\n")
+}
+
+// lineKinds returns the kind (' ', '-', or '+') of each line. "\ No newline"
+// markers are given the kind of the line they annotate.
+func (h *Hunk) lineKinds() []byte {
+	kinds := make([]byte, len(h.Lines))
+	for i, l := range h.Lines {
+		k := l[0]
+		if k == '\\' && i > 0 {
+			k = kinds[i-1]
+		}
+		kinds[i] = k
+	}
+	return kinds
+}
+
+// Split splits the hunk into smaller hunks that each contain a single block of
+// consecutive changes. The hunk itself is returned if it can't be split.
+// Context lines between two blocks are included in both of the resulting hunks.
+func (h *Hunk) Split() []*Hunk {
+	kinds := h.lineKinds()
+
+	// Find the [start, end) ranges of each block of changes.
+	type block struct{ start, end int }
+	var blocks []*block
+	for i, k := range kinds {
+		if k == ' ' {
+			continue
+		}
+		if len(blocks) > 0 && blocks[len(blocks)-1].end == i {
+			blocks[len(blocks)-1].end = i + 1
+		} else {
+			blocks = append(blocks, &block{i, i + 1})
+		}
+	}
+	if len(blocks) <= 1 {
+		return []*Hunk{h}
+	}
+
+	var hunks []*Hunk
+	for bi := range blocks {
+		start, end := 0, len(h.Lines)
+		if bi > 0 {
+			start = blocks[bi-1].end
+		}
+		if bi < len(blocks)-1 {
+			end = blocks[bi+1].start
+		}
+
+		sub := &Hunk{
+			OldStart: h.OldStart,
+			NewStart: h.NewStart,
+			Section:  h.Section,
+			Lines:    h.Lines[start:end],
+		}
+		for i := 0; i < start; i++ {
+			if kinds[i] != '+' && h.Lines[i][0] != '\\' {
+				sub.OldStart++
+			}
+			if kinds[i] != '-' && h.Lines[i][0] != '\\' {
+				sub.NewStart++
+			}
+		}
+		sub.recount()
+		hunks = append(hunks, sub)
+	}
+	return hunks
+}
+
+// recount sets the line counts of the hunk from its lines.
+func (h *Hunk) recount() {
+	h.OldLines, h.NewLines = 0, 0
+	for _, l := range h.Lines {
+		switch l[0] {
+		case ' ':
+			h.OldLines++
+			h.NewLines++
+		case '-':
+			h.OldLines++
+		case '+':
+			h.NewLines++
+		}
+	}
+}
+
+// Patch returns a patch that contains only the provided hunks (in order) of
+// the file. Hunks that overlap or touch (e.g. the pieces of a split hunk,
+// which share their context lines) are merged, since git can't apply
+// overlapping hunks.
+func (fd *FileDiff) Patch(hunks []*Hunk) string {
+	lines := append([]string{}, fd.Header...)
+	for _, h := range mergeHunks(hunks) {
+		lines = append(lines, h.String())
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// mergeHunks merges consecutive hunks whose old line ranges overlap or touch.
+func mergeHunks(hunks []*Hunk) []*Hunk {
+	var merged []*Hunk
+	for _, h := range hunks {
+		if len(merged) == 0 {
+			merged = append(merged, h)
+			continue
+		}
+		prev := merged[len(merged)-1]
+		overlap := prev.OldStart + prev.OldLines - h.OldStart
+		if overlap < 0 {
+			merged = append(merged, h)
+			continue
+		}
+
+		// Skip the lines that are already in the previous hunk.
+		i := 0
+		for ; i < len(h.Lines) && overlap > 0; i++ {
+			if k := h.Lines[i][0]; k == ' ' || k == '-' {
+				overlap--
+			}
+		}
+		for ; i < len(h.Lines) && h.Lines[i][0] == '\\'; i++ {
+		}
+
+		m := &Hunk{
+			OldStart: prev.OldStart,
+			NewStart: prev.NewStart,
+			Section:  prev.Section,
+			Lines:    append(append([]string{}, prev.Lines...), h.Lines[i:]...),
+		}
+		m.recount()
+		merged[len(merged)-1] = m
+	}
+	return merged
+}
+
+// ApplyOptions are the options for `Apply`.
+type ApplyOptions struct {
+	// Cached is whether or not to apply the patch to the index (instead of the
+	// working tree).
+	Cached bool
+	// Reverse is whether or not to apply the patch in reverse.
+	Reverse bool
+}
+
+// Apply applies the patch in the provided file. The patch is applied from the
+// root of the repo since git ignores paths outside of the current directory.
+func (r *Repo) Apply(patchFile string, opts *ApplyOptions) error {
+	top, err := r.TopLevel()
+	if err != nil {
+		return err
+	}
+	args := []string{"-C", top, "apply", "--recount"}
+	if opts.Cached {
+		args = append(args, "--cached")
+	}
+	if opts.Reverse {
+		args = append(args, "--reverse")
+	}
+	_, err = r.Run(NewCommand(append(args, patchFile)...))
+	return err
+}
+
+// ApplyPatch writes the patch to a temporary file and applies it.
+func (r *Repo) ApplyPatch(patch string, opts *ApplyOptions) error {
+	f, err := os.CreateTemp("", "gitrepo-*.patch")
+	if err != nil {
+		return fmt.Errorf("failed to create patch file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(patch); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write patch file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close patch file: %v", err)
+	}
+	return r.Apply(f.Name(), opts)
+}
@@ -0,0 +1,242 @@
+package gitrepo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandtest"
+)
+
+func TestParseDiff(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		lines   []string
+		want    []*FileDiff
+		wantErr error
+	}{
+		{
+			name: "handles empty diff",
+		},
+		{
+			name: "parses multiple files and hunks",
+			lines: []string{
+				"diff --git a/one.txt b/one.txt",
+				"index 1234567..89abcde 100644",
+				"--- a/one.txt",
+				"+++ b/one.txt",
+				"@@ -1,3 +1,3 @@ func main() {",
+				" a",
+				"-b",
+				"+B",
+				" c",
+				"@@ -10 +10,2 @@",
+				" j",
+				"+k",
+				"diff --git a/new file.txt b/new file.txt",
+				"new file mode 100644",
+				"index 0000000..1234567",
+				"--- /dev/null",
+				"+++ b/new file.txt",
+				"@@ -0,0 +1 @@",
+				"+hello",
+				`\ No newline at end of file`,
+			},
+			want: []*FileDiff{
+				{
+					Header: []string{
+						"diff --git a/one.txt b/one.txt",
+						"index 1234567..89abcde 100644",
+						"--- a/one.txt",
+						"+++ b/one.txt",
+					},
+					Path: "one.txt",
+					Hunks: []*Hunk{
+						{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Section: " func main() {", Lines: []string{" a", "-b", "+B", " c"}},
+						{OldStart: 10, OldLines: 1, NewStart: 10, NewLines: 2, Lines: []string{" j", "+k"}},
+					},
+				},
+				{
+					Header: []string{
+						"diff --git a/new file.txt b/new file.txt",
+						"new file mode 100644",
+						"index 0000000..1234567",
+						"--- /dev/null",
+						"+++ b/new file.txt",
+					},
+					Path: "new file.txt",
+					Hunks: []*Hunk{
+						{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1, Lines: []string{"+hello", `\ No newline at end of file`}},
+					},
+				},
+			},
+		},
+		{
+			name: "parses deleted and quoted files",
+			lines: []string{
+				`diff --git "a/tab\there.txt" "b/tab\there.txt"`,
+				"deleted file mode 100644",
+				"--- \"a/tab\\there.txt\"",
+				"+++ /dev/null",
+				"@@ -1 +0,0 @@",
+				"-bye",
+			},
+			want: []*FileDiff{
+				{
+					Header: []string{
+						`diff --git "a/tab\there.txt" "b/tab\there.txt"`,
+						"deleted file mode 100644",
+						"--- \"a/tab\\there.txt\"",
+						"+++ /dev/null",
+					},
+					Path: "tab\there.txt",
+					Hunks: []*Hunk{
+						{OldStart: 1, OldLines: 1, NewStart: 0, NewLines: 0, Lines: []string{"-bye"}},
+					},
+				},
+			},
+		},
+		{
+			name: "handles binary files",
+			lines: []string{
+				"diff --git a/img.png b/img.png",
+				"index 1234567..89abcde 100644",
+				"Binary files a/img.png and b/img.png differ",
+			},
+			want: []*FileDiff{
+				{
+					Header: []string{
+						"diff --git a/img.png b/img.png",
+						"index 1234567..89abcde 100644",
+						"Binary files a/img.png and b/img.png differ",
+					},
+				},
+			},
+		},
+		{
+			name:    "fails if line isn't in a file diff",
+			lines:   []string{"@@ -1 +1 @@"},
+			wantErr: fmt.Errorf(`diff line is not part of a file diff: "@@ -1 +1 @@"`),
+		},
+		{
+			name: "fails on malformed hunk header",
+			lines: []string{
+				"diff --git a/one.txt b/one.txt",
+				"@@ -x +1 @@",
+			},
+			wantErr: fmt.Errorf(`malformed hunk header: "@@ -x +1 @@"`),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseDiff(test.lines)
+			commandtest.CmpError(t, "ParseDiff()", test.wantErr, err)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ParseDiff() returned incorrect diffs (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHunkSplit(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		h    *Hunk
+		want []*Hunk
+	}{
+		{
+			name: "doesn't split hunk with one block of changes",
+			h:    &Hunk{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: []string{" a", "-b", "+B", " c"}},
+			want: []*Hunk{
+				{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: []string{" a", "-b", "+B", " c"}},
+			},
+		},
+		{
+			name: "splits hunk with multiple blocks of changes",
+			h: &Hunk{
+				OldStart: 5, OldLines: 8, NewStart: 7, NewLines: 8, Section: " section",
+				Lines: []string{" a", "-b", "+B", " c", " d", "+e", " f", "-g", " h"},
+			},
+			want: []*Hunk{
+				{OldStart: 5, OldLines: 4, NewStart: 7, NewLines: 4, Section: " section", Lines: []string{" a", "-b", "+B", " c", " d"}},
+				{OldStart: 7, OldLines: 3, NewStart: 9, NewLines: 4, Section: " section", Lines: []string{" c", " d", "+e", " f"}},
+				{OldStart: 9, OldLines: 3, NewStart: 12, NewLines: 2, Section: " section", Lines: []string{" f", "-g", " h"}},
+			},
+		},
+		{
+			name: "keeps no newline markers with their line",
+			h: &Hunk{
+				OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+				Lines: []string{"-a", "+A", " b", "-c", `\ No newline at end of file`, "+C", `\ No newline at end of file`},
+			},
+			want: []*Hunk{
+				{OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2, Lines: []string{"-a", "+A", " b"}},
+				{OldStart: 2, OldLines: 2, NewStart: 2, NewLines: 2, Lines: []string{" b", "-c", `\ No newline at end of file`, "+C", `\ No newline at end of file`}},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, test.h.Split()); diff != "" {
+				t.Errorf("Hunk.Split() returned incorrect hunks (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileDiffPatch(t *testing.T) {
+	header := []string{
+		"diff --git a/one.txt b/one.txt",
+		"--- a/one.txt",
+		"+++ b/one.txt",
+	}
+	// The pieces of a split hunk (see TestHunkSplit).
+	split := []*Hunk{
+		{OldStart: 5, OldLines: 4, NewStart: 7, NewLines: 4, Section: " section", Lines: []string{" a", "-b", "+B", " c", " d"}},
+		{OldStart: 7, OldLines: 3, NewStart: 9, NewLines: 4, Section: " section", Lines: []string{" c", " d", "+e", " f"}},
+		{OldStart: 9, OldLines: 3, NewStart: 12, NewLines: 2, Section: " section", Lines: []string{" f", "-g", " h"}},
+	}
+	for _, test := range []struct {
+		name  string
+		hunks []*Hunk
+		want  []string
+	}{
+		{
+			name: "includes only the provided hunks",
+			hunks: []*Hunk{
+				{OldStart: 9, OldLines: 1, NewStart: 9, NewLines: 2, Section: " func", Lines: []string{" i", "+j"}},
+			},
+			want: []string{"@@ -9,1 +9,2 @@ func", " i", "+j"},
+		},
+		{
+			name: "keeps separate hunks separate",
+			hunks: []*Hunk{
+				{OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2, Lines: []string{"-a", "+A", " b"}},
+				{OldStart: 9, OldLines: 1, NewStart: 9, NewLines: 2, Section: " func", Lines: []string{" i", "+j"}},
+			},
+			want: []string{"@@ -1,2 +1,2 @@", "-a", "+A", " b", "@@ -9,1 +9,2 @@ func", " i", "+j"},
+		},
+		{
+			name:  "merges overlapping pieces of a split hunk",
+			hunks: split[:2],
+			want:  []string{"@@ -5,5 +7,6 @@ section", " a", "-b", "+B", " c", " d", "+e", " f"},
+		},
+		{
+			name:  "merges all pieces of a split hunk",
+			hunks: split,
+			want:  []string{"@@ -5,7 +7,7 @@ section", " a", "-b", "+B", " c", " d", "+e", " f", "-g", " h"},
+		},
+		{
+			name:  "merges touching pieces of a split hunk",
+			hunks: []*Hunk{split[0], split[2]},
+			want:  []string{"@@ -5,7 +7,6 @@ section", " a", "-b", "+B", " c", " d", " f", "-g", " h"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fd := &FileDiff{Header: header}
+			want := strings.Join(append(append([]string{}, header...), test.want...), "\n") + "\n"
+			if diff := cmp.Diff(want, fd.Patch(test.hunks)); diff != "" {
+				t.Errorf("FileDiff.Patch() returned incorrect patch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
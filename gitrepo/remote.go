@@ -0,0 +1,94 @@
+package gitrepo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Upstream returns the upstream of the provided branch (e.g. "origin/main").
+func (r *Repo) Upstream(branch string) (string, error) {
+	return r.single(NewCommand("rev-parse", "--abbrev-ref", "--symbolic-full-name", branch+"@{upstream}"))
+}
+
+// HasUpstream returns whether or not the provided branch has an upstream.
+func (r *Repo) HasUpstream(branch string) bool {
+	_, err := r.Upstream(branch)
+	return err == nil
+}
+
+// RefExists returns whether or not the provided ref exists.
+func (r *Repo) RefExists(ref string) bool {
+	_, err := r.Run(NewCommand("rev-parse", "--verify", "--quiet", ref))
+	return err == nil
+}
+
+// RevParse returns the commit SHA of the provided ref.
+func (r *Repo) RevParse(ref string) (string, error) {
+	return r.single(NewCommand("rev-parse", "--verify", ref))
+}
+
+// Commits returns the commits (in `<short SHA> <subject>` format) that are
+// reachable from `ref` but not from `base`, most recent first.
+func (r *Repo) Commits(base, ref string) ([]string, error) {
+	out, err := r.Run(NewCommand("log", "--format=%h %s", fmt.Sprintf("%s..%s", base, ref)))
+	if err != nil {
+		return nil, err
+	}
+	var commits []string
+	for _, c := range out {
+		if c = strings.TrimSpace(c); c != "" {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+// AheadBehind returns the number of commits in `ref` that aren't in `base`
+// (ahead) and the number of commits in `base` that aren't in `ref` (behind).
+func (r *Repo) AheadBehind(ref, base string) (int, int, error) {
+	out, err := r.single(NewCommand("rev-list", "--left-right", "--count", fmt.Sprintf("%s...%s", ref, base)))
+	if err != nil {
+		return 0, 0, err
+	}
+	parts := strings.Fields(out)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", out)
+	}
+	ahead, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", out)
+	}
+	behind, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", out)
+	}
+	return ahead, behind, nil
+}
+
+// FastForward fast-forwards the provided local branch to `ref` without
+// checking it out. It fails if the branch can't be fast-forwarded.
+func (r *Repo) FastForward(branch, ref string) error {
+	_, err := r.Run(NewCommand("fetch", ".", fmt.Sprintf("%s:%s", ref, branch)))
+	return err
+}
+
+// Conflicts returns the paths of files with merge conflicts.
+func (r *Repo) Conflicts() ([]string, error) {
+	entries, err := r.Status()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.Type == Unmerged {
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths, nil
+}
+
+// Rebase returns a command that rebases the current branch onto the provided ref.
+func Rebase(ref string) *Command {
+	return NewCommand("rebase", ref)
+}
@@ -0,0 +1,245 @@
+// Package gitrepo contains typed git operations that can be shared by tools
+// built on top of git (such as the `g` CLI in the parent package).
+package gitrepo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Runner runs git commands.
+type Runner interface {
+	// Run runs git with the provided arguments and returns stdout split by line.
+	Run(args ...string) ([]string, error)
+}
+
+// ExecRunner is a `Runner` that runs the git executable.
+type ExecRunner struct {
+	// Dir is the directory in which to run git. Defaults to the current directory.
+	Dir string
+	// Env are additional environment variables (in `KEY=VALUE` format) to set
+	// when running git.
+	Env []string
+}
+
+// Run runs git with the provided arguments.
+func (er *ExecRunner) Run(args ...string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = er.Dir
+	if len(er.Env) > 0 {
+		cmd.Env = append(os.Environ(), er.Env...)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run %q: %v: %s", (&Command{args}).String(), err, strings.TrimSpace(stderr.String()))
+	}
+	return SplitLines(stdout.String()), nil
+}
+
+// SplitLines splits command output into lines, excluding the trailing newline.
+func SplitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// Repo runs git operations with a `Runner`.
+type Repo struct {
+	runner Runner
+}
+
+// New returns a `Repo` that runs git commands with the provided `Runner`.
+func New(r Runner) *Repo {
+	return &Repo{r}
+}
+
+// Run runs the provided command and returns its output.
+func (r *Repo) Run(c *Command) ([]string, error) {
+	return r.runner.Run(c.Args...)
+}
+
+func (r *Repo) single(c *Command) (string, error) {
+	out, err := r.Run(c)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.Join(out, "\n")), nil
+}
+
+// RemoteURL returns the URL of the provided remote.
+func (r *Repo) RemoteURL(remote string) (string, error) {
+	return r.single(NewCommand("config", "--get", fmt.Sprintf("remote.%s.url", remote)))
+}
+
+// CurrentBranch returns the name of the checked out branch ("HEAD" if detached).
+func (r *Repo) CurrentBranch() (string, error) {
+	return r.single(NewCommand("rev-parse", "--abbrev-ref", "HEAD"))
+}
+
+// TopLevel returns the absolute path of the root of the repo.
+func (r *Repo) TopLevel() (string, error) {
+	return r.single(NewCommand("rev-parse", "--show-toplevel"))
+}
+
+// GitDir returns the absolute path of the repo's git directory.
+func (r *Repo) GitDir() (string, error) {
+	return r.single(NewCommand("rev-parse", "--absolute-git-dir"))
+}
+
+// DefaultBranch returns the default branch of the provided remote, as
+// determined by the remote's HEAD ref.
+func (r *Repo) DefaultBranch(remote string) (string, error) {
+	ref, err := r.single(NewCommand("symbolic-ref", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", remote)))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(ref, remote+"/"), nil
+}
+
+// DiffFiles returns the names of files with unstaged (or staged, if `cached`
+// is true) changes, relative to the root of the repo.
+func (r *Repo) DiffFiles(cached bool) ([]string, error) {
+	args := []string{"diff"}
+	if cached {
+		args = append(args, "--cached")
+	}
+	out, err := r.Run(NewCommand(append(args, "--name-only")...))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range out {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// TrackedFiles returns the names of all tracked files, relative to the root
+// of the repo.
+func (r *Repo) TrackedFiles() ([]string, error) {
+	// The ":/" pathspec includes files outside of the current directory.
+	out, err := r.Run(NewCommand("ls-files", "--full-name", "--", ":/"))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range out {
+		if f != "" {
+			files = append(files, unquotePath(f))
+		}
+	}
+	return files, nil
+}
+
+// Prefix returns the path of the current directory relative to the root of
+// the repo (with a trailing slash, or an empty string at the root).
+func (r *Repo) Prefix() (string, error) {
+	return r.single(NewCommand("rev-parse", "--show-prefix"))
+}
+
+// RelativePaths converts paths relative to the root of the repo into paths
+// relative to the current directory (which may start with "../").
+func (r *Repo) RelativePaths(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return paths, nil
+	}
+	prefix, err := r.Prefix()
+	if err != nil {
+		return nil, err
+	}
+	if prefix == "" {
+		return paths, nil
+	}
+
+	var rel []string
+	for _, p := range paths {
+		rp, err := filepath.Rel(filepath.FromSlash(prefix), filepath.FromSlash(p))
+		if err != nil {
+			return nil, err
+		}
+		rp = filepath.ToSlash(rp)
+		// Directories (e.g. untracked ones) keep their trailing slash.
+		if strings.HasSuffix(p, "/") {
+			rp += "/"
+		}
+		rel = append(rel, rp)
+	}
+	return rel, nil
+}
+
+// Command is a git command. Commands are built by the functions in this
+// package and can either be run by a `Repo` or rendered for a shell.
+type Command struct {
+	// Args are the arguments to pass to git.
+	Args []string
+}
+
+// NewCommand returns a `Command` with the provided git arguments.
+func NewCommand(args ...string) *Command {
+	return &Command{args}
+}
+
+var (
+	// Args that only contain these characters don't need to be quoted in bash.
+	shellSafeRegex = regexp.MustCompile(`^[a-zA-Z0-9_+=:,./~^-]+$`)
+	// Commas separate array elements in PowerShell.
+	powerShellSafeRegex = regexp.MustCompile(`^[a-zA-Z0-9_+=:./~^-]+$`)
+	// Characters that the shell still expands inside of double quotes.
+	shellExpansionReplacer = strings.NewReplacer("$", `\$`, "`", "\\`")
+)
+
+// Shell is a shell that commands can be formatted for.
+type Shell int
+
+const (
+	// Bash is bash (or any other POSIX shell).
+	Bash Shell = iota
+	// PowerShell is Windows PowerShell.
+	PowerShell
+)
+
+// String returns the command as a string that can be run in bash.
+// Arguments are double-quoted when necessary.
+func (c *Command) String() string {
+	return c.StringFor(Bash)
+}
+
+// StringFor returns the command as a string that can be run in the provided
+// shell. Arguments are quoted when necessary: double-quoted (with shell
+// expansions escaped) in bash, and single-quoted in PowerShell (which doesn't
+// expand anything in single quotes).
+func (c *Command) StringFor(sh Shell) string {
+	safe, quote := shellSafeRegex, bashQuote
+	if sh == PowerShell {
+		safe, quote = powerShellSafeRegex, powerShellQuote
+	}
+
+	r := []string{"git"}
+	for _, a := range c.Args {
+		if safe.MatchString(a) {
+			r = append(r, a)
+		} else {
+			r = append(r, quote(a))
+		}
+	}
+	return strings.Join(r, " ")
+}
+
+func bashQuote(s string) string {
+	return shellExpansionReplacer.Replace(fmt.Sprintf("%q", s))
+}
+
+func powerShellQuote(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
+}
@@ -0,0 +1,342 @@
+package gitrepo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"github.com/leep-frog/sourcecontrol/gitrepo/gitrepotest"
+)
+
+func TestStatusWithRealRepo(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		setup func(*gitrepotest.TestRepo)
+		want  []*gitrepo.StatusEntry
+	}{
+		{
+			name: "clean repo",
+		},
+		{
+			name: "modified, added, deleted, and untracked files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("modified.txt", "one\n")
+				tr.WriteFile("deleted.txt", "two\n")
+				tr.Commit("Add files")
+
+				tr.WriteFile("modified.txt", "changed\n")
+				tr.RemoveFile("deleted.txt")
+				tr.WriteFile("added.txt", "three\n")
+				tr.Git("add", "added.txt")
+				tr.WriteFile("untracked.txt", "four\n")
+			},
+			want: []*gitrepo.StatusEntry{
+				{Type: gitrepo.Changed, XY: "A.", Submodule: "N...", Path: "added.txt"},
+				{Type: gitrepo.Changed, XY: ".D", Submodule: "N...", Path: "deleted.txt"},
+				{Type: gitrepo.Changed, XY: ".M", Submodule: "N...", Path: "modified.txt"},
+				{Type: gitrepo.Untracked, Path: "untracked.txt"},
+			},
+		},
+		{
+			name: "renamed files and files with spaces",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("old name.txt", "some contents\n")
+				tr.Commit("Add file")
+
+				tr.Git("mv", "old name.txt", "new name.txt")
+				tr.WriteFile("un tracked.txt", "hello\n")
+			},
+			want: []*gitrepo.StatusEntry{
+				{Type: gitrepo.Renamed, XY: "R.", Submodule: "N...", Path: "new name.txt", OrigPath: "old name.txt"},
+				{Type: gitrepo.Untracked, Path: "un tracked.txt"},
+			},
+		},
+		{
+			name: "quoted paths",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("tab\there.txt", "hello\n")
+			},
+			want: []*gitrepo.StatusEntry{
+				{Type: gitrepo.Untracked, Path: "tab\there.txt"},
+			},
+		},
+		{
+			name: "merge conflicts",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("conflict.txt", "base\n")
+				tr.Commit("Add file")
+				tr.Conflict("conflict.txt")
+			},
+			want: []*gitrepo.StatusEntry{
+				{Type: gitrepo.Unmerged, XY: "UU", Submodule: "N...", Path: "conflict.txt"},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tr := gitrepotest.NewWithCommit(t)
+			if test.setup != nil {
+				test.setup(tr)
+			}
+
+			got, err := tr.Repo().Status()
+			if err != nil {
+				t.Fatalf("Status() returned error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Status() returned incorrect entries (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRelativePathsWithRealRepo(t *testing.T) {
+	for _, relativePaths := range []string{"true", "false"} {
+		t.Run("status.relativePaths="+relativePaths, func(t *testing.T) {
+			tr := gitrepotest.NewWithCommit(t)
+			tr.Git("config", "status.relativePaths", relativePaths)
+			tr.WriteFile("sub/dir/a.txt", "a\n")
+			tr.WriteFile("sub/b.txt", "b\n")
+			tr.WriteFile("c.txt", "c\n")
+
+			repo := gitrepo.New(tr.SubdirRunner("sub"))
+			entries, err := repo.Status()
+			if err != nil {
+				t.Fatalf("Status() returned error: %v", err)
+			}
+			wantEntries := []*gitrepo.StatusEntry{
+				{Type: gitrepo.Untracked, Path: "c.txt"},
+				{Type: gitrepo.Untracked, Path: "sub/"},
+			}
+			if diff := cmp.Diff(wantEntries, entries); diff != "" {
+				t.Errorf("Status() returned incorrect entries (-want, +got):\n%s", diff)
+			}
+
+			got, err := repo.RelativePaths([]string{"c.txt", "sub/", "sub/dir/a.txt"})
+			if err != nil {
+				t.Fatalf("RelativePaths() returned error: %v", err)
+			}
+			if diff := cmp.Diff([]string{"../c.txt", "./", "dir/a.txt"}, got); diff != "" {
+				t.Errorf("RelativePaths() returned incorrect paths (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTrackedFilesWithRealRepo(t *testing.T) {
+	tr := gitrepotest.NewWithCommit(t)
+	tr.WriteFile("sub/a.txt", "a\n")
+	tr.WriteFile("other/b c.txt", "b\n")
+	tr.Commit("Add files")
+	tr.WriteFile("sub/untracked.txt", "new\n")
+
+	got, err := gitrepo.New(tr.SubdirRunner("sub")).TrackedFiles()
+	if err != nil {
+		t.Fatalf("TrackedFiles() returned error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"README.md", "other/b c.txt", "sub/a.txt"}, got); diff != "" {
+		t.Errorf("TrackedFiles() returned incorrect files (-want, +got):\n%s", diff)
+	}
+}
+
+func TestIgnoreRulesWithRealRepo(t *testing.T) {
+	tr := gitrepotest.NewWithCommit(t)
+	tr.WriteFile(".gitignore", "*.log\n")
+	tr.WriteFile("sub/.gitignore", "# Build output\nbuild/\n")
+	tr.Commit("Add ignore files")
+	tr.WriteFile(".git/info/exclude", "secret\n")
+	tr.WriteFile("a.log", "a\n")
+	tr.WriteFile("sub/b.log", "b\n")
+	tr.WriteFile("sub/build/out", "out\n")
+	tr.WriteFile("secret", "shh\n")
+	tr.WriteFile("sub/untracked.txt", "new\n")
+
+	repo := gitrepo.New(tr.SubdirRunner("sub"))
+	files, err := repo.IgnoredFiles()
+	if err != nil {
+		t.Fatalf("IgnoredFiles() returned error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a.log", "secret", "sub/b.log", "sub/build/"}, files); diff != "" {
+		t.Errorf("IgnoredFiles() returned incorrect files (-want, +got):\n%s", diff)
+	}
+
+	rules, err := repo.IgnoreRules("../a.log", "../secret", "b.log", "build/", "untracked.txt")
+	if err != nil {
+		t.Fatalf("IgnoreRules() returned error: %v", err)
+	}
+	want := []*gitrepo.IgnoreRule{
+		{Path: "../a.log", Source: ".gitignore", Line: 1, Pattern: "*.log"},
+		{Path: "../secret", Source: ".git/info/exclude", Line: 1, Pattern: "secret"},
+		{Path: "b.log", Source: ".gitignore", Line: 1, Pattern: "*.log"},
+		{Path: "build/", Source: "sub/.gitignore", Line: 2, Pattern: "build/"},
+	}
+	if diff := cmp.Diff(want, rules); diff != "" {
+		t.Errorf("IgnoreRules() returned incorrect rules (-want, +got):\n%s", diff)
+	}
+
+	exclude, err := repo.GitPath("info/exclude")
+	if err != nil {
+		t.Fatalf("GitPath() returned error: %v", err)
+	}
+	if diff := cmp.Diff(tr.Path(".git/info/exclude"), exclude); diff != "" {
+		t.Errorf("GitPath() returned incorrect path (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSubmodulesWithRealRepo(t *testing.T) {
+	tr := gitrepotest.NewWithCommit(t)
+	tr.AddSubmodule("libs/changed")
+	tr.AddSubmodule("libs/moved")
+	tr.AddSubmodule("libs/uninitialized")
+
+	tr.WriteFile("libs/changed/README.md", "changed\n")
+	tr.Git("-C", tr.Path("libs/moved"), "commit", "--allow-empty", "-m", "New commit")
+	tr.Git("submodule", "deinit", "libs/uninitialized")
+
+	subs, err := tr.Repo().Submodules()
+	if err != nil {
+		t.Fatalf("Submodules() returned error: %v", err)
+	}
+	var got []*gitrepo.Submodule
+	for _, s := range subs {
+		got = append(got, &gitrepo.Submodule{Path: s.Path, State: s.State})
+	}
+	want := []*gitrepo.Submodule{
+		{Path: "libs/changed", State: gitrepo.SubmoduleUpToDate},
+		{Path: "libs/moved", State: gitrepo.SubmoduleOutOfSync},
+		{Path: "libs/uninitialized", State: gitrepo.SubmoduleUninitialized},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Submodules() returned incorrect submodules (-want, +got):\n%s", diff)
+	}
+
+	wantStatus := []*gitrepo.StatusEntry{
+		{Type: gitrepo.Changed, XY: ".M", Submodule: "S.M.", Path: "libs/changed"},
+		{Type: gitrepo.Changed, XY: ".M", Submodule: "SC..", Path: "libs/moved"},
+	}
+	if diff := cmp.Diff(wantStatus, tr.Status()); diff != "" {
+		t.Errorf("Status() returned incorrect entries (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBranchesWithRealRepo(t *testing.T) {
+	tr := gitrepotest.NewWithCommit(t)
+	tr.Git("branch", "feature")
+	tr.Git("checkout", "-b", "other")
+
+	got, err := tr.Repo().Branches()
+	if err != nil {
+		t.Fatalf("Branches() returned error: %v", err)
+	}
+	want := []*gitrepo.Branch{
+		{Name: "feature"},
+		{Name: "main"},
+		{Name: "other", Current: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Branches() returned incorrect branches (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBranchInfosWithRealRepo(t *testing.T) {
+	tr := gitrepotest.NewWithCommit(t)
+	tr.AddRemote("origin")
+	tr.Git("push", "-u", "origin", "main")
+
+	// gone is pushed and then deleted from the remote.
+	tr.Git("checkout", "-b", "gone")
+	tr.Git("push", "-u", "origin", "gone")
+	tr.Git("push", "origin", "--delete", "gone")
+
+	tr.Git("checkout", "-b", "feature", "main")
+	tr.WriteFile("feature.txt", "feature\n")
+	tr.Commit("Feature change")
+	tr.Git("push", "-u", "origin", "feature")
+	tr.WriteFile("feature.txt", "more\n")
+	tr.Commit("Another feature change")
+
+	tr.Git("checkout", "main")
+	tr.WriteFile("main.txt", "main\n")
+	tr.Commit("Main change")
+
+	got, err := tr.Repo().BranchInfos("main")
+	if err != nil {
+		t.Fatalf("BranchInfos() returned error: %v", err)
+	}
+	want := []*gitrepo.BranchInfo{
+		{Name: "feature", Author: "Test User", Upstream: "origin/feature", Ahead: 1, BaseAhead: 2, BaseBehind: 1},
+		{Name: "gone", Author: "Test User", Upstream: "origin/gone", UpstreamGone: true, BaseBehind: 1, Merged: true},
+		{Name: "main", Current: true, Author: "Test User", Upstream: "origin/main", Ahead: 1, Merged: true},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(gitrepo.BranchInfo{}, "LastCommit")); diff != "" {
+		t.Errorf("BranchInfos() returned incorrect branches (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDefaultBranchWithRealRepo(t *testing.T) {
+	tr := gitrepotest.NewWithCommit(t)
+	tr.Git("checkout", "-b", "trunk")
+	tr.AddRemote("origin")
+	tr.Git("push", "origin", "trunk")
+	tr.Git("remote", "set-head", "origin", "trunk")
+
+	got, err := tr.Repo().DefaultBranch("origin")
+	if err != nil {
+		t.Fatalf("DefaultBranch() returned error: %v", err)
+	}
+	if got != "trunk" {
+		t.Errorf("DefaultBranch() returned %q; want %q", got, "trunk")
+	}
+}
+
+func TestApplyPatchWithRealRepo(t *testing.T) {
+	tr := gitrepotest.NewWithCommit(t)
+	tr.WriteFile("sub/file.txt", "a\nb\nc\nd\ne\nf\ng\n")
+	tr.Commit("Add file")
+	tr.WriteFile("sub/file.txt", "A\nb\nc\nd\ne\nf\nG\n")
+	tr.WriteFile("other.txt", "other\n")
+	tr.Git("add", "other.txt")
+
+	// Run from a subdirectory to verify paths outside of it are still patched.
+	repo := gitrepo.New(&gitrepo.ExecRunner{Dir: tr.Path("sub")})
+	diffs, err := repo.FileDiffs(false)
+	if err != nil {
+		t.Fatalf("FileDiffs() returned error: %v", err)
+	}
+	if len(diffs) != 1 || len(diffs[0].Hunks) != 1 {
+		t.Fatalf("FileDiffs() returned unexpected diffs: %v", diffs)
+	}
+	fd := diffs[0]
+	if fd.Path != "sub/file.txt" {
+		t.Errorf("FileDiffs() returned diff for %q; want %q", fd.Path, "sub/file.txt")
+	}
+
+	split := fd.Hunks[0].Split()
+	if len(split) != 2 {
+		t.Fatalf("Hunk.Split() returned %d hunks; want 2", len(split))
+	}
+
+	// Stage only the second change.
+	if err := repo.ApplyPatch(fd.Patch(split[1:]), &gitrepo.ApplyOptions{Cached: true}); err != nil {
+		t.Fatalf("ApplyPatch() returned error: %v", err)
+	}
+	if diff := cmp.Diff("a\nb\nc\nd\ne\nf\nG", strings.Join(tr.Git("show", ":sub/file.txt"), "\n")); diff != "" {
+		t.Errorf("ApplyPatch() staged incorrect contents (-want, +got):\n%s", diff)
+	}
+
+	// Discard the first change from the working tree.
+	if err := repo.ApplyPatch(fd.Patch(split[:1]), &gitrepo.ApplyOptions{Reverse: true}); err != nil {
+		t.Fatalf("ApplyPatch() returned error: %v", err)
+	}
+	if diff := cmp.Diff("a\nb\nc\nd\ne\nf\nG\n", tr.ReadFile("sub/file.txt")); diff != "" {
+		t.Errorf("ApplyPatch() left incorrect file contents (-want, +got):\n%s", diff)
+	}
+	wantStatus := []*gitrepo.StatusEntry{
+		{Type: gitrepo.Changed, XY: "A.", Submodule: "N...", Path: "other.txt"},
+		{Type: gitrepo.Changed, XY: "M.", Submodule: "N...", Path: "sub/file.txt"},
+	}
+	if diff := cmp.Diff(wantStatus, tr.Status()); diff != "" {
+		t.Errorf("ApplyPatch() produced incorrect status (-want, +got):\n%s", diff)
+	}
+}
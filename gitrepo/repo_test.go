@@ -0,0 +1,561 @@
+package gitrepo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandtest"
+)
+
+// fakeRunner is a `Runner` that returns canned responses and records the
+// commands it was asked to run.
+type fakeRunner struct {
+	responses []*fakeResponse
+	got       [][]string
+}
+
+type fakeResponse struct {
+	stdout []string
+	err    error
+}
+
+func (fr *fakeRunner) Run(args ...string) ([]string, error) {
+	fr.got = append(fr.got, args)
+	if len(fr.responses) == 0 {
+		return nil, fmt.Errorf("unexpected command: %v", args)
+	}
+	r := fr.responses[0]
+	fr.responses = fr.responses[1:]
+	return r.stdout, r.err
+}
+
+func TestCommandString(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		c              *Command
+		want           string
+		wantPowerShell string
+	}{
+		{
+			name:           "no args",
+			c:              NewCommand(),
+			want:           "git",
+			wantPowerShell: "git",
+		},
+		{
+			name:           "simple args",
+			c:              NewCommand("diff", "-w", "HEAD~1", "--", "some/file.go"),
+			want:           "git diff -w HEAD~1 -- some/file.go",
+			wantPowerShell: "git diff -w HEAD~1 -- some/file.go",
+		},
+		{
+			name:           "quotes args with spaces",
+			c:              NewCommand("add", "my file.txt"),
+			want:           `git add "my file.txt"`,
+			wantPowerShell: `git add 'my file.txt'`,
+		},
+		{
+			name:           "quotes args with special characters",
+			c:              NewCommand("commit", "-m", `say "hi" and 'bye'`),
+			want:           `git commit -m "say \"hi\" and 'bye'"`,
+			wantPowerShell: `git commit -m 'say "hi" and ''bye'''`,
+		},
+		{
+			name:           "escapes shell expansions",
+			c:              NewCommand("commit", "-m", "cost: $5 `x`"),
+			want:           "git commit -m \"cost: \\$5 \\`x\\`\"",
+			wantPowerShell: "git commit -m 'cost: $5 `x`'",
+		},
+		{
+			name:           "quotes commas in PowerShell",
+			c:              NewCommand("blame", "-L", "1,2"),
+			want:           "git blame -L 1,2",
+			wantPowerShell: "git blame -L '1,2'",
+		},
+		{
+			name:           "quotes empty args",
+			c:              NewCommand("commit", "-m", ""),
+			want:           `git commit -m ""`,
+			wantPowerShell: `git commit -m ''`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, test.c.String()); diff != "" {
+				t.Errorf("Command.String() returned incorrect string (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.want, test.c.StringFor(Bash)); diff != "" {
+				t.Errorf("Command.StringFor(Bash) returned incorrect string (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantPowerShell, test.c.StringFor(PowerShell)); diff != "" {
+				t.Errorf("Command.StringFor(PowerShell) returned incorrect string (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want []string
+	}{
+		{},
+		{
+			s: "\n",
+		},
+		{
+			s:    "one",
+			want: []string{"one"},
+		},
+		{
+			s:    "one\ntwo\n",
+			want: []string{"one", "two"},
+		},
+	} {
+		t.Run(test.s, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, SplitLines(test.s)); diff != "" {
+				t.Errorf("SplitLines(%q) returned incorrect lines (-want, +got):\n%s", test.s, diff)
+			}
+		})
+	}
+}
+
+func TestRepoOperations(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		f         func(*Repo) (interface{}, error)
+		responses []*fakeResponse
+		want      interface{}
+		wantErr   error
+		wantRuns  [][]string
+	}{
+		{
+			name: "RemoteURL",
+			f: func(r *Repo) (interface{}, error) {
+				return r.RemoteURL("origin")
+			},
+			responses: []*fakeResponse{{stdout: []string{"git@github.com:some/repo.git"}}},
+			want:      "git@github.com:some/repo.git",
+			wantRuns:  [][]string{{"config", "--get", "remote.origin.url"}},
+		},
+		{
+			name: "RemoteURL fails",
+			f: func(r *Repo) (interface{}, error) {
+				return r.RemoteURL("upstream")
+			},
+			responses: []*fakeResponse{{err: fmt.Errorf("oops")}},
+			want:      "",
+			wantErr:   fmt.Errorf("oops"),
+			wantRuns:  [][]string{{"config", "--get", "remote.upstream.url"}},
+		},
+		{
+			name: "CurrentBranch",
+			f: func(r *Repo) (interface{}, error) {
+				return r.CurrentBranch()
+			},
+			responses: []*fakeResponse{{stdout: []string{"my-branch  "}}},
+			want:      "my-branch",
+			wantRuns:  [][]string{{"rev-parse", "--abbrev-ref", "HEAD"}},
+		},
+		{
+			name: "DefaultBranch",
+			f: func(r *Repo) (interface{}, error) {
+				return r.DefaultBranch("origin")
+			},
+			responses: []*fakeResponse{{stdout: []string{"origin/trunk"}}},
+			want:      "trunk",
+			wantRuns:  [][]string{{"symbolic-ref", "--short", "refs/remotes/origin/HEAD"}},
+		},
+		{
+			name: "DiffFiles",
+			f: func(r *Repo) (interface{}, error) {
+				return r.DiffFiles(false)
+			},
+			responses: []*fakeResponse{{stdout: []string{"a.go", "", "b/c.go"}}},
+			want:      []string{"a.go", "b/c.go"},
+			wantRuns:  [][]string{{"diff", "--name-only"}},
+		},
+		{
+			name: "DiffFiles cached",
+			f: func(r *Repo) (interface{}, error) {
+				return r.DiffFiles(true)
+			},
+			responses: []*fakeResponse{{stdout: []string{"a.go"}}},
+			want:      []string{"a.go"},
+			wantRuns:  [][]string{{"diff", "--cached", "--name-only"}},
+		},
+		{
+			name: "Prefix",
+			f: func(r *Repo) (interface{}, error) {
+				return r.Prefix()
+			},
+			responses: []*fakeResponse{{stdout: []string{"a/b/"}}},
+			want:      "a/b/",
+			wantRuns:  [][]string{{"rev-parse", "--show-prefix"}},
+		},
+		{
+			name: "RelativePaths at the root of the repo",
+			f: func(r *Repo) (interface{}, error) {
+				return r.RelativePaths([]string{"a.go", "b/c.go"})
+			},
+			responses: []*fakeResponse{{stdout: []string{""}}},
+			want:      []string{"a.go", "b/c.go"},
+			wantRuns:  [][]string{{"rev-parse", "--show-prefix"}},
+		},
+		{
+			name: "RelativePaths in a subdirectory",
+			f: func(r *Repo) (interface{}, error) {
+				return r.RelativePaths([]string{"a.go", "b/c.go", "b/d/", "b/e/f.go", "bb/g.go"})
+			},
+			responses: []*fakeResponse{{stdout: []string{"b/"}}},
+			want:      []string{"../a.go", "c.go", "d/", "e/f.go", "../bb/g.go"},
+			wantRuns:  [][]string{{"rev-parse", "--show-prefix"}},
+		},
+		{
+			name: "RelativePaths fails if the prefix can't be found",
+			f: func(r *Repo) (interface{}, error) {
+				return r.RelativePaths([]string{"a.go"})
+			},
+			responses: []*fakeResponse{{err: fmt.Errorf("not a git repo")}},
+			want:      ([]string)(nil),
+			wantErr:   fmt.Errorf("not a git repo"),
+			wantRuns:  [][]string{{"rev-parse", "--show-prefix"}},
+		},
+		{
+			name: "Branches",
+			f: func(r *Repo) (interface{}, error) {
+				return r.Branches()
+			},
+			responses: []*fakeResponse{{stdout: []string{
+				"  main",
+				"* my-branch",
+				"+ other-worktree",
+				"",
+			}}},
+			want: []*Branch{
+				{Name: "main"},
+				{Name: "my-branch", Current: true},
+				{Name: "other-worktree"},
+			},
+			wantRuns: [][]string{{"branch", "--list"}},
+		},
+		{
+			name: "Upstream",
+			f: func(r *Repo) (interface{}, error) {
+				return r.Upstream("feature")
+			},
+			responses: []*fakeResponse{{stdout: []string{"origin/feature"}}},
+			want:      "origin/feature",
+			wantRuns:  [][]string{{"rev-parse", "--abbrev-ref", "--symbolic-full-name", "feature@{upstream}"}},
+		},
+		{
+			name: "HasUpstream",
+			f: func(r *Repo) (interface{}, error) {
+				return r.HasUpstream("feature"), nil
+			},
+			responses: []*fakeResponse{{err: fmt.Errorf("no upstream configured")}},
+			want:      false,
+			wantRuns:  [][]string{{"rev-parse", "--abbrev-ref", "--symbolic-full-name", "feature@{upstream}"}},
+		},
+		{
+			name: "AheadBehind",
+			f: func(r *Repo) (interface{}, error) {
+				ahead, behind, err := r.AheadBehind("feature", "origin/feature")
+				return []int{ahead, behind}, err
+			},
+			responses: []*fakeResponse{{stdout: []string{"2\t5"}}},
+			want:      []int{2, 5},
+			wantRuns:  [][]string{{"rev-list", "--left-right", "--count", "feature...origin/feature"}},
+		},
+		{
+			name: "AheadBehind fails on unexpected output",
+			f: func(r *Repo) (interface{}, error) {
+				ahead, behind, err := r.AheadBehind("feature", "origin/feature")
+				return []int{ahead, behind}, err
+			},
+			responses: []*fakeResponse{{stdout: []string{"2"}}},
+			want:      []int{0, 0},
+			wantErr:   fmt.Errorf(`unexpected rev-list output: "2"`),
+			wantRuns:  [][]string{{"rev-list", "--left-right", "--count", "feature...origin/feature"}},
+		},
+		{
+			name: "RevParse",
+			f: func(r *Repo) (interface{}, error) {
+				return r.RevParse("origin/feature")
+			},
+			responses: []*fakeResponse{{stdout: []string{"abc123"}}},
+			want:      "abc123",
+			wantRuns:  [][]string{{"rev-parse", "--verify", "origin/feature"}},
+		},
+		{
+			name: "Commits",
+			f: func(r *Repo) (interface{}, error) {
+				return r.Commits("feature", "origin/feature")
+			},
+			responses: []*fakeResponse{{stdout: []string{"abc123 Second", "def456 First", ""}}},
+			want:      []string{"abc123 Second", "def456 First"},
+			wantRuns:  [][]string{{"log", "--format=%h %s", "feature..origin/feature"}},
+		},
+		{
+			name: "FastForward",
+			f: func(r *Repo) (interface{}, error) {
+				return nil, r.FastForward("main", "origin/main")
+			},
+			responses: []*fakeResponse{{}},
+			wantRuns:  [][]string{{"fetch", ".", "origin/main:main"}},
+		},
+		{
+			name: "BranchInfos",
+			f: func(r *Repo) (interface{}, error) {
+				return r.BranchInfos("main")
+			},
+			responses: []*fakeResponse{
+				{stdout: []string{
+					"feature\x00*\x00200\x00Some One\x00origin/feature\x00ahead 1, behind 2",
+					"main\x00 \x00100\x00Other One\x00origin/main\x00",
+					"old\x00 \x0050\x00Some One\x00origin/old\x00gone",
+					"",
+				}},
+				// RefExists
+				{stdout: []string{"abc123"}},
+				// Merged branches
+				{stdout: []string{"main", "old"}},
+				{stdout: []string{"3\t0"}},
+				{stdout: []string{"0\t0"}},
+				{stdout: []string{"0\t4"}},
+			},
+			want: []*BranchInfo{
+				{Name: "feature", Current: true, LastCommit: time.Unix(200, 0), Author: "Some One", Upstream: "origin/feature", Ahead: 1, Behind: 2, BaseAhead: 3},
+				{Name: "main", LastCommit: time.Unix(100, 0), Author: "Other One", Upstream: "origin/main", Merged: true},
+				{Name: "old", LastCommit: time.Unix(50, 0), Author: "Some One", Upstream: "origin/old", UpstreamGone: true, BaseBehind: 4, Merged: true},
+			},
+			wantRuns: [][]string{
+				{"for-each-ref", "--format=" + branchInfoFormat, "refs/heads"},
+				{"rev-parse", "--verify", "--quiet", "main"},
+				{"for-each-ref", "--format=%(refname:short)", "--merged=main", "refs/heads"},
+				{"rev-list", "--left-right", "--count", "feature...main"},
+				{"rev-list", "--left-right", "--count", "main...main"},
+				{"rev-list", "--left-right", "--count", "old...main"},
+			},
+		},
+		{
+			name: "BranchInfos skips base comparisons if the base doesn't exist",
+			f: func(r *Repo) (interface{}, error) {
+				return r.BranchInfos("main")
+			},
+			responses: []*fakeResponse{
+				{stdout: []string{"feature\x00*\x00200\x00Some One\x00\x00"}},
+				{err: fmt.Errorf("oops")},
+			},
+			want: []*BranchInfo{
+				{Name: "feature", Current: true, LastCommit: time.Unix(200, 0), Author: "Some One"},
+			},
+			wantRuns: [][]string{
+				{"for-each-ref", "--format=" + branchInfoFormat, "refs/heads"},
+				{"rev-parse", "--verify", "--quiet", "main"},
+			},
+		},
+		{
+			name: "RecentBranches",
+			f: func(r *Repo) (interface{}, error) {
+				return r.RecentBranches()
+			},
+			responses: []*fakeResponse{{stdout: []string{
+				"checkout: moving from feature to main",
+				"commit: Some change",
+				"checkout: moving from main to feature",
+				"checkout: moving from abc123 to main",
+				"rebase (finish): returning to refs/heads/fix",
+				"checkout: moving from fix to abc123",
+				"",
+			}}},
+			want:     []string{"main", "feature", "abc123", "fix"},
+			wantRuns: [][]string{{"reflog", "--format=%gs"}},
+		},
+		{
+			name: "Tags",
+			f: func(r *Repo) (interface{}, error) {
+				return r.Tags()
+			},
+			responses: []*fakeResponse{{stdout: []string{"v1.0", "v1.1", ""}}},
+			want:      []string{"v1.0", "v1.1"},
+			wantRuns:  [][]string{{"tag", "--list"}},
+		},
+		{
+			name: "RecentCommits",
+			f: func(r *Repo) (interface{}, error) {
+				return r.RecentCommits(3)
+			},
+			responses: []*fakeResponse{{stdout: []string{"abc1234", "def5678", "0123456", ""}}},
+			want:      []string{"abc1234", "def5678", "0123456"},
+			wantRuns:  [][]string{{"log", "--format=%h", "-n3"}},
+		},
+		{
+			name: "MergeBase",
+			f: func(r *Repo) (interface{}, error) {
+				return r.MergeBase("feature", "main")
+			},
+			responses: []*fakeResponse{{stdout: []string{"abc123"}}},
+			want:      "abc123",
+			wantRuns:  [][]string{{"merge-base", "feature", "main"}},
+		},
+		{
+			name: "TrackedFiles",
+			f: func(r *Repo) (interface{}, error) {
+				return r.TrackedFiles()
+			},
+			responses: []*fakeResponse{{stdout: []string{"README.md", "sub/a.go", `"tab\there.go"`, ""}}},
+			want:      []string{"README.md", "sub/a.go", "tab\there.go"},
+			wantRuns:  [][]string{{"ls-files", "--full-name", "--", ":/"}},
+		},
+		{
+			name: "IgnoredFiles",
+			f: func(r *Repo) (interface{}, error) {
+				return r.IgnoredFiles()
+			},
+			responses: []*fakeResponse{{stdout: []string{
+				"? untracked.txt",
+				"! build/",
+				`! "tab\there.log"`,
+				"! sub/debug.log",
+				"",
+			}}},
+			want:     []string{"build/", "tab\there.log", "sub/debug.log"},
+			wantRuns: [][]string{{"-c", "status.relativePaths=false", "status", "--porcelain=v2", "--ignored"}},
+		},
+		{
+			name: "IgnoreRules with no paths",
+			f: func(r *Repo) (interface{}, error) {
+				return r.IgnoreRules()
+			},
+			want: ([]*IgnoreRule)(nil),
+		},
+		{
+			name: "IgnoreRules",
+			f: func(r *Repo) (interface{}, error) {
+				return r.IgnoreRules("../a.log", "build/", "secret", "nope", "win.log")
+			},
+			responses: []*fakeResponse{{stdout: []string{
+				".gitignore:1:*.log\t../a.log",
+				"sub/.gitignore:3:build/\tbuild/",
+				".git/info/exclude:7:sec:ret*\tsecret",
+				"::\tnope",
+				`C:\Users\me\.gitignore:12:win:*.log` + "\twin.log",
+				"",
+			}}},
+			want: []*IgnoreRule{
+				{Path: "../a.log", Source: ".gitignore", Line: 1, Pattern: "*.log"},
+				{Path: "build/", Source: "sub/.gitignore", Line: 3, Pattern: "build/"},
+				{Path: "secret", Source: ".git/info/exclude", Line: 7, Pattern: "sec:ret*"},
+				{Path: "win.log", Source: `C:\Users\me\.gitignore`, Line: 12, Pattern: "win:*.log"},
+			},
+			wantRuns: [][]string{{"check-ignore", "--verbose", "--non-matching", "--", "../a.log", "build/", "secret", "nope", "win.log"}},
+		},
+		{
+			name: "IgnoreRules fails on unexpected output",
+			f: func(r *Repo) (interface{}, error) {
+				return r.IgnoreRules("a.log")
+			},
+			responses: []*fakeResponse{{stdout: []string{".gitignore:one:*.log\ta.log"}}},
+			want:      ([]*IgnoreRule)(nil),
+			wantErr:   fmt.Errorf(`malformed check-ignore output: ".gitignore:one:*.log\ta.log"`),
+			wantRuns:  [][]string{{"check-ignore", "--verbose", "--non-matching", "--", "a.log"}},
+		},
+		{
+			name: "GitPath",
+			f: func(r *Repo) (interface{}, error) {
+				return r.GitPath("info/exclude")
+			},
+			responses: []*fakeResponse{{stdout: []string{"/repo/.git/info/exclude"}}},
+			want:      "/repo/.git/info/exclude",
+			wantRuns:  [][]string{{"rev-parse", "--path-format=absolute", "--git-path", "info/exclude"}},
+		},
+		{
+			name: "BranchInfos fails on unexpected output",
+			f: func(r *Repo) (interface{}, error) {
+				return r.BranchInfos("main")
+			},
+			responses: []*fakeResponse{{stdout: []string{"feature"}}},
+			want:      ([]*BranchInfo)(nil),
+			wantErr:   fmt.Errorf(`unexpected for-each-ref output: "feature"`),
+			wantRuns:  [][]string{{"for-each-ref", "--format=" + branchInfoFormat, "refs/heads"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fr := &fakeRunner{responses: test.responses}
+			got, err := test.f(New(fr))
+			commandtest.CmpError(t, test.name, test.wantErr, err)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("%s returned incorrect value (-want, +got):\n%s", test.name, diff)
+			}
+			if diff := cmp.Diff(test.wantRuns, fr.got); diff != "" {
+				t.Errorf("%s ran incorrect commands (-want, +got):\n%s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		c    *Command
+		want string
+	}{
+		{"checkout", Checkout("main", false), "git checkout main"},
+		{"checkout new branch", Checkout("feature", true), "git checkout -b feature"},
+		{"delete branch", DeleteBranch("old", false), "git branch -d old"},
+		{"force delete branch", DeleteBranch("old", true), "git branch -D old"},
+		{"rename branch", RenameBranch("old", "new"), "git branch -m old new"},
+		{"merge", Merge("main"), "git merge main"},
+		{"commit", Commit(&CommitOptions{Message: "hello there"}), `git commit -m "hello there"`},
+		{"commit with options", Commit(&CommitOptions{
+			Message:  "hi",
+			NoVerify: true,
+			Trailers: []string{CoAuthorTrailer("Some One <some@one.com>")},
+		}), `git commit --no-verify -m hi --trailer "Co-authored-by: Some One <some@one.com>"`},
+		{"amend", Commit(&CommitOptions{Amend: true, NoEdit: true}), "git commit --amend --no-edit"},
+		{"undo commit", UndoCommit(), "git reset HEAD~"},
+		{"push", Push(nil), "git push"},
+		{"push upstream", Push(&PushOptions{SetUpstream: true, Remote: "origin", Branch: "b"}), "git push --set-upstream origin b"},
+		{"force push", Push(&PushOptions{ForceWithLease: true}), "git push --force-with-lease"},
+		{"force push with pinned lease", Push(&PushOptions{ForceWithLease: true, LeaseRef: "b", LeaseSHA: "abc123"}), "git push --force-with-lease=b:abc123"},
+		{"delete remote branch", DeleteRemoteBranch("origin", "b"), "git push origin --delete b"},
+		{"fetch remote", FetchRemote("origin"), "git fetch origin"},
+		{"rebase", Rebase("main"), "git rebase main"},
+		{"pull", Pull(nil), "git pull"},
+		{"pull rebase", Pull(&PullOptions{Rebase: true}), "git pull --rebase"},
+		{"pull fast-forward only", Pull(&PullOptions{FFOnly: true}), "git pull --ff-only"},
+		{"submodule update", SubmoduleUpdate(), "git submodule update --init --recursive"},
+		{"submodule update paths", SubmoduleUpdate("a", "b c"), `git submodule update --init --recursive -- a "b c"`},
+		{"submodule sync", SubmoduleSync(), "git submodule sync --recursive"},
+		{"submodule sync paths", SubmoduleSync("a"), "git submodule sync --recursive -- a"},
+		{"submodule pull", SubmodulePull(), "git submodule update --remote --recursive"},
+		{"add all", Add(), "git add ."},
+		{"add files", Add("a.go", "b c.go"), `git add a.go "b c.go"`},
+		{"discard", Discard("a.go"), "git checkout -- a.go"},
+		{"unstage", Unstage("a.go"), "git reset -- a.go"},
+		{"diff", Diff(&DiffOptions{}), "git diff --"},
+		{"diff with options", Diff(&DiffOptions{IgnoreWhitespace: true, Base: "main", Paths: []string{"a.go"}}), "git diff -w main -- a.go"},
+		{"log", Log(3), "git log -n 3"},
+		{"blame", Blame(&BlameOptions{Path: "a.go"}), "git blame --date=relative -- a.go"},
+		{"blame from line", Blame(&BlameOptions{Path: "a.go", Start: 10}), "git blame --date=relative -L 10, -- a.go"},
+		{"blame with options", Blame(&BlameOptions{Path: "b c.go", Start: 3, End: 7, IgnoreWhitespace: true, IgnoreRevsFile: "/repo/.git-blame-ignore-revs"}), `git blame --date=relative -w --ignore-revs-file /repo/.git-blame-ignore-revs -L 3,7 -- "b c.go"`},
+		{"bisect start", BisectStart("HEAD", "v1.0"), "git bisect start HEAD v1.0"},
+		{"bisect good", BisectMark("good"), "git bisect good"},
+		{"bisect skip commits", BisectMark("skip", "abc123", "def456"), "git bisect skip abc123 def456"},
+		{"bisect run", BisectRun("go", "test", "./..."), "git bisect run go test ./..."},
+		{"bisect reset", BisectReset(), "git bisect reset"},
+		{"grep", Grep(&GrepOptions{Pattern: "TODO"}), "git grep -n -I -e TODO --"},
+		{"grep with options", Grep(&GrepOptions{Pattern: "-v$", Paths: []string{"a.go", "sub"}, Ref: "v1.0", IgnoreCase: true, WordRegexp: true, ExtendedRegexp: true}), `git grep -n -I -i -w -E -e "-v\$" v1.0 -- a.go sub`},
+		{"grep fixed strings", Grep(&GrepOptions{Pattern: "a.b(", FixedStrings: true}), `git grep -n -I -F -e "a.b(" --`},
+		{"stash push", StashPush("abc"), "git stash push abc"},
+		{"stash pop", StashPop(), "git stash pop"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, test.c.String()); diff != "" {
+				t.Errorf("%s command is incorrect (-want, +got):\n%s", test.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package gitrepo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EntryType is the type of a `StatusEntry`.
+type EntryType string
+
+const (
+	// Changed entries are ordinary tracked files with changes.
+	Changed EntryType = "1"
+	// Renamed entries are tracked files that were renamed or copied.
+	Renamed EntryType = "2"
+	// Unmerged entries are files with merge conflicts.
+	Unmerged EntryType = "u"
+	// Untracked entries are files that aren't tracked by git.
+	Untracked EntryType = "?"
+	// Ignored entries are files ignored by git.
+	Ignored EntryType = "!"
+)
+
+// StatusEntry is a single file entry from `git status --porcelain=v2`.
+// See https://git-scm.com/docs/git-status#_porcelain_format_version_2
+type StatusEntry struct {
+	// Type is the type of the entry.
+	Type EntryType
+	// XY contains the staged (X) and unstaged (Y) status codes of the file
+	// (e.g. ".M"). It is empty for untracked and ignored files.
+	XY string
+	// Submodule is the submodule state of the file ("N..." if the file isn't a
+	// submodule). It is empty for untracked and ignored files.
+	Submodule string
+	// Path is the path of the file, relative to the root of the repo.
+	Path string
+	// OrigPath is the path the file was renamed or copied from.
+	OrigPath string
+}
+
+// Status returns the status of all changed, untracked, and unmerged files.
+func (r *Repo) Status() ([]*StatusEntry, error) {
+	// Paths are relative to the current directory if status.relativePaths is
+	// set (the default), so it is disabled to always get repo-relative paths.
+	out, err := r.Run(NewCommand("-c", "status.relativePaths=false", "status", "--porcelain=v2"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatus(out)
+}
+
+// ParseStatus parses the output of `git status --porcelain=v2`.
+func ParseStatus(lines []string) ([]*StatusEntry, error) {
+	var entries []*StatusEntry
+	for _, line := range lines {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		e, err := parseStatusLine(line)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func parseStatusLine(line string) (*StatusEntry, error) {
+	// The number of space-separated fields for each entry type. The path is
+	// always the last field, so it may contain spaces.
+	nFields := map[EntryType]int{
+		// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+		Changed: 9,
+		// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path><tab><origPath>
+		Renamed: 10,
+		// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+		Unmerged: 11,
+		// ? <path>
+		Untracked: 2,
+		// ! <path>
+		Ignored: 2,
+	}
+
+	t := EntryType(line[:1])
+	n, ok := nFields[t]
+	if !ok {
+		return nil, fmt.Errorf("unknown status entry type: %q", line)
+	}
+	parts := strings.SplitN(line, " ", n)
+	if len(parts) != n {
+		return nil, fmt.Errorf("malformed status entry: %q", line)
+	}
+
+	e := &StatusEntry{Type: t}
+	if n > 2 {
+		e.XY = parts[1]
+		e.Submodule = parts[2]
+	}
+
+	path := parts[n-1]
+	if t == Renamed {
+		paths := strings.SplitN(path, "\t", 2)
+		if len(paths) != 2 {
+			return nil, fmt.Errorf("malformed rename status entry: %q", line)
+		}
+		path = paths[0]
+		e.OrigPath = unquotePath(paths[1])
+	}
+	e.Path = unquotePath(path)
+	return e, nil
+}
+
+// IsSubmodule returns whether or not the entry is a submodule.
+func (e *StatusEntry) IsSubmodule() bool {
+	return strings.HasPrefix(e.Submodule, "S")
+}
+
+// SubmoduleCommitChanged returns whether or not the entry is a submodule with
+// a different commit checked out than the one recorded in the index.
+func (e *StatusEntry) SubmoduleCommitChanged() bool {
+	return e.IsSubmodule() && len(e.Submodule) == 4 && e.Submodule[1] == 'C'
+}
+
+// unquotePath removes the C-style quotes git adds to paths with unusual characters.
+func unquotePath(p string) string {
+	if !strings.HasPrefix(p, `"`) {
+		return p
+	}
+	if s, err := strconv.Unquote(p); err == nil {
+		return s
+	}
+	return p
+}
@@ -0,0 +1,96 @@
+package gitrepo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandtest"
+)
+
+func TestParseStatus(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		lines   []string
+		want    []*StatusEntry
+		wantErr error
+	}{
+		{
+			name: "handles empty status",
+		},
+		{
+			name: "ignores headers",
+			lines: []string{
+				"# branch.oid abc123",
+				"# branch.head main",
+				"",
+			},
+		},
+		{
+			name: "parses all entry types",
+			lines: []string{
+				"1 .M N... 100644 100644 100644 abc def some/file.go",
+				"1 A. N... 000000 100644 100644 000 def new.go",
+				"2 R. N... 100644 100644 100644 abc abc R100 to.go\tfrom.go",
+				"u UU N... 100644 100644 100644 100644 abc def ghi conflict.go",
+				"? untracked.txt",
+				"! ignored.log",
+			},
+			want: []*StatusEntry{
+				{Type: Changed, XY: ".M", Submodule: "N...", Path: "some/file.go"},
+				{Type: Changed, XY: "A.", Submodule: "N...", Path: "new.go"},
+				{Type: Renamed, XY: "R.", Submodule: "N...", Path: "to.go", OrigPath: "from.go"},
+				{Type: Unmerged, XY: "UU", Submodule: "N...", Path: "conflict.go"},
+				{Type: Untracked, Path: "untracked.txt"},
+				{Type: Ignored, Path: "ignored.log"},
+			},
+		},
+		{
+			name: "handles paths with spaces",
+			lines: []string{
+				"1 .M N... 100644 100644 100644 abc def some dir/my file.go",
+				"2 R. N... 100644 100644 100644 abc abc R90 new name.go\told name.go",
+				"? un tracked.txt",
+			},
+			want: []*StatusEntry{
+				{Type: Changed, XY: ".M", Submodule: "N...", Path: "some dir/my file.go"},
+				{Type: Renamed, XY: "R.", Submodule: "N...", Path: "new name.go", OrigPath: "old name.go"},
+				{Type: Untracked, Path: "un tracked.txt"},
+			},
+		},
+		{
+			name: "unquotes paths",
+			lines: []string{
+				`1 .M N... 100644 100644 100644 abc def "tab\there.go"`,
+				`? "quote\"d.txt"`,
+			},
+			want: []*StatusEntry{
+				{Type: Changed, XY: ".M", Submodule: "N...", Path: "tab\there.go"},
+				{Type: Untracked, Path: `quote"d.txt`},
+			},
+		},
+		{
+			name:    "fails on unknown entry type",
+			lines:   []string{"x what"},
+			wantErr: fmt.Errorf(`unknown status entry type: "x what"`),
+		},
+		{
+			name:    "fails on malformed entry",
+			lines:   []string{"1 .M N... file.go"},
+			wantErr: fmt.Errorf(`malformed status entry: "1 .M N... file.go"`),
+		},
+		{
+			name:    "fails on malformed rename entry",
+			lines:   []string{"2 R. N... 100644 100644 100644 abc abc R100 to.go"},
+			wantErr: fmt.Errorf(`malformed rename status entry: "2 R. N... 100644 100644 100644 abc abc R100 to.go"`),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseStatus(test.lines)
+			commandtest.CmpError(t, "ParseStatus()", test.wantErr, err)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ParseStatus() returned incorrect entries (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
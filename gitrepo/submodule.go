@@ -0,0 +1,114 @@
+package gitrepo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SubmoduleState is the state of a submodule's checked out commit.
+type SubmoduleState string
+
+const (
+	// SubmoduleUpToDate submodules have the commit recorded in the index checked out.
+	SubmoduleUpToDate SubmoduleState = " "
+	// SubmoduleUninitialized submodules haven't been initialized.
+	SubmoduleUninitialized SubmoduleState = "-"
+	// SubmoduleOutOfSync submodules have a different commit than the one
+	// recorded in the index checked out.
+	SubmoduleOutOfSync SubmoduleState = "+"
+	// SubmoduleConflict submodules have merge conflicts.
+	SubmoduleConflict SubmoduleState = "U"
+)
+
+var (
+	submoduleStateDescriptions = map[SubmoduleState]string{
+		SubmoduleUpToDate:      "up to date",
+		SubmoduleUninitialized: "not initialized",
+		SubmoduleOutOfSync:     "new commits",
+		SubmoduleConflict:      "merge conflicts",
+	}
+)
+
+// String returns a description of the state.
+func (ss SubmoduleState) String() string {
+	return submoduleStateDescriptions[ss]
+}
+
+// Submodule is a submodule of the repo.
+type Submodule struct {
+	// Path is the path of the submodule, relative to the current directory.
+	Path string
+	// SHA is the commit checked out in the submodule (or the commit recorded in
+	// the index if the submodule isn't initialized).
+	SHA string
+	// State is the state of the submodule's checked out commit.
+	State SubmoduleState
+}
+
+// Submodules returns the submodules (including nested ones) of the repo.
+func (r *Repo) Submodules() ([]*Submodule, error) {
+	out, err := r.Run(NewCommand("submodule", "status", "--recursive"))
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []*Submodule
+	for _, line := range out {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sub, err := parseSubmoduleLine(line)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func parseSubmoduleLine(line string) (*Submodule, error) {
+	// <state><SHA> <path>[ (<describe>)]
+	state := SubmoduleState(line[:1])
+	if _, ok := submoduleStateDescriptions[state]; !ok {
+		return nil, fmt.Errorf("unknown submodule state: %q", line)
+	}
+	sha, path, ok := strings.Cut(line[1:], " ")
+	if !ok || sha == "" || path == "" {
+		return nil, fmt.Errorf("malformed submodule status: %q", line)
+	}
+	if i := strings.LastIndex(path, " ("); i >= 0 && strings.HasSuffix(path, ")") {
+		path = path[:i]
+	}
+	return &Submodule{
+		Path:  path,
+		SHA:   sha,
+		State: state,
+	}, nil
+}
+
+// SubmoduleUpdate returns a command that initializes and updates the provided
+// submodules (or all submodules if none are provided), recursively.
+func SubmoduleUpdate(paths ...string) *Command {
+	return submoduleCommand([]string{"update", "--init", "--recursive"}, paths)
+}
+
+// SubmoduleSync returns a command that syncs the remote URLs of the provided
+// submodules (or all submodules if none are provided), recursively.
+func SubmoduleSync(paths ...string) *Command {
+	return submoduleCommand([]string{"sync", "--recursive"}, paths)
+}
+
+// SubmodulePull returns a command that updates every submodule (recursively)
+// to the latest commit of its remote branch. Unlike running `git pull` in each
+// submodule, this works for submodules with a detached HEAD.
+func SubmodulePull() *Command {
+	return NewCommand("submodule", "update", "--remote", "--recursive")
+}
+
+func submoduleCommand(args, paths []string) *Command {
+	args = append([]string{"submodule"}, args...)
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	return NewCommand(args...)
+}
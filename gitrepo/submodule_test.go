@@ -0,0 +1,104 @@
+package gitrepo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandtest"
+)
+
+func TestSubmodules(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		response *fakeResponse
+		want     []*Submodule
+		wantErr  error
+	}{
+		{
+			name:     "no submodules",
+			response: &fakeResponse{},
+		},
+		{
+			name: "parses submodule states",
+			response: &fakeResponse{stdout: []string{
+				" abc123 libs/up to date (v1.0.0)",
+				"-def456 libs/uninitialized",
+				"+ghi789 libs/new commits (heads/main)",
+				"Ujkl012 libs/conflict",
+				"",
+			}},
+			want: []*Submodule{
+				{Path: "libs/up to date", SHA: "abc123", State: SubmoduleUpToDate},
+				{Path: "libs/uninitialized", SHA: "def456", State: SubmoduleUninitialized},
+				{Path: "libs/new commits", SHA: "ghi789", State: SubmoduleOutOfSync},
+				{Path: "libs/conflict", SHA: "jkl012", State: SubmoduleConflict},
+			},
+		},
+		{
+			name:     "fails on unknown state",
+			response: &fakeResponse{stdout: []string{"?abc123 libs/sub"}},
+			wantErr:  fmt.Errorf(`unknown submodule state: "?abc123 libs/sub"`),
+		},
+		{
+			name:     "fails on malformed status",
+			response: &fakeResponse{stdout: []string{" abc123"}},
+			wantErr:  fmt.Errorf(`malformed submodule status: " abc123"`),
+		},
+		{
+			name:     "fails if git fails",
+			response: &fakeResponse{err: fmt.Errorf("oops")},
+			wantErr:  fmt.Errorf("oops"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fr := &fakeRunner{responses: []*fakeResponse{test.response}}
+			got, err := New(fr).Submodules()
+			commandtest.CmpError(t, "Submodules()", test.wantErr, err)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Submodules() returned incorrect submodules (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([][]string{{"submodule", "status", "--recursive"}}, fr.got); diff != "" {
+				t.Errorf("Submodules() ran incorrect commands (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubmoduleStatusEntries(t *testing.T) {
+	for _, test := range []struct {
+		name              string
+		e                 *StatusEntry
+		wantSubmodule     bool
+		wantCommitChanged bool
+	}{
+		{
+			name: "file",
+			e:    &StatusEntry{Type: Changed, XY: ".M", Submodule: "N..."},
+		},
+		{
+			name: "untracked file",
+			e:    &StatusEntry{Type: Untracked},
+		},
+		{
+			name:          "submodule with modified content",
+			e:             &StatusEntry{Type: Changed, XY: ".M", Submodule: "S.MU"},
+			wantSubmodule: true,
+		},
+		{
+			name:              "submodule with new commits",
+			e:                 &StatusEntry{Type: Changed, XY: ".M", Submodule: "SC.."},
+			wantSubmodule:     true,
+			wantCommitChanged: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.e.IsSubmodule(); got != test.wantSubmodule {
+				t.Errorf("IsSubmodule() returned %v; want %v", got, test.wantSubmodule)
+			}
+			if got := test.e.SubmoduleCommitChanged(); got != test.wantCommitChanged {
+				t.Errorf("SubmoduleCommitChanged() returned %v; want %v", got, test.wantCommitChanged)
+			}
+		})
+	}
+}
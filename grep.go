@@ -0,0 +1,87 @@
+package sourcecontrol
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+)
+
+var (
+	grepPatternArg = commander.Arg[string]("PATTERN", "Pattern to search for")
+	grepPathsArg   = commander.ListArg[string](
+		"PATHS", "Files and directories to search (defaults to the current directory)",
+		0, command.UnboundedList,
+		trackedFileCompleter[[]string](),
+	)
+	grepIgnoreCaseFlag = commander.BoolFlag("ignore-case", 'i', "Whether or not to ignore case differences")
+	grepWordFlag       = commander.BoolFlag("word", 'w', "Whether or not to only match whole words")
+	grepFixedFlag      = commander.BoolFlag("fixed", 'f', "Whether or not to match PATTERN as a literal string instead of a regex")
+	grepExtendedFlag   = commander.BoolFlag("extended", 'e', "Whether or not PATTERN is an extended regex")
+	grepRefFlag        = commander.Flag[string]("ref", 'r', "Branch or tag to search instead of the working tree", refCompleter[string](false))
+)
+
+func grepNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("Search tracked files, printing each match as `file:line:text`"),
+		commander.FlagProcessor(
+			grepIgnoreCaseFlag,
+			grepWordFlag,
+			grepFixedFlag,
+			grepExtendedFlag,
+			grepRefFlag,
+		),
+		grepPatternArg,
+		grepPathsArg,
+		commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+			if grepFixedFlag.Get(d) && grepExtendedFlag.Get(d) {
+				return nil, o.Stderrf("--%s and --%s can't both be provided\n", grepFixedFlag.Name(), grepExtendedFlag.Name())
+			}
+			grep := gitrepo.Grep(&gitrepo.GrepOptions{
+				Pattern:        grepPatternArg.Get(d),
+				Paths:          grepPathsArg.Get(d),
+				Ref:            grepRefFlag.Get(d),
+				IgnoreCase:     grepIgnoreCaseFlag.Get(d),
+				WordRegexp:     grepWordFlag.Get(d),
+				FixedStrings:   grepFixedFlag.Get(d),
+				ExtendedRegexp: grepExtendedFlag.Get(d),
+			})
+			if grepRefFlag.Provided(d) {
+				return nil, grepRef(o, d, grep)
+			}
+			return []string{commandString(grep)}, nil
+		}),
+	)
+}
+
+// noGrepMatchRegex matches runner errors for git grep's "no matches" exit
+// status. The runners don't expose exit codes, so the error message is checked
+// instead.
+var noGrepMatchRegex = regexp.MustCompile(`exit status 1(:|$)`)
+
+// grepRef runs the provided grep command (which searches a ref) and prints its
+// results. Results from a ref are prefixed with `REF:` (and refs can't contain
+// colons), so the prefix is dropped to keep the `file:line:` format.
+func grepRef(o command.Output, d *command.Data, grep *gitrepo.Command) error {
+	repo := gitRepo(d)
+	ref := grepRefFlag.Get(d)
+	if !repo.RefExists(ref) {
+		return o.Stderrf("%s does not exist\n", ref)
+	}
+
+	out, err := repo.Run(grep)
+	if err != nil {
+		// git grep exits with status 1 when nothing matches (and 128 for actual
+		// errors, like an invalid pattern).
+		if noGrepMatchRegex.MatchString(err.Error()) {
+			return o.Stderrf("No matches in %s\n", ref)
+		}
+		return o.Annotatef(err, "failed to grep %s", ref)
+	}
+	for _, line := range out {
+		o.Stdoutln(strings.TrimPrefix(line, ref+":"))
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package sourcecontrol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+)
+
+var (
+	patchFlag = commander.BoolFlag("patch", 'p', "Whether or not to interactively choose hunks")
+
+	// stdin is a stub for os.Stdin
+	stdin io.Reader = os.Stdin
+	// now is a stub for time.Now
+	now = time.Now
+)
+
+// hunkSelector interactively chooses hunks from a set of diffs.
+type hunkSelector struct {
+	// verb is the action that will be taken on selected hunks (e.g. "Stage").
+	verb string
+
+	r *bufio.Reader
+}
+
+func newHunkSelector(verb string) *hunkSelector {
+	return &hunkSelector{
+		verb: verb,
+		r:    bufio.NewReader(stdin),
+	}
+}
+
+func (hs *hunkSelector) help(o command.Output) {
+	o.Stdoutln(strings.Join([]string{
+		"y - " + strings.ToLower(hs.verb) + " this hunk",
+		"n - do not " + strings.ToLower(hs.verb) + " this hunk",
+		"s - split the current hunk into smaller hunks",
+		"q - quit; do not " + strings.ToLower(hs.verb) + " this hunk or any of the remaining ones",
+		"? - print help",
+	}, "\n"))
+}
+
+// prompt returns the user's (trimmed) response. quit is true if there is no
+// more input.
+func (hs *hunkSelector) prompt(o command.Output, s string) (string, bool) {
+	o.Stdout(s)
+	line, err := hs.r.ReadString('\n')
+	if err != nil && line == "" {
+		o.Stdoutln()
+		return "", true
+	}
+	return strings.TrimSpace(line), false
+}
+
+// selectHunks prompts the user to select hunks and returns a patch containing
+// the selected hunks (or an empty string if no hunks were selected).
+func (hs *hunkSelector) selectHunks(o command.Output, diffs []*gitrepo.FileDiff) string {
+	var patch strings.Builder
+	for _, fd := range diffs {
+		if len(fd.Hunks) == 0 {
+			continue
+		}
+		o.Stdoutln(strings.Join(fd.Header, "\n"))
+
+		var selected []*gitrepo.Hunk
+		queue := fd.Hunks
+		quit := false
+		for len(queue) > 0 && !quit {
+			h := queue[0]
+			o.Stdoutln(h.String())
+
+			split := h.Split()
+			opts := "y,n,q"
+			if len(split) > 1 {
+				opts += ",s"
+			}
+
+			resp, eof := hs.prompt(o, hs.verb+" this hunk ["+opts+",?]? ")
+			switch {
+			case eof || resp == "q":
+				quit = true
+			case resp == "y":
+				selected = append(selected, h)
+				queue = queue[1:]
+			case resp == "n":
+				queue = queue[1:]
+			case resp == "s" && len(split) > 1:
+				o.Stdoutf("Split into %d hunks.\n", len(split))
+				queue = append(split, queue[1:]...)
+			case resp == "s":
+				o.Stdoutln("Sorry, cannot split this hunk")
+			default:
+				hs.help(o)
+			}
+		}
+
+		if len(selected) > 0 {
+			patch.WriteString(fd.Patch(selected))
+		}
+		if quit {
+			break
+		}
+	}
+	return patch.String()
+}
+
+// applyHunks prompts the user to select hunks from the unstaged (or staged, if
+// `cached` is true) diffs of the files and applies a patch of the selected hunks.
+// If `save` is true, the patch is saved (so it can be recovered) before it is applied.
+func applyHunks(o command.Output, d *command.Data, files []string, verb string, cached, save bool, opts *gitrepo.ApplyOptions) error {
+	repo := gitRepo(d)
+	diffs, err := repo.FileDiffs(cached, files...)
+	if err != nil {
+		return o.Annotatef(err, "failed to get diff")
+	}
+
+	patch := newHunkSelector(verb).selectHunks(o, diffs)
+	if patch == "" {
+		o.Stdoutln("No hunks selected")
+		return nil
+	}
+
+	if save {
+		f, err := savePatch(repo, patch)
+		if err != nil {
+			return o.Annotatef(err, "failed to save patch")
+		}
+		o.Stdoutf("Saved discarded hunks to %s (restore them with `git apply %s` from the root of the repo)\n", f, f)
+	}
+
+	if err := repo.ApplyPatch(patch, opts); err != nil {
+		return o.Annotatef(err, "failed to apply patch")
+	}
+	return nil
+}
+
+// savePatch saves the patch in the repo's git directory and returns the file
+// it was saved to.
+func savePatch(repo *gitrepo.Repo, patch string) (string, error) {
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(gitDir, "g", "discarded")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	f := filepath.Join(dir, now().Format("20060102-150405.000000")+".patch")
+	if err := os.WriteFile(f, []byte(patch), 0644); err != nil {
+		return "", fmt.Errorf("failed to write patch file: %v", err)
+	}
+	return f, nil
+}
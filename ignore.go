@@ -0,0 +1,231 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	ignorePatternsArg = commander.ListArg[string](
+		"PATTERN", "Patterns to ignore (relative to the current directory)",
+		1, command.UnboundedList,
+		untrackedFileCompleter[[]string](),
+	)
+	ignoreLocalFlag = commander.BoolFlag("local", 'l', "Whether to add the patterns to .git/info/exclude (which isn't committed) instead of .gitignore")
+)
+
+// untrackedFileCompleter completes untracked files and directories.
+func untrackedFileCompleter[T any]() commander.Completer[T] {
+	return commander.CompleterFromFunc(func(t T, d *command.Data) (*command.Completion, error) {
+		repo := completionRepo(d)
+		entries, err := repo.Status()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get git status: %v", err)
+		}
+
+		var files []string
+		for _, e := range entries {
+			if e.Type == gitrepo.Untracked {
+				files = append(files, e.Path)
+			}
+		}
+		if files, err = repo.RelativePaths(files); err != nil {
+			return nil, fmt.Errorf("failed to get relative paths: %v", err)
+		}
+		// The current directory can't be ignored from within itself.
+		files = slices.DeleteFunc(files, func(f string) bool { return f == "./" })
+		return fileCompletion(t, d, &command.Completion{
+			Suggestions: files,
+			Distinct:    true,
+		}), nil
+	})
+}
+
+// ignoreLocation returns the directory (relative to the root of the repo) of
+// the .gitignore file that a pattern (relative to the directory with the
+// provided prefix) belongs in, along with the pattern relative to that
+// directory. Leading "../" segments move the pattern up a directory, and
+// patterns for specific paths (i.e. that contain a slash) stay anchored.
+func ignoreLocation(prefix, pattern string) (string, string, error) {
+	dir := strings.TrimSuffix(prefix, "/")
+	rest := pattern
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	for {
+		if strings.HasPrefix(rest, "./") {
+			rest = rest[2:]
+		} else if strings.HasPrefix(rest, "../") {
+			if dir == "" {
+				return "", "", fmt.Errorf("pattern %q is outside of the repo", pattern)
+			}
+			if dir = path.Dir(dir); dir == "." {
+				dir = ""
+			}
+			rest = rest[3:]
+		} else {
+			break
+		}
+	}
+
+	if strings.Trim(rest, "/") == "" {
+		return "", "", fmt.Errorf("invalid ignore pattern: %q", pattern)
+	}
+	if anchored && !strings.Contains(strings.TrimSuffix(rest, "/"), "/") {
+		rest = "/" + rest
+	}
+	return dir, rest, nil
+}
+
+// excludePattern converts a pattern relative to the provided directory into
+// one relative to the root of the repo (for .git/info/exclude).
+func excludePattern(dir, pattern string) string {
+	switch {
+	case dir == "":
+		return pattern
+	case strings.Contains(strings.TrimSuffix(pattern, "/"), "/"):
+		return "/" + dir + "/" + strings.TrimPrefix(pattern, "/")
+	default:
+		// Patterns without a slash match at any depth below the directory.
+		return "/" + dir + "/**/" + pattern
+	}
+}
+
+// appendIgnorePatterns appends the patterns that aren't already in the
+// provided ignore file (creating it if necessary), and returns the ones that
+// were added.
+func appendIgnorePatterns(file string, patterns []string) ([]string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	contents := string(b)
+	existing := strings.Split(strings.ReplaceAll(contents, "\r\n", "\n"), "\n")
+
+	var added []string
+	for _, p := range patterns {
+		if slices.Contains(existing, p) || slices.Contains(added, p) {
+			continue
+		}
+		added = append(added, p)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	if contents != "" && !strings.HasSuffix(contents, "\n") {
+		contents += "\n"
+	}
+	contents += strings.Join(added, "\n") + "\n"
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, []byte(contents), 0644); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+func (g *git) ignoreNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("Ignore files by adding patterns to the .gitignore in the current directory (or to .git/info/exclude with --local)"),
+		commander.FlagProcessor(ignoreLocalFlag),
+		ignorePatternsArg,
+		g.clearCompletionCache(),
+		&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+			repo := gitRepo(d)
+			top, err := repo.TopLevel()
+			if err != nil {
+				return o.Annotatef(err, "failed to get repo root")
+			}
+			prefix, err := repo.Prefix()
+			if err != nil {
+				return o.Annotatef(err, "failed to get current directory")
+			}
+
+			var exclude string
+			if ignoreLocalFlag.Get(d) {
+				if exclude, err = repo.GitPath("info/exclude"); err != nil {
+					return o.Annotatef(err, "failed to get exclude file")
+				}
+			}
+
+			// Group the patterns by file (in the order they were provided).
+			var files []string
+			patterns := map[string][]string{}
+			for _, p := range ignorePatternsArg.Get(d) {
+				dir, rest, err := ignoreLocation(prefix, filepath.ToSlash(p))
+				if err != nil {
+					return o.Err(err)
+				}
+				file := filepath.Join(top, filepath.FromSlash(dir), ".gitignore")
+				if exclude != "" {
+					file, rest = exclude, excludePattern(dir, rest)
+				}
+				if _, ok := patterns[file]; !ok {
+					files = append(files, file)
+				}
+				patterns[file] = append(patterns[file], rest)
+			}
+
+			for _, file := range files {
+				name := file
+				if rel, err := filepath.Rel(top, file); err == nil {
+					name = filepath.ToSlash(rel)
+				}
+				added, err := appendIgnorePatterns(file, patterns[file])
+				if err != nil {
+					return o.Annotatef(err, "failed to update %s", name)
+				}
+				for _, p := range patterns[file] {
+					if slices.Contains(added, p) {
+						o.Stdoutf("Ignoring %s in %s\n", p, name)
+					} else {
+						o.Stdoutf("%s is already in %s\n", p, name)
+					}
+				}
+			}
+			return nil
+		}},
+	)
+}
+
+func ignoredNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("List ignored files and the rules that ignore them"),
+		&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+			repo := gitRepo(d)
+			files, err := repo.IgnoredFiles()
+			if err != nil {
+				return o.Annotatef(err, "failed to get ignored files")
+			}
+			if files, err = repo.RelativePaths(files); err != nil {
+				return o.Annotatef(err, "failed to get relative paths")
+			}
+			if len(files) == 0 {
+				o.Stdoutln("No ignored files")
+				return nil
+			}
+
+			rules, err := repo.IgnoreRules(files...)
+			if err != nil {
+				return o.Annotatef(err, "failed to get ignore rules")
+			}
+			var sb strings.Builder
+			w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+			for _, r := range rules {
+				fmt.Fprintf(w, "%s\t%s:%d: %s\n", r.Path, r.Source, r.Line, r.Pattern)
+			}
+			w.Flush()
+			o.Stdout(sb.String())
+			return nil
+		}},
+	)
+}
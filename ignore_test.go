@@ -0,0 +1,130 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandtest"
+)
+
+func TestIgnoreLocation(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		prefix      string
+		pattern     string
+		wantDir     string
+		wantPattern string
+		wantErr     error
+	}{
+		{
+			name:        "pattern at the root",
+			pattern:     "*.log",
+			wantPattern: "*.log",
+		},
+		{
+			name:        "pattern in a subdirectory",
+			prefix:      "sub/dir/",
+			pattern:     "*.log",
+			wantDir:     "sub/dir",
+			wantPattern: "*.log",
+		},
+		{
+			name:        "path in the current directory",
+			prefix:      "sub/",
+			pattern:     "build/out.txt",
+			wantDir:     "sub",
+			wantPattern: "build/out.txt",
+		},
+		{
+			name:        "directory",
+			prefix:      "sub/",
+			pattern:     "build/",
+			wantDir:     "sub",
+			wantPattern: "build/",
+		},
+		{
+			name:        "path in a parent directory is anchored",
+			prefix:      "sub/dir/",
+			pattern:     "../out.txt",
+			wantDir:     "sub",
+			wantPattern: "/out.txt",
+		},
+		{
+			name:        "path in the root directory is anchored",
+			prefix:      "sub/dir/",
+			pattern:     "../../build/",
+			wantDir:     "",
+			wantPattern: "/build/",
+		},
+		{
+			name:        "path in a sibling directory",
+			prefix:      "sub/dir/",
+			pattern:     "./../other/out.txt",
+			wantDir:     "sub",
+			wantPattern: "other/out.txt",
+		},
+		{
+			name:        "explicit current directory path is anchored",
+			pattern:     "./out.txt",
+			wantPattern: "/out.txt",
+		},
+		{
+			name:    "pattern outside of the repo",
+			prefix:  "sub/",
+			pattern: "../../out.txt",
+			wantErr: fmt.Errorf(`pattern "../../out.txt" is outside of the repo`),
+		},
+		{
+			name:    "empty pattern",
+			prefix:  "sub/",
+			pattern: "../",
+			wantErr: fmt.Errorf(`invalid ignore pattern: "../"`),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir, pattern, err := ignoreLocation(test.prefix, test.pattern)
+			commandtest.CmpError(t, fmt.Sprintf("ignoreLocation(%q, %q)", test.prefix, test.pattern), test.wantErr, err)
+			if diff := cmp.Diff(test.wantDir, dir); diff != "" {
+				t.Errorf("ignoreLocation(%q, %q) returned incorrect directory (-want, +got):\n%s", test.prefix, test.pattern, diff)
+			}
+			if diff := cmp.Diff(test.wantPattern, pattern); diff != "" {
+				t.Errorf("ignoreLocation(%q, %q) returned incorrect pattern (-want, +got):\n%s", test.prefix, test.pattern, diff)
+			}
+		})
+	}
+}
+
+func TestExcludePattern(t *testing.T) {
+	for _, test := range []struct {
+		dir     string
+		pattern string
+		want    string
+	}{
+		{
+			pattern: "*.log",
+			want:    "*.log",
+		},
+		{
+			dir:     "sub/dir",
+			pattern: "*.log",
+			want:    "/sub/dir/**/*.log",
+		},
+		{
+			dir:     "sub",
+			pattern: "/out.txt",
+			want:    "/sub/out.txt",
+		},
+		{
+			dir:     "sub",
+			pattern: "build/out/",
+			want:    "/sub/build/out/",
+		},
+	} {
+		t.Run(test.dir+":"+test.pattern, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, excludePattern(test.dir, test.pattern)); diff != "" {
+				t.Errorf("excludePattern(%q, %q) returned incorrect pattern (-want, +got):\n%s", test.dir, test.pattern, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package sourcecontrol
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	overrideProtectedFlag = commander.BoolFlag("override-protected", 'o', "Whether or not to allow changing commits on a protected branch")
+	protectBranchesArg    = commander.ListArg[string]("BRANCH", "Branches to protect", 1, command.UnboundedList, branchCompleter[[]string]())
+)
+
+// protectedBranches returns the protected branches for the current repo. If
+// none are configured, then only the default branch is protected.
+func (g *git) protectedBranches(d *command.Data) []string {
+	if branches := g.ProtectedBranches[repoName.Get(d)]; len(branches) > 0 {
+		return branches
+	}
+	return []string{g.GetDefaultBranch(d)}
+}
+
+// isProtected returns whether or not the provided branch is protected in the
+// current repo.
+func (g *git) isProtected(d *command.Data, branch string) bool {
+	return slices.Contains(g.protectedBranches(d), branch)
+}
+
+// isForcePushProtected returns whether or not the provided branch must not be
+// force pushed in the current repo. Unlike `isProtected`, the default branch
+// is always included.
+func (g *git) isForcePushProtected(d *command.Data, branch string) bool {
+	return branch == g.GetDefaultBranch(d) || g.isProtected(d, branch)
+}
+
+// protectedBranchGuard is a `command.Processor` that stops commands that
+// change commits from running on a protected branch. The user is offered the
+// chance to create a new branch (and run the command there) instead.
+type protectedBranchGuard struct {
+	g *git
+	// action describes what the command does (e.g. "commit").
+	action string
+}
+
+func (g *git) protectedBranchGuard(action string) command.Processor {
+	return &protectedBranchGuard{g, action}
+}
+
+func (pbg *protectedBranchGuard) Execute(i *command.Input, o command.Output, d *command.Data, ed *command.ExecuteData) error {
+	if overrideProtectedFlag.Get(d) {
+		return nil
+	}
+
+	// The repo name is only needed if the protected branches are configured per repo.
+	if !d.Has(repoName.Name()) && (len(pbg.g.MainBranches) > 0 || len(pbg.g.ProtectedBranches) > 0) {
+		setOptionalRepoName(d)
+	}
+
+	repo := gitRepo(d)
+	branch := currentBranchArg.Get(d)
+	if !d.Has(currentBranchArg.Name()) {
+		var err error
+		if branch, err = repo.CurrentBranch(); err != nil {
+			return o.Annotatef(err, "failed to get current branch")
+		}
+	}
+	if !pbg.g.isProtected(d, branch) {
+		return nil
+	}
+
+	o.Stdoutf("%s is a protected branch; enter a new branch name to %s on instead (or nothing to cancel): ", branch, pbg.action)
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	newBranch := strings.TrimSpace(line)
+	if newBranch == "" {
+		if err != nil {
+			o.Stdoutln()
+		}
+		return o.Stderrf("refusing to %s on protected branch %s (use --%s to override)\n", pbg.action, branch, overrideProtectedFlag.Name())
+	}
+
+	if _, err := repo.Run(gitrepo.Checkout(newBranch, true)); err != nil {
+		return o.Annotatef(err, "failed to create branch %s", newBranch)
+	}
+	o.Stdoutf("Switched to new branch %s\n", newBranch)
+	// Later processors (e.g. the ticket prefix) should use the new branch.
+	if d.Has(currentBranchArg.Name()) {
+		d.Set(currentBranchArg.Name(), newBranch)
+	}
+	return nil
+}
+
+func (pbg *protectedBranchGuard) Complete(*command.Input, *command.Data) (*command.Completion, error) {
+	return nil, nil
+}
+
+func (pbg *protectedBranchGuard) Usage(*command.Input, *command.Data, *command.Usage) error {
+	return nil
+}
+
+func (g *git) showProtectedBranches(o command.Output) {
+	if len(g.ProtectedBranches) == 0 {
+		o.Stdoutln("No protected branches set; only default branches are protected")
+		return
+	}
+
+	keys := maps.Keys(g.ProtectedBranches)
+	slices.Sort(keys)
+	for _, k := range keys {
+		o.Stdoutf("%s: protecting %s\n", k, strings.Join(g.ProtectedBranches[k], ", "))
+	}
+}
+
+func (g *git) protectedBranchConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show protected branches (the default branch is protected from commits if none are set, and always from force pushes)"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showProtectedBranches(o)
+					return nil
+				}},
+			),
+			"add": commander.SerialNodes(
+				commander.Description("Protect branches in this repo from commits, amends, and force pushes (the default branch is always protected from force pushes)"),
+				repoName,
+				protectBranchesArg,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					if g.ProtectedBranches == nil {
+						g.ProtectedBranches = map[string][]string{}
+					}
+					rn := repoName.Get(d)
+					for _, b := range protectBranchesArg.Get(d) {
+						if !slices.Contains(g.ProtectedBranches[rn], b) {
+							g.ProtectedBranches[rn] = append(g.ProtectedBranches[rn], b)
+						}
+					}
+					g.changed = true
+					o.Stdoutf("Protecting %s in %s\n", strings.Join(protectBranchesArg.Get(d), ", "), rn)
+					return nil
+				}},
+			),
+			"rm": commander.SerialNodes(
+				commander.Description("Stop protecting branches in this repo"),
+				repoName,
+				commander.ListArg[string](protectBranchesArg.Name(), "Branches to stop protecting", 1, command.UnboundedList,
+					commander.CompleterFromFunc(func(sl []string, d *command.Data) (*command.Completion, error) {
+						return &command.Completion{
+							Suggestions: g.ProtectedBranches[repoName.Get(d)],
+							Distinct:    true,
+						}, nil
+					}),
+				),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					rn := repoName.Get(d)
+					for _, b := range protectBranchesArg.Get(d) {
+						if !slices.Contains(g.ProtectedBranches[rn], b) {
+							return o.Stderrf("%s is not a protected branch\n", b)
+						}
+					}
+					g.ProtectedBranches[rn] = slices.DeleteFunc(g.ProtectedBranches[rn], func(b string) bool {
+						return slices.Contains(protectBranchesArg.Get(d), b)
+					})
+					if len(g.ProtectedBranches[rn]) == 0 {
+						delete(g.ProtectedBranches, rn)
+					}
+					g.changed = true
+					o.Stdoutf("No longer protecting %s in %s\n", strings.Join(protectBranchesArg.Get(d), ", "), rn)
+					return nil
+				}},
+			),
+		},
+	}
+}
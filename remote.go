@@ -0,0 +1,263 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	mergePullMode  = "merge"
+	rebasePullMode = "rebase"
+	ffOnlyPullMode = "ff-only"
+
+	defaultPullMode = mergePullMode
+)
+
+var (
+	pullModes       = []string{mergePullMode, rebasePullMode, ffOnlyPullMode}
+	pullModeArg     = commander.Arg[string]("MODE", "How `g l` integrates the upstream branch", commander.SimpleCompleter[string](pullModes...), commander.InList(pullModes...))
+	pullRebaseFlag  = commander.BoolFlag("rebase", 'r', "Whether or not to rebase onto the upstream branch (overrides the repo setting)")
+	pullFFOnlyFlag  = commander.BoolFlag("ff-only", 'f', "Whether or not to only pull if the branch can be fast-forwarded (overrides the repo setting)")
+	pullMergeFlag   = commander.BoolFlag("merge", 'm', "Whether or not to merge the upstream branch (overrides the repo setting)")
+	pullModeFlagSet = []commander.FlagWithType[bool]{pullRebaseFlag, pullFFOnlyFlag, pullMergeFlag}
+	forcePushFlag   = commander.BoolFlag("force", 'f', "Whether or not to force push (only if the remote branch hasn't changed since the last fetch)")
+)
+
+// upstreamState returns the current branch and its upstream (or an empty
+// string if it doesn't have one).
+func upstreamState(repo *gitrepo.Repo) (string, string, error) {
+	cur, err := repo.CurrentBranch()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get current branch: %v", err)
+	}
+	upstream, err := repo.Upstream(cur)
+	if err != nil {
+		return cur, "", nil
+	}
+	return cur, upstream, nil
+}
+
+func (g *git) pushNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("Push (and set the upstream if the branch doesn't have one)"),
+		commander.FlagProcessor(forcePushFlag),
+		// The repo name is needed to look up protected branches.
+		commander.If(
+			optionalRepoName,
+			func(i *command.Input, d *command.Data) bool {
+				return forcePushFlag.Get(d)
+			},
+		),
+		sshNode,
+		commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+			repo := gitRepo(d)
+			cur, upstream, err := upstreamState(repo)
+			if err != nil {
+				return nil, o.Err(err)
+			}
+
+			// Check the branch that will be pushed to, which may not have the same
+			// name as the local branch.
+			dest := cur
+			if upstream != "" {
+				_, dest, _ = strings.Cut(upstream, "/")
+			}
+			if forcePushFlag.Get(d) && g.isForcePushProtected(d, dest) {
+				return nil, o.Stderrf("refusing to force push protected branch %s\n", dest)
+			}
+
+			if upstream == "" {
+				pushCmd := commandString(gitrepo.Push(&gitrepo.PushOptions{
+					SetUpstream: true,
+					Remote:      "origin",
+					Branch:      cur,
+				}))
+				o.Stdoutln(pushCmd)
+				return []string{pushCmd}, nil
+			}
+
+			if forcePushFlag.Get(d) {
+				return forcePush(o, repo, cur, upstream)
+			}
+
+			_, behind, err := repo.AheadBehind(cur, upstream)
+			if err != nil {
+				return nil, o.Annotatef(err, "failed to compare %s with %s", cur, upstream)
+			}
+			if behind > 0 {
+				o.Stderrf("Warning: %s is %d %s behind %s (as of the last fetch), so the push may be rejected; run `g l` first\n", cur, behind, pluralCommits(behind), upstream)
+			}
+			return []string{commandString(gitrepo.Push(nil))}, nil
+		}),
+	)
+}
+
+// forcePush returns a force push command with a lease pinned to the last
+// fetched commit of the upstream branch, so commits pushed by others since
+// then are never overwritten. The commits that will be overwritten are printed.
+func forcePush(o command.Output, repo *gitrepo.Repo, cur, upstream string) ([]string, error) {
+	sha, err := repo.RevParse(upstream)
+	if err != nil {
+		return nil, o.Annotatef(err, "failed to get commit for %s", upstream)
+	}
+
+	overwritten, err := repo.Commits(cur, upstream)
+	if err != nil {
+		return nil, o.Annotatef(err, "failed to get commits in %s", upstream)
+	}
+	if len(overwritten) == 0 {
+		o.Stdoutf("No commits in %s will be overwritten\n", upstream)
+	} else {
+		o.Stdoutf("Force pushing will overwrite %d %s in %s:\n", len(overwritten), pluralCommits(len(overwritten)), upstream)
+		for _, c := range overwritten {
+			o.Stdoutf("  %s\n", c)
+		}
+	}
+
+	remote, branch, _ := strings.Cut(upstream, "/")
+	return []string{commandString(gitrepo.Push(&gitrepo.PushOptions{
+		ForceWithLease: true,
+		LeaseRef:       branch,
+		LeaseSHA:       sha,
+		Remote:         remote,
+		Branch:         fmt.Sprintf("HEAD:%s", branch),
+	}))}, nil
+}
+
+// pullMode returns the mode to use for `g l` in the current repo.
+func (g *git) pullMode(d *command.Data) string {
+	switch {
+	case pullRebaseFlag.Get(d):
+		return rebasePullMode
+	case pullFFOnlyFlag.Get(d):
+		return ffOnlyPullMode
+	case pullMergeFlag.Get(d):
+		return mergePullMode
+	}
+	if m, ok := g.PullModes[repoName.Get(d)]; ok {
+		return m
+	}
+	return defaultPullMode
+}
+
+func (g *git) pullNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("Pull"),
+		commander.FlagProcessor(
+			pullRebaseFlag,
+			pullFFOnlyFlag,
+			pullMergeFlag,
+		),
+		// Only fetch the repo name if there is a mode to look up.
+		commander.If(
+			optionalRepoName,
+			func(i *command.Input, d *command.Data) bool {
+				return len(g.PullModes) > 0
+			},
+		),
+		sshNode,
+		commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+			var provided int
+			for _, f := range pullModeFlagSet {
+				if f.Get(d) {
+					provided++
+				}
+			}
+			if provided > 1 {
+				return nil, o.Stderrf("only one of --%s, --%s, and --%s can be provided\n", pullRebaseFlag.Name(), pullFFOnlyFlag.Name(), pullMergeFlag.Name())
+			}
+
+			repo := gitRepo(d)
+			cur, upstream, err := upstreamState(repo)
+			if err != nil {
+				return nil, o.Err(err)
+			}
+			if upstream == "" {
+				return nil, o.Stderrf("%s has no upstream branch (run `g p` to push it and set one)\n", cur)
+			}
+
+			mode := g.pullMode(d)
+			ahead, behind, err := repo.AheadBehind(cur, upstream)
+			if err != nil {
+				return nil, o.Annotatef(err, "failed to compare %s with %s", cur, upstream)
+			}
+			if mode == ffOnlyPullMode && ahead > 0 && behind > 0 {
+				o.Stderrf("Warning: %s and %s have diverged (as of the last fetch), so a fast-forward only pull may fail\n", cur, upstream)
+			}
+
+			return []string{commandString(gitrepo.Pull(&gitrepo.PullOptions{
+				Rebase: mode == rebasePullMode,
+				FFOnly: mode == ffOnlyPullMode,
+			}))}, nil
+		}),
+	)
+}
+
+func pluralCommits(n int) string {
+	if n == 1 {
+		return "commit"
+	}
+	return "commits"
+}
+
+func (g *git) showPullModes(o command.Output) {
+	if len(g.PullModes) == 0 {
+		o.Stdoutf("No pull modes set; using %s\n", defaultPullMode)
+		return
+	}
+
+	keys := maps.Keys(g.PullModes)
+	slices.Sort(keys)
+	for _, k := range keys {
+		o.Stdoutf("%s: pull with %s\n", k, g.PullModes[k])
+	}
+}
+
+func (g *git) pullModeConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show pull modes"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showPullModes(o)
+					return nil
+				}},
+			),
+			"set": commander.SerialNodes(
+				commander.Description("Set how `g l` integrates the upstream branch in this repo"),
+				repoName,
+				pullModeArg,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					if g.PullModes == nil {
+						g.PullModes = map[string]string{}
+					}
+					g.PullModes[repoName.Get(d)] = pullModeArg.Get(d)
+					g.changed = true
+					o.Stdoutf("Setting pull mode for %s to %s\n", repoName.Get(d), pullModeArg.Get(d))
+					return nil
+				}},
+			),
+			"unset": commander.SerialNodes(
+				commander.Description("Use the default pull mode in this repo"),
+				repoName,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					rn := repoName.Get(d)
+					if _, ok := g.PullModes[rn]; !ok {
+						o.Stdoutln("No pull mode set for this repo")
+						return nil
+					}
+					delete(g.PullModes, rn)
+					g.changed = true
+					o.Stdoutln("Deleting pull mode for", rn)
+					return nil
+				}},
+			),
+		},
+	}
+}
@@ -0,0 +1,1342 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commandertest"
+	"github.com/leep-frog/command/commandtest"
+	"github.com/leep-frog/command/sourcerer"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"github.com/leep-frog/sourcecontrol/gitrepo/gitrepotest"
+)
+
+// stubRepo runs all git operations against the provided repo.
+func stubRepo(t *testing.T, tr *gitrepotest.TestRepo) {
+	commandtest.StubValue(t, &sourcerer.CurrentOS, sourcerer.Linux())
+	commandtest.StubValue(t, &newRunner, func(*command.Data) gitrepo.Runner {
+		return tr.Runner()
+	})
+}
+
+func TestExecutionWithRealRepo(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		setup func(*gitrepotest.TestRepo)
+		// dir is the subdirectory of the repo to run the command in.
+		dir string
+		// stdin is the input for interactive prompts.
+		stdin string
+		etc   *commandtest.ExecuteTestCase
+		// wantStdout overrides etc.WantStdout for output that depends on the repo.
+		wantStdout func(*gitrepotest.TestRepo) string
+		// wantExecutable overrides etc.WantExecuteData for executables that
+		// depend on the repo.
+		wantExecutable func(*gitrepotest.TestRepo) []string
+		// check verifies the state of the repo after the executable is run.
+		check func(*testing.T, *gitrepotest.TestRepo)
+	}{
+		{
+			name: "add stages files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("one.txt", "1\n")
+				tr.WriteFile("two words.txt", "2\n")
+				tr.WriteFile("three.txt", "3\n")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"a", "one.txt", "two words.txt"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					filesArg.Name(): []string{"one.txt", "two words.txt"},
+				}},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{`git add one.txt "two words.txt"`},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Changed, XY: "A.", Submodule: "N...", Path: "one.txt"},
+					{Type: gitrepo.Changed, XY: "A.", Submodule: "N...", Path: "two words.txt"},
+					{Type: gitrepo.Untracked, Path: "three.txt"},
+				})
+			},
+		},
+		{
+			name: "add patch stages selected hunks",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("file.txt", "a\nb\nc\nd\ne\nf\ng\n")
+				tr.Commit("Add file")
+				tr.WriteFile("file.txt", "A\nb\nc\nd\ne\nf\nG\n")
+				tr.WriteFile("README.md", "changed\n")
+			},
+			stdin: "s\nn\nx\ns\nn\ny\n",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"a", "-p"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					patchFlag.Name(): true,
+				}},
+				WantStdout: strings.Join([]string{
+					"diff --git a/README.md b/README.md",
+					"index f9f9fb7..5ea2ed4 100644",
+					"--- a/README.md",
+					"+++ b/README.md",
+					"@@ -1,1 +1,1 @@",
+					"-# Test repo",
+					"+changed",
+					"Stage this hunk [y,n,q,?]? Sorry, cannot split this hunk",
+					"@@ -1,1 +1,1 @@",
+					"-# Test repo",
+					"+changed",
+					"Stage this hunk [y,n,q,?]? diff --git a/file.txt b/file.txt",
+					"index f9d9a01..1d6182b 100644",
+					"--- a/file.txt",
+					"+++ b/file.txt",
+					"@@ -1,7 +1,7 @@",
+					"-a", "+A", " b", " c", " d", " e", " f", "-g", "+G",
+					"Stage this hunk [y,n,q,s,?]? y - stage this hunk",
+					"n - do not stage this hunk",
+					"s - split the current hunk into smaller hunks",
+					"q - quit; do not stage this hunk or any of the remaining ones",
+					"? - print help",
+					"@@ -1,7 +1,7 @@",
+					"-a", "+A", " b", " c", " d", " e", " f", "-g", "+G",
+					"Stage this hunk [y,n,q,s,?]? Split into 2 hunks.",
+					"@@ -1,6 +1,6 @@",
+					"-a", "+A", " b", " c", " d", " e", " f",
+					"Stage this hunk [y,n,q,?]? @@ -2,6 +2,6 @@",
+					" b", " c", " d", " e", " f", "-g", "+G",
+					"Stage this hunk [y,n,q,?]? ",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"a", "b", "c", "d", "e", "f", "G"}, tr.Git("show", ":file.txt")); diff != "" {
+					t.Errorf("Patch staged incorrect contents (-want, +got):\n%s", diff)
+				}
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Changed, XY: ".M", Submodule: "N...", Path: "README.md"},
+					{Type: gitrepo.Changed, XY: "MM", Submodule: "N...", Path: "file.txt"},
+				})
+			},
+		},
+		{
+			name: "add patch stages every piece of a split hunk",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("file.txt", "a\nb\nc\nd\ne\nf\ng\nh\n")
+				tr.Commit("Add file")
+				tr.WriteFile("file.txt", "a\nb\nC\nd\ne\nF\ng\nh\n")
+			},
+			stdin: "s\ny\ny\n",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"a", "-p", "file.txt"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					patchFlag.Name(): true,
+					filesArg.Name():  []string{"file.txt"},
+				}},
+			},
+			wantStdout: func(tr *gitrepotest.TestRepo) string {
+				index := fmt.Sprintf("index %s..%s 100644", tr.Git("rev-parse", "--short", "HEAD:file.txt")[0], tr.Git("hash-object", "file.txt")[0][:7])
+				return strings.Join([]string{
+					"diff --git a/file.txt b/file.txt",
+					index,
+					"--- a/file.txt",
+					"+++ b/file.txt",
+					"@@ -1,8 +1,8 @@",
+					" a", " b", "-c", "+C", " d", " e", "-f", "+F", " g", " h",
+					"Stage this hunk [y,n,q,s,?]? Split into 2 hunks.",
+					"@@ -1,5 +1,5 @@",
+					" a", " b", "-c", "+C", " d", " e",
+					"Stage this hunk [y,n,q,?]? @@ -4,5 +4,5 @@",
+					" d", " e", "-f", "+F", " g", " h",
+					"Stage this hunk [y,n,q,?]? ",
+				}, "\n")
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"a", "b", "C", "d", "e", "F", "g", "h"}, tr.Git("show", ":file.txt")); diff != "" {
+					t.Errorf("Patch staged incorrect contents (-want, +got):\n%s", diff)
+				}
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Changed, XY: "M.", Submodule: "N...", Path: "file.txt"},
+				})
+			},
+		},
+		{
+			name: "add patch quits",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("README.md", "changed\n")
+			},
+			stdin: "q\n",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"a", "-p", "README.md"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					patchFlag.Name(): true,
+					filesArg.Name():  []string{"README.md"},
+				}},
+				WantStdout: strings.Join([]string{
+					"diff --git a/README.md b/README.md",
+					"index f9f9fb7..5ea2ed4 100644",
+					"--- a/README.md",
+					"+++ b/README.md",
+					"@@ -1,1 +1,1 @@",
+					"-# Test repo",
+					"+changed",
+					"Stage this hunk [y,n,q,?]? No hunks selected",
+					"",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Changed, XY: ".M", Submodule: "N...", Path: "README.md"},
+				})
+			},
+		},
+		{
+			name: "undo change discards changes",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("README.md", "changed\n")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"uc", "README.md"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					ucArgs.Name(): []string{"README.md"},
+				}},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{"git checkout -- README.md"},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, nil)
+				if got := tr.ReadFile("README.md"); got != "# Test repo\n" {
+					t.Errorf("README.md has contents %q after undoing change", got)
+				}
+			},
+		},
+		{
+			name: "undo change patch discards selected hunks",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("file.txt", "a\nb\nc\nd\ne\nf\ng\n")
+				tr.Commit("Add file")
+				tr.WriteFile("file.txt", "A\nb\nc\nd\ne\nf\nG\n")
+			},
+			stdin: "s\ny\nn\n",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"uc", "-p", "file.txt"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					patchFlag.Name(): true,
+					ucArgs.Name():    []string{"file.txt"},
+				}},
+			},
+			wantStdout: func(tr *gitrepotest.TestRepo) string {
+				f := filepath.Join(tr.Git("rev-parse", "--absolute-git-dir")[0], "g", "discarded", "20240506-070809.000000.patch")
+				return strings.Join([]string{
+					"diff --git a/file.txt b/file.txt",
+					"index f9d9a01..1d6182b 100644",
+					"--- a/file.txt",
+					"+++ b/file.txt",
+					"@@ -1,7 +1,7 @@",
+					"-a", "+A", " b", " c", " d", " e", " f", "-g", "+G",
+					"Discard this hunk [y,n,q,s,?]? Split into 2 hunks.",
+					"@@ -1,6 +1,6 @@",
+					"-a", "+A", " b", " c", " d", " e", " f",
+					"Discard this hunk [y,n,q,?]? @@ -2,6 +2,6 @@",
+					" b", " c", " d", " e", " f", "-g", "+G",
+					fmt.Sprintf("Discard this hunk [y,n,q,?]? Saved discarded hunks to %s (restore them with `git apply %s` from the root of the repo)", f, f),
+					"",
+				}, "\n")
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff("a\nb\nc\nd\ne\nf\nG\n", tr.ReadFile("file.txt")); diff != "" {
+					t.Errorf("Patch discarded incorrect changes (-want, +got):\n%s", diff)
+				}
+
+				// Verify the discarded hunk can be restored.
+				tr.Exec("git apply .git/g/discarded/20240506-070809.000000.patch")
+				if diff := cmp.Diff("A\nb\nc\nd\ne\nf\nG\n", tr.ReadFile("file.txt")); diff != "" {
+					t.Errorf("Restoring discarded patch produced incorrect file (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "undo add patch unstages selected hunks",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("file.txt", "a\nb\nc\nd\ne\nf\ng\n")
+				tr.Commit("Add file")
+				tr.WriteFile("file.txt", "A\nb\nc\nd\ne\nf\nG\n")
+				tr.Git("add", "file.txt")
+			},
+			stdin: "s\nn\ny\n",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ua", "-p", "file.txt"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					patchFlag.Name(): true,
+					uaArgs.Name():    []string{"file.txt"},
+				}},
+				WantStdout: strings.Join([]string{
+					"diff --git a/file.txt b/file.txt",
+					"index f9d9a01..1d6182b 100644",
+					"--- a/file.txt",
+					"+++ b/file.txt",
+					"@@ -1,7 +1,7 @@",
+					"-a", "+A", " b", " c", " d", " e", " f", "-g", "+G",
+					"Unstage this hunk [y,n,q,s,?]? Split into 2 hunks.",
+					"@@ -1,6 +1,6 @@",
+					"-a", "+A", " b", " c", " d", " e", " f",
+					"Unstage this hunk [y,n,q,?]? @@ -2,6 +2,6 @@",
+					" b", " c", " d", " e", " f", "-g", "+G",
+					"Unstage this hunk [y,n,q,?]? ",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"A", "b", "c", "d", "e", "f", "g"}, tr.Git("show", ":file.txt")); diff != "" {
+					t.Errorf("Patch unstaged incorrect changes (-want, +got):\n%s", diff)
+				}
+				if diff := cmp.Diff("A\nb\nc\nd\ne\nf\nG\n", tr.ReadFile("file.txt")); diff != "" {
+					t.Errorf("Patch modified the working tree (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "undo add unstages files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("README.md", "changed\n")
+				tr.Git("add", "README.md")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ua", "README.md"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					uaArgs.Name(): []string{"README.md"},
+				}},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{"git reset -- README.md"},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Changed, XY: ".M", Submodule: "N...", Path: "README.md"},
+				})
+			},
+		},
+		{
+			name: "commit commits staged changes",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("checkout", "-b", "feature")
+				tr.WriteFile("new.txt", "new\n")
+				tr.Git("add", "new.txt")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"c", "Add", `"new"`, "file"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					messageArg.Name(): []string{"Add", `"new"`, "file"},
+				}},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{`git commit -m "Add \"new\" file" && echo Success!`},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, nil)
+				if diff := cmp.Diff([]string{`Add "new" file`, "Initial commit"}, tr.Log(2)); diff != "" {
+					t.Errorf("Commit produced incorrect log (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "commit refuses protected branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("new.txt", "new\n")
+				tr.Git("add", "new.txt")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"c", "Add", "file"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					messageArg.Name(): []string{"Add", "file"},
+				}},
+				WantStdout: "main is a protected branch; enter a new branch name to commit on instead (or nothing to cancel): \n",
+				WantStderr: "refusing to commit on protected branch main (use --override-protected to override)\n",
+				WantErr:    fmt.Errorf("refusing to commit on protected branch main (use --override-protected to override)"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"Initial commit"}, tr.Log(2)); diff != "" {
+					t.Errorf("Commit produced incorrect log (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "commit on protected branch creates new branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("new.txt", "new\n")
+				tr.Git("add", "new.txt")
+			},
+			stdin: "feature\n",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"c", "Add", "file"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					messageArg.Name(): []string{"Add", "file"},
+				}},
+				WantStdout: "main is a protected branch; enter a new branch name to commit on instead (or nothing to cancel): Switched to new branch feature\n",
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{`git commit -m "Add file" && echo Success!`},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if got := tr.CurrentBranch(); got != "feature" {
+					t.Errorf("Current branch is %q; want %q", got, "feature")
+				}
+				if diff := cmp.Diff([]string{"Add file", "Initial commit"}, tr.Log(2)); diff != "" {
+					t.Errorf("Commit produced incorrect log (-want, +got):\n%s", diff)
+				}
+				if diff := cmp.Diff([]string{"Initial commit"}, tr.Git("log", "--format=%s", "main")); diff != "" {
+					t.Errorf("Commit changed the protected branch (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "amend protected branch with override",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("new.txt", "new\n")
+				tr.Git("add", "new.txt")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"am", "-o"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					overrideProtectedFlag.Name(): true,
+				}},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{"git commit --amend --no-edit"},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, nil)
+				if diff := cmp.Diff([]string{"Initial commit"}, tr.Log(2)); diff != "" {
+					t.Errorf("Amend produced incorrect log (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "checkout new branch",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ch", "-n", "feature"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					branchArg.Name():     "feature",
+					newBranchFlag.Name(): true,
+				}},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{"git checkout -b feature"},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if got := tr.CurrentBranch(); got != "feature" {
+					t.Errorf("Current branch is %q; want %q", got, "feature")
+				}
+			},
+		},
+		{
+			name: "recent lists recently checked out branches",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("checkout", "-b", "deleted")
+				tr.Git("checkout", "-b", "feature")
+				tr.Git("checkout", "-b", "fix")
+				tr.Git("checkout", "feature")
+				tr.Git("branch", "-D", "deleted")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args:          []string{"recent"},
+				SkipDataCheck: true,
+				WantStdout:    "fix\nmain\n",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "checkout switches to the previous branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("checkout", "-b", "feature")
+				tr.Git("checkout", "main")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ch", "-"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					checkoutBranchArg.Name(): "-",
+				}},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{"git checkout -"},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff("feature", tr.CurrentBranch()); diff != "" {
+					t.Errorf("Checkout produced incorrect current branch (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "status shows submodule states",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddSubmodule("lib/one")
+				sub := tr.AddSubmodule("lib/two")
+				sub.WriteFile("new.txt", "new\n")
+				sub.Commit("New commit")
+				tr.Git("-C", "lib/two", "pull")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"s"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{"git status"},
+				},
+			},
+			wantStdout: func(tr *gitrepotest.TestRepo) string {
+				return strings.Join([]string{
+					"Submodules:",
+					fmt.Sprintf("  lib/one: up to date (%s)", shortSHA(tr.Git("-C", "lib/one", "rev-parse", "HEAD")[0])),
+					fmt.Sprintf("  lib/two: new commits (%s)", shortSHA(tr.Git("-C", "lib/two", "rev-parse", "HEAD")[0])),
+					"",
+					"",
+				}, "\n")
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "submodule update initializes submodules",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddSubmodule("lib/sub")
+				tr.Git("submodule", "deinit", "--force", "lib/sub")
+				// Local submodules are disallowed by default.
+				tr.Git("config", "protocol.file.allow", "always")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"sm", "update"},
+				WantExecuteData: &command.ExecuteData{
+					FunctionWrap: true,
+					Executable: []string{
+						createSSHAgentCommand,
+						"git submodule update --init --recursive",
+					},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				subs, err := gitrepo.New(tr.Runner()).Submodules()
+				if err != nil {
+					t.Fatalf("Submodules() returned error: %v", err)
+				}
+				if len(subs) != 1 || subs[0].State != gitrepo.SubmoduleUpToDate {
+					t.Errorf("Submodule update produced incorrect submodules: %v", subs)
+				}
+			},
+		},
+		{
+			name: "ignore appends patterns to .gitignore",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile(".gitignore", "# Logs\n*.log")
+				tr.Commit("Add .gitignore")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ignore", "*.log", "build/", "*.tmp", "build/"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					ignorePatternsArg.Name(): []string{"*.log", "build/", "*.tmp", "build/"},
+				}},
+				WantStdout: strings.Join([]string{
+					"*.log is already in .gitignore",
+					"Ignoring build/ in .gitignore",
+					"Ignoring *.tmp in .gitignore",
+					"Ignoring build/ in .gitignore",
+					"",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantFile(t, tr, ".gitignore", "# Logs\n*.log\nbuild/\n*.tmp\n")
+			},
+		},
+		{
+			name: "ignore adds patterns to the .gitignore at the right level",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("sub/dir/out.txt", "out\n")
+				tr.WriteFile("sub/other.txt", "other\n")
+				tr.WriteFile("root.txt", "root\n")
+			},
+			dir: "sub/dir",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ignore", "*.log", "out.txt", "../other.txt", "../../root.txt"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					ignorePatternsArg.Name(): []string{"*.log", "out.txt", "../other.txt", "../../root.txt"},
+				}},
+				WantStdout: strings.Join([]string{
+					"Ignoring *.log in sub/dir/.gitignore",
+					"Ignoring out.txt in sub/dir/.gitignore",
+					"Ignoring /other.txt in sub/.gitignore",
+					"Ignoring /root.txt in .gitignore",
+					"",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantFile(t, tr, "sub/dir/.gitignore", "*.log\nout.txt\n")
+				wantFile(t, tr, "sub/.gitignore", "/other.txt\n")
+				wantFile(t, tr, ".gitignore", "/root.txt\n")
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Untracked, Path: ".gitignore"},
+					{Type: gitrepo.Untracked, Path: "sub/"},
+				})
+			},
+		},
+		{
+			name: "ignore adds patterns to .git/info/exclude",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("sub/out.txt", "out\n")
+			},
+			dir: "sub",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ignore", "--local", "*.log", "out.txt", "../root/"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					ignoreLocalFlag.Name():   true,
+					ignorePatternsArg.Name(): []string{"*.log", "out.txt", "../root/"},
+				}},
+				WantStdout: strings.Join([]string{
+					"Ignoring /sub/**/*.log in .git/info/exclude",
+					"Ignoring /sub/**/out.txt in .git/info/exclude",
+					"Ignoring /root/ in .git/info/exclude",
+					"",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if !strings.HasSuffix(tr.ReadFile(".git/info/exclude"), "\n/sub/**/*.log\n/sub/**/out.txt\n/root/\n") {
+					t.Errorf(".git/info/exclude has incorrect contents:\n%s", tr.ReadFile(".git/info/exclude"))
+				}
+				wantStatus(t, tr, nil)
+			},
+		},
+		{
+			name: "ignore fails for patterns outside of the repo",
+			dir:  "sub",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("sub/out.txt", "out\n")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ignore", "../../out.txt"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					ignorePatternsArg.Name(): []string{"../../out.txt"},
+				}},
+				WantErr:    fmt.Errorf(`pattern "../../out.txt" is outside of the repo`),
+				WantStderr: "pattern \"../../out.txt\" is outside of the repo\n",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "ignored lists ignored files with their rules",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile(".gitignore", "*.log\n")
+				tr.WriteFile("sub/.gitignore", "# Build output\nbuild/\n")
+				tr.Commit("Add ignore files")
+				tr.WriteFile(".git/info/exclude", "secret.txt\n")
+				tr.WriteFile("a.log", "a\n")
+				tr.WriteFile("sub/b.log", "b\n")
+				tr.WriteFile("sub/build/out", "out\n")
+				tr.WriteFile("secret.txt", "shh\n")
+			},
+			dir: "sub",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"ignored"},
+				WantStdout: strings.Join([]string{
+					"../a.log       .gitignore:1: *.log",
+					"../secret.txt  .git/info/exclude:1: secret.txt",
+					"b.log          .gitignore:1: *.log",
+					"build/         sub/.gitignore:2: build/",
+					"",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "ignored with no ignored files",
+			etc: &commandtest.ExecuteTestCase{
+				Args:       []string{"ignored"},
+				WantStdout: "No ignored files\n",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "bisect shows the remaining steps",
+			setup: func(tr *gitrepotest.TestRepo) {
+				for i := 1; i <= 8; i++ {
+					tr.WriteFile("file.txt", fmt.Sprintf("%d\n", i))
+					tr.Commit(fmt.Sprintf("Change %d", i))
+				}
+				tr.Git("bisect", "start", "HEAD", "HEAD~7")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"bs"},
+			},
+			wantStdout: func(tr *gitrepotest.TestRepo) string {
+				return strings.Join([]string{
+					fmt.Sprintf("Testing %s: 3 commits left to test after this (roughly 2 steps)", tr.Git("rev-parse", "--short=7", "HEAD")[0]),
+					fmt.Sprintf("  Bad: %s", tr.Git("rev-parse", "--short=7", "refs/bisect/bad")[0]),
+					fmt.Sprintf("  Good: %s", tr.Git("rev-parse", "--short=7", "main~7")[0]),
+					"",
+				}, "\n")
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "bisect shows the first bad commit",
+			setup: func(tr *gitrepotest.TestRepo) {
+				for i := 1; i <= 3; i++ {
+					tr.WriteFile("file.txt", fmt.Sprintf("%d\n", i))
+					tr.Commit(fmt.Sprintf("Change %d", i))
+				}
+				tr.Git("bisect", "start", "HEAD", "HEAD~2")
+				tr.Git("bisect", "bad")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"bs"},
+			},
+			wantStdout: func(tr *gitrepotest.TestRepo) string {
+				bad := tr.Git("rev-parse", "--short=7", "refs/bisect/bad")[0]
+				return strings.Join([]string{
+					fmt.Sprintf("Found the first bad commit: %s", bad),
+					fmt.Sprintf("  Bad: %s", bad),
+					fmt.Sprintf("  Good: %s", tr.Git("rev-parse", "--short=7", "main~2")[0]),
+					"",
+				}, "\n")
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "clean lists untracked files by default",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile(".gitignore", "*.log\n")
+				tr.Commit("Add .gitignore")
+				tr.WriteFile("untracked.txt", "new\n")
+				tr.WriteFile("build/out.txt", "out\n")
+				tr.WriteFile("debug.log", "log\n")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"clean"},
+				WantStdout: strings.Join([]string{
+					"Would remove:",
+					"  build/",
+					"  untracked.txt",
+					"Run with --force to remove these files",
+					"",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Untracked, Path: "build/"},
+					{Type: gitrepo.Untracked, Path: "untracked.txt"},
+				})
+			},
+		},
+		{
+			name: "clean lists ignored files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile(".gitignore", "*.log\n")
+				tr.Commit("Add .gitignore")
+				tr.WriteFile("untracked.txt", "new\n")
+				tr.WriteFile("build/out.txt", "out\n")
+				tr.WriteFile("build/out.log", "log\n")
+				tr.WriteFile("debug.log", "log\n")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"clean", "--ignored"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					cleanIgnoredFlag.Name(): true,
+				}},
+				WantStdout: strings.Join([]string{
+					"Would remove:",
+					"  build/",
+					"  debug.log",
+					"  untracked.txt",
+					"Run with --force to remove these files",
+					"",
+				}, "\n"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "clean with no untracked files",
+			etc: &commandtest.ExecuteTestCase{
+				Args:       []string{"clean", "-f"},
+				WantData:   &command.Data{Values: map[string]interface{}{cleanForceFlag.Name(): true}},
+				WantStdout: "No untracked files to remove\n",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "clean moves files to the trash",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile(".gitignore", "*.log\n")
+				tr.WriteFile("sub/tracked.txt", "tracked\n")
+				tr.Commit("Add files")
+				tr.WriteFile("sub/untracked.txt", "new\n")
+				tr.WriteFile("sub/build/out.txt", "out\n")
+				tr.WriteFile("sub/debug.log", "log\n")
+				tr.WriteFile("sub/keep.txt", "keep\n")
+				tr.WriteFile("root.txt", "root\n")
+			},
+			dir: "sub",
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"clean", "-f", "-i", "untracked.txt", "build", "debug.log", "../root.txt"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					cleanIgnoredFlag.Name(): true,
+					cleanForceFlag.Name():   true,
+					cleanFilesArg.Name():    []string{"untracked.txt", "build", "debug.log", "../root.txt"},
+				}},
+			},
+			wantStdout: func(tr *gitrepotest.TestRepo) string {
+				return strings.Join([]string{
+					"Removed ../root.txt",
+					"Removed build/",
+					"Removed debug.log",
+					"Removed untracked.txt",
+					fmt.Sprintf("Saved removed files to %s (restore them by moving them back to the root of the repo)", tr.Path(".git/g/trash/20240506-070809.000000")),
+					"",
+				}, "\n")
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Untracked, Path: "sub/keep.txt"},
+				})
+				trash := ".git/g/trash/20240506-070809.000000/"
+				wantFile(t, tr, trash+"root.txt", "root\n")
+				wantFile(t, tr, trash+"sub/untracked.txt", "new\n")
+				wantFile(t, tr, trash+"sub/build/out.txt", "out\n")
+				wantFile(t, tr, trash+"sub/debug.log", "log\n")
+			},
+		},
+		{
+			name: "clean fails for tracked files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("untracked.txt", "new\n")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"clean", "-f", "untracked.txt", "README.md"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					cleanForceFlag.Name(): true,
+					cleanFilesArg.Name():  []string{"untracked.txt", "README.md"},
+				}},
+				WantErr:    fmt.Errorf("README.md is not an untracked file"),
+				WantStderr: "README.md is not an untracked file\n",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Untracked, Path: "untracked.txt"},
+				})
+			},
+		},
+		{
+			name: "push sets upstream for new branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+				tr.Git("checkout", "-b", "feature")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args:       []string{"p"},
+				WantStdout: "git push --set-upstream origin feature\n",
+				WantExecuteData: &command.ExecuteData{
+					FunctionWrap: true,
+					Executable: []string{
+						createSSHAgentCommand,
+						"git push --set-upstream origin feature",
+					},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"origin/feature"}, tr.Git("rev-parse", "--abbrev-ref", "@{upstream}")); diff != "" {
+					t.Errorf("Push set incorrect upstream (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "force push overwrites remote commits",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+				tr.Git("checkout", "-b", "feature")
+				tr.WriteFile("remote.txt", "remote\n")
+				tr.Commit("Remote change")
+				tr.Git("push", "-u", "origin", "feature")
+				tr.Git("reset", "--hard", "HEAD~1")
+				tr.WriteFile("local.txt", "local\n")
+				tr.Commit("Local change")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args:          []string{"p", "-f"},
+				SkipDataCheck: true,
+			},
+			wantStdout: func(tr *gitrepotest.TestRepo) string {
+				return fmt.Sprintf("Force pushing will overwrite 1 commit in origin/feature:\n  %s Remote change\n", tr.Git("rev-parse", "--short", "origin/feature")[0])
+			},
+			wantExecutable: func(tr *gitrepotest.TestRepo) []string {
+				return []string{
+					createSSHAgentCommand,
+					fmt.Sprintf("git push --force-with-lease=feature:%s origin HEAD:feature", tr.Git("rev-parse", "origin/feature")[0]),
+				}
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"Local change", "Initial commit"}, tr.Git("log", "--format=%s", "origin/feature")); diff != "" {
+					t.Errorf("Force push produced incorrect remote log (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "force push refuses default branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+				tr.Git("push", "-u", "origin", "main")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args:          []string{"p", "-f"},
+				SkipDataCheck: true,
+				WantStderr:    "refusing to force push protected branch main\n",
+				WantErr:       fmt.Errorf("refusing to force push protected branch main"),
+				WantExecuteData: &command.ExecuteData{
+					FunctionWrap: true,
+					Executable:   []string{createSSHAgentCommand},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "force push refuses default branch of a differently named local branch",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+				tr.Git("push", "-u", "origin", "main")
+				tr.Git("checkout", "-b", "mywork", "--track", "origin/main")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args:          []string{"p", "-f"},
+				SkipDataCheck: true,
+				WantStderr:    "refusing to force push protected branch main\n",
+				WantErr:       fmt.Errorf("refusing to force push protected branch main"),
+				WantExecuteData: &command.ExecuteData{
+					FunctionWrap: true,
+					Executable:   []string{createSSHAgentCommand},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "grep searches a ref",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("sub/a.txt", "TODO: old\n")
+				tr.Commit("Add TODO")
+				tr.Git("tag", "v1")
+				tr.WriteFile("sub/a.txt", "TODO: new\n")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args:          []string{"gr", "TODO", "--ref", "v1"},
+				SkipDataCheck: true,
+				WantStdout:    "sub/a.txt:1:TODO: old\n",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "grep in a ref fails for an invalid pattern",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("a.txt", "abc\n")
+				tr.Commit("Add a")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args:          []string{"gr", "a[", "--ref", "HEAD"},
+				SkipDataCheck: true,
+				WantStderr:    "failed to grep HEAD: failed to run \"git grep -n -I -e \\\"a[\\\" HEAD --\": exit status 128: fatal: -e option, 'a[': Invalid regular expression\n",
+				WantErr:       fmt.Errorf("failed to grep HEAD: failed to run \"git grep -n -I -e \\\"a[\\\" HEAD --\": exit status 128: fatal: -e option, 'a[': Invalid regular expression"),
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "pull fails without upstream",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("checkout", "-b", "feature")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args:       []string{"l"},
+				WantStderr: "feature has no upstream branch (run `g p` to push it and set one)\n",
+				WantErr:    fmt.Errorf("feature has no upstream branch (run `g p` to push it and set one)"),
+				WantExecuteData: &command.ExecuteData{
+					FunctionWrap: true,
+					Executable:   []string{createSSHAgentCommand},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {},
+		},
+		{
+			name: "pull rebases onto upstream",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddRemote("origin")
+				tr.Git("push", "-u", "origin", "main")
+				tr.WriteFile("upstream.txt", "upstream\n")
+				tr.Commit("Upstream change")
+				tr.Git("push")
+				tr.Git("reset", "--hard", "HEAD~1")
+				tr.WriteFile("local.txt", "local\n")
+				tr.Commit("Local change")
+			},
+			etc: &commandtest.ExecuteTestCase{
+				Args: []string{"l", "--rebase"},
+				WantData: &command.Data{Values: map[string]interface{}{
+					pullRebaseFlag.Name(): true,
+				}},
+				WantExecuteData: &command.ExecuteData{
+					FunctionWrap: true,
+					Executable: []string{
+						createSSHAgentCommand,
+						"git pull --rebase",
+					},
+				},
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"Local change", "Upstream change", "Initial commit"}, tr.Log(5)); diff != "" {
+					t.Errorf("Pull produced incorrect log (-want, +got):\n%s", diff)
+				}
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tr := gitrepotest.NewWithCommit(t)
+			if test.setup != nil {
+				test.setup(tr)
+			}
+			stubRepo(t, tr)
+			if test.dir != "" {
+				commandtest.StubValue(t, &newRunner, func(*command.Data) gitrepo.Runner {
+					return tr.SubdirRunner(test.dir)
+				})
+			}
+			commandtest.StubValue(t, &stdin, io.Reader(strings.NewReader(test.stdin)))
+			commandtest.StubValue(t, &now, func() time.Time {
+				return time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+			})
+			if test.wantStdout != nil {
+				test.etc.WantStdout = test.wantStdout(tr)
+			}
+			if test.wantExecutable != nil {
+				test.etc.WantExecuteData = &command.ExecuteData{
+					FunctionWrap: true,
+					Executable:   test.wantExecutable(tr),
+				}
+			}
+
+			test.etc.Node = CLI().Node()
+			commandertest.ExecuteTest(t, test.etc)
+			// The executable isn't run if the command fails.
+			if test.etc.WantExecuteData != nil && test.etc.WantErr == nil {
+				tr.Exec(test.etc.WantExecuteData.Executable...)
+			}
+			test.check(t, tr)
+		})
+	}
+}
+
+func TestAutocompleteWithRealRepo(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		setup func(*gitrepotest.TestRepo)
+		// dir is the subdirectory of the repo to complete in.
+		dir  string
+		args string
+		want []string
+	}{
+		{
+			name: "add completes changed and untracked files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("README.md", "changed\n")
+				tr.WriteFile("untracked.txt", "new\n")
+				tr.WriteFile("staged.txt", "new\n")
+				tr.Git("add", "staged.txt")
+			},
+			args: "cmd a ",
+			want: []string{"README.md", "untracked.txt"},
+		},
+		{
+			name: "add completes files with spaces",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("some file.txt", "new\n")
+				tr.WriteFile("some other.txt", "new\n")
+			},
+			args: "cmd a some",
+			want: []string{`some\ file.txt`, `some\ other.txt`},
+		},
+		{
+			name: "add completes conflicted files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Conflict("README.md")
+			},
+			args: "cmd a ",
+			want: []string{"README.md"},
+		},
+		{
+			name: "add completes directories when there are many files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				for i := 0; i <= directoryCompletionThreshold; i++ {
+					tr.WriteFile(fmt.Sprintf("src/pkg/f%d.go", i), "package pkg\n")
+				}
+				tr.Commit("Add package")
+				for i := 0; i <= directoryCompletionThreshold; i++ {
+					tr.WriteFile(fmt.Sprintf("src/pkg/f%d.go", i), "package pkg // changed\n")
+				}
+				tr.WriteFile("README.md", "changed\n")
+			},
+			args: "cmd a ",
+			want: []string{"README.md", "src/"},
+		},
+		{
+			name: "add excludes submodules with only content changes",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddSubmodule("lib/one")
+				sub := tr.AddSubmodule("lib/two")
+				tr.WriteFile("lib/one/untracked.txt", "new\n")
+				sub.WriteFile("new.txt", "new\n")
+				sub.Commit("New commit")
+				tr.Git("-C", "lib/two", "pull")
+			},
+			args: "cmd a ",
+			want: []string{"lib/two"},
+		},
+		{
+			name: "status completes all changed submodules",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddSubmodule("lib/one")
+				sub := tr.AddSubmodule("lib/two")
+				tr.WriteFile("lib/one/untracked.txt", "new\n")
+				sub.WriteFile("new.txt", "new\n")
+				sub.Commit("New commit")
+				tr.Git("-C", "lib/two", "pull")
+			},
+			args: "cmd s ",
+			want: []string{"lib/one", "lib/two"},
+		},
+		{
+			name: "submodule update completes submodules",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.AddSubmodule("lib/one")
+				tr.AddSubmodule("vendor/two")
+			},
+			args: "cmd sm update ",
+			want: []string{"lib/one", "vendor/two"},
+		},
+		{
+			name: "ignore completes untracked files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("sub/tracked.txt", "tracked\n")
+				tr.Commit("Add sub")
+				tr.WriteFile("README.md", "changed\n")
+				tr.WriteFile("untracked.txt", "new\n")
+				tr.WriteFile("build/out.txt", "out\n")
+				tr.WriteFile("sub/new.txt", "new\n")
+			},
+			dir:  "sub",
+			args: "cmd ignore ",
+			want: []string{"../build/", "../untracked.txt", "new.txt"},
+		},
+		{
+			name: "ignore doesn't complete the current directory",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("untracked.txt", "new\n")
+				tr.WriteFile("sub/new.txt", "new\n")
+			},
+			dir:  "sub",
+			args: "cmd ignore ",
+			want: []string{"../untracked.txt"},
+		},
+		{
+			name: "clean completes untracked files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile(".gitignore", "*.log\n")
+				tr.Commit("Add .gitignore")
+				tr.WriteFile("README.md", "changed\n")
+				tr.WriteFile("untracked.txt", "new\n")
+				tr.WriteFile("debug.log", "log\n")
+			},
+			args: "cmd clean ",
+			want: []string{"untracked.txt"},
+		},
+		{
+			name: "clean completes ignored files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile(".gitignore", "*.log\n")
+				tr.Commit("Add .gitignore")
+				tr.WriteFile("README.md", "changed\n")
+				tr.WriteFile("untracked.txt", "new\n")
+				tr.WriteFile("debug.log", "log\n")
+			},
+			args: "cmd clean -i ",
+			want: []string{"debug.log", "untracked.txt"},
+		},
+		{
+			name: "blame completes tracked files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("sub/a.go", "package sub\n")
+				tr.WriteFile("other/b.go", "package other\n")
+				tr.Commit("Add files")
+				tr.WriteFile("sub/untracked.go", "package sub\n")
+			},
+			dir:  "sub",
+			args: "cmd bl ",
+			want: []string{"../README.md", "../other/b.go", "a.go"},
+		},
+		{
+			name: "blame completes tracked files case insensitively",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("Makefile", "all:\n")
+				tr.Commit("Add files")
+			},
+			args: "cmd bl m",
+			want: []string{"Makefile"},
+		},
+		{
+			name: "undo add completes renamed files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("mv", "README.md", "NEW README.md")
+				tr.WriteFile("untracked.txt", "new\n")
+			},
+			args: "cmd ua ",
+			want: []string{`NEW\ README.md`},
+		},
+		{
+			name: "diff completes unstaged files",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("README.md", "changed\n")
+				tr.WriteFile("untracked.txt", "new\n")
+			},
+			args: "cmd d ",
+			want: []string{"README.md"},
+		},
+		{
+			name: "checkout completes other branches",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("branch", "feature")
+				tr.Git("branch", "fix")
+			},
+			args: "cmd ch f",
+			want: []string{"feature", "fix"},
+		},
+		{
+			name: "checkout completes recent branches first",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("branch", "old")
+				tr.Git("checkout", "-b", "feature")
+				tr.Git("checkout", "-b", "fix")
+				tr.Git("checkout", "main")
+			},
+			args: "cmd ch ",
+			want: []string{"feature", "fix"},
+		},
+		{
+			name: "rename completes other branches",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("branch", "feature")
+			},
+			args: "cmd bmv ",
+			want: []string{"feature"},
+		},
+		{
+			name: "add completes paths relative to a subdirectory",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.Git("config", "status.relativePaths", "false")
+				tr.WriteFile("README.md", "changed\n")
+				tr.WriteFile("sub/dir/a.txt", "a\n")
+				tr.Git("add", "sub/dir/a.txt")
+				tr.WriteFile("sub/dir/a.txt", "changed\n")
+				tr.WriteFile("sub/b.txt", "b\n")
+			},
+			dir:  "sub",
+			args: "cmd a ",
+			want: []string{"../README.md", "b.txt", "dir/a.txt"},
+		},
+		{
+			name: "diff completes paths relative to a subdirectory",
+			setup: func(tr *gitrepotest.TestRepo) {
+				tr.WriteFile("sub/a.txt", "a\n")
+				tr.Commit("Add file")
+				tr.WriteFile("README.md", "changed\n")
+				tr.WriteFile("sub/a.txt", "changed\n")
+			},
+			dir:  "sub",
+			args: "cmd d ",
+			want: []string{"../README.md", "a.txt"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tr := gitrepotest.NewWithCommit(t)
+			if test.setup != nil {
+				test.setup(tr)
+			}
+			stubRepo(t, tr)
+			if test.dir != "" {
+				commandtest.StubValue(t, &newRunner, func(*command.Data) gitrepo.Runner {
+					return tr.SubdirRunner(test.dir)
+				})
+			}
+
+			commandertest.AutocompleteTest(t, &commandtest.CompleteTestCase{
+				Node:          CLI().Node(),
+				Args:          test.args,
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: test.want,
+				},
+			})
+		})
+	}
+}
+
+func TestCompletionCacheWithRealRepo(t *testing.T) {
+	tr := gitrepotest.NewWithCommit(t)
+	stubRepo(t, tr)
+	cacheDir := t.TempDir()
+	commandtest.StubValue(t, &completionCacheDir, func() (string, error) {
+		return cacheDir, nil
+	})
+	g := &git{CompletionCache: true}
+
+	wantCompletion := func(want []string) {
+		t.Helper()
+		commandertest.AutocompleteTest(t, &commandtest.CompleteTestCase{
+			Node:          g.Node(),
+			Args:          "cmd a ",
+			SkipDataCheck: true,
+			Want: &command.Autocompletion{
+				Suggestions: want,
+			},
+		})
+	}
+
+	tr.WriteFile("one.txt", "1\n")
+	wantCompletion([]string{"one.txt"})
+
+	// New files don't change the index, so the cached status is used.
+	tr.WriteFile("two.txt", "2\n")
+	wantCompletion([]string{"one.txt"})
+
+	// Commands that modify the index clear the cache (even before they're run).
+	commandertest.ExecuteTest(t, &commandtest.ExecuteTestCase{
+		Node: g.Node(),
+		Args: []string{"a", "one.txt"},
+		WantData: &command.Data{Values: map[string]interface{}{
+			filesArg.Name(): []string{"one.txt"},
+		}},
+		WantExecuteData: &command.ExecuteData{
+			Executable: []string{"git add one.txt"},
+		},
+	})
+	wantCompletion([]string{"one.txt", "two.txt"})
+
+	// Index changes invalidate the cache.
+	tr.WriteFile("three.txt", "3\n")
+	tr.Git("add", "one.txt")
+	wantCompletion([]string{"three.txt", "two.txt"})
+}
+
+func wantStatus(t *testing.T, tr *gitrepotest.TestRepo, want []*gitrepo.StatusEntry) {
+	t.Helper()
+	if diff := cmp.Diff(want, tr.Status()); diff != "" {
+		t.Errorf("Repo has incorrect status (-want, +got):\n%s", diff)
+	}
+}
+
+// wantFile verifies the contents of a file in the repo.
+func wantFile(t *testing.T, tr *gitrepotest.TestRepo, path, want string) {
+	t.Helper()
+	if diff := cmp.Diff(want, tr.ReadFile(path)); diff != "" {
+		t.Errorf("%s has incorrect contents (-want, +got):\n%s", path, diff)
+	}
+}
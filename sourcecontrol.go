@@ -1,6 +1,7 @@
 package sourcecontrol
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"github.com/leep-frog/command/command"
 	"github.com/leep-frog/command/commander"
 	"github.com/leep-frog/command/sourcerer"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
@@ -44,8 +46,9 @@ const (
 func wCmd(s string) string {
 	return strings.Join([]string{
 		s,
-		// Windows escapes quotes with backtick, hence why '%q' alone isn't sufficient here
-		fmt.Sprintf("if (!$?) { throw %q }", fmt.Sprintf("Command failed: %s", strings.ReplaceAll(s, `"`, "'"))),
+		// The message is single-quoted so PowerShell doesn't expand anything in
+		// the command (single quotes are escaped by doubling them).
+		fmt.Sprintf("if (!$?) { throw '%s' }", strings.ReplaceAll(fmt.Sprintf("Command failed: %s", s), "'", "''")),
 	}, "\n")
 }
 
@@ -68,19 +71,18 @@ var (
 	// The two dots represent [file state in the cache (e.g. added/green), file state not in the cache (red file)]
 	redFileCompleter            = PrefixCompleter[[]string](true, regexp.MustCompile(`^.[^\.]$`))
 	greenFileCompleter          = PrefixCompleter[[]string](false, regexp.MustCompile(`^[^\.].$`))
-	redFileCompleterNoDeletes   = commander.ShellCommandCompleterWithOpts[[]string](&command.Completion{Distinct: true, CaseInsensitive: true}, "git", "diff", "--name-only", "--relative")
-	greenFileCompleterNoDeletes = commander.ShellCommandCompleterWithOpts[[]string](&command.Completion{Distinct: true, CaseInsensitive: true}, "git", "diff", "--cached", "--name-only", "--relative")
+	redFileCompleterNoDeletes   = diffFileCompleter[[]string](false)
+	greenFileCompleterNoDeletes = diffFileCompleter[[]string](true)
 
 	filesArg         = commander.ListArg[string]("FILES", "Files to add", 0, command.UnboundedList, redFileCompleter)
 	allFileCompleter = PrefixCompleter[[]string](true, regexp.MustCompile(".*"))
-	statusFilesArg   = commander.ListArg[string]("FILES", "Files to add", 0, command.UnboundedList, allFileCompleter)
-	repoName         = &commander.ShellCommand[string]{
-		ArgName:     "REPO",
-		CommandName: "git",
-		Args: []string{
-			"config",
-			"--get",
-			"remote.origin.url",
+	// Status is also useful for submodules with changes inside them.
+	statusFileCompleter = statusCompleter[[]string](true, true, regexp.MustCompile(".*"))
+	statusFilesArg      = commander.ListArg[string]("FILES", "Files to add", 0, command.UnboundedList, statusFileCompleter)
+	repoName            = &gitArg[string]{
+		name: "REPO",
+		f: func(r *gitrepo.Repo) (string, error) {
+			return r.RemoteURL("origin")
 		},
 	}
 	defRepoArg     = commander.Arg[string]("DEFAULT_BRANCH", "Default branch for this git repo")
@@ -110,77 +112,229 @@ var (
 		0, command.UnboundedList,
 		allFileCompleter,
 	)
-	pushUpstreamFlag = commander.BoolFlag("upstream", 'u', "If set, push branch to upstream")
-	currentBranchArg = &commander.ShellCommand[string]{
-		ArgName:     "CURRENT_BRANCH",
+	currentBranchArg = &gitArg[string]{
+		name:              "CURRENT_BRANCH",
+		f:                 (*gitrepo.Repo).CurrentBranch,
+		dontRunOnComplete: true,
+	}
+)
+
+// dataRunner is a `gitrepo.Runner` that runs git with `commander.ShellCommand`
+// so that commands can be stubbed in tests.
+type dataRunner struct {
+	d *command.Data
+}
+
+func (dr *dataRunner) Run(args ...string) ([]string, error) {
+	// ShellCommand trims whitespace from each line, so the raw output is
+	// collected instead (leading whitespace is significant in diffs).
+	var out bytes.Buffer
+	sc := &commander.ShellCommand[[]string]{
 		CommandName: "git",
-		Args: []string{
-			"rev-parse",
-			"--abbrev-ref",
-			"HEAD",
+		Args:        args,
+		OutputStreamProcessor: func(o command.Output, d *command.Data, b []byte) error {
+			_, err := out.Write(b)
+			return err
 		},
-		DontRunOnComplete: true,
+	}
+	if _, err := sc.Run(nil, dr.d); err != nil {
+		return nil, err
+	}
+	return gitrepo.SplitLines(out.String()), nil
+}
+
+var (
+	// newRunner returns the runner used for git operations. It is a variable
+	// so tests can run operations against a real repo.
+	newRunner = func(d *command.Data) gitrepo.Runner {
+		return &dataRunner{d}
 	}
 )
 
+func gitRepo(d *command.Data) *gitrepo.Repo {
+	return gitrepo.New(newRunner(d))
+}
+
+// gitArg is a `command.Processor` that stores the result of a git operation
+// in `command.Data`.
+type gitArg[T any] struct {
+	name string
+	f    func(*gitrepo.Repo) (T, error)
+	// dontRunOnComplete indicates whether or not the operation should be run
+	// when completing a command arg.
+	dontRunOnComplete bool
+}
+
+func (ga *gitArg[T]) Name() string {
+	return ga.name
+}
+
+func (ga *gitArg[T]) Get(d *command.Data) T {
+	return command.GetData[T](d, ga.name)
+}
+
+// Run runs the git operation without storing the result.
+func (ga *gitArg[T]) Run(d *command.Data) (T, error) {
+	return ga.f(gitRepo(d))
+}
+
+func (ga *gitArg[T]) set(d *command.Data) error {
+	v, err := ga.Run(d)
+	if err != nil {
+		return err
+	}
+	d.Set(ga.name, v)
+	return nil
+}
+
+func (ga *gitArg[T]) Execute(i *command.Input, o command.Output, d *command.Data, ed *command.ExecuteData) error {
+	return o.Err(ga.set(d))
+}
+
+func (ga *gitArg[T]) Complete(i *command.Input, d *command.Data) (*command.Completion, error) {
+	if ga.dontRunOnComplete {
+		return nil, nil
+	}
+	return nil, ga.set(d)
+}
+
+func (ga *gitArg[T]) Usage(*command.Input, *command.Data, *command.Usage) error {
+	return nil
+}
+
+// commandString returns the provided git command as a string that can be run
+// in the current OS's shell.
+func commandString(c *gitrepo.Command) string {
+	if sourcerer.CurrentOS.Name() == "windows" {
+		return c.StringFor(gitrepo.PowerShell)
+	}
+	return c.String()
+}
+
+// executableCommands returns a processor that adds the provided git commands
+// to the executable.
+func executableCommands(cmds ...*gitrepo.Command) command.Processor {
+	return commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+		var sl []string
+		for _, c := range cmds {
+			sl = append(sl, commandString(c))
+		}
+		return sl, nil
+	})
+}
+
 func CLI() *git {
 	return &git{}
 }
 
 func BranchCompleter() commander.Completer[string] {
-	return commander.CompleterFromFunc(func(s string, d *command.Data) (*command.Completion, error) {
-		c, err := commander.ShellCommandCompleter[string]("git", "branch", "--list").Complete(s, d)
-		if c == nil || err != nil {
-			return c, err
+	return branchCompleter[string]()
+}
+
+// branchCompleter completes branches other than the current one.
+func branchCompleter[T any]() commander.Completer[T] {
+	return commander.CompleterFromFunc(func(t T, d *command.Data) (*command.Completion, error) {
+		branches, err := gitRepo(d).Branches()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches: %v", err)
 		}
 
 		var r []string
-		for _, s := range c.Suggestions {
-			if !strings.Contains(s, "*") {
-				r = append(r, strings.TrimSpace(s))
+		for _, b := range branches {
+			if !b.Current {
+				r = append(r, b.Name)
 			}
 		}
-		c.Suggestions = r
-		return c, nil
+		return matchCompletion(t, d, &command.Completion{
+			Suggestions: r,
+		}), nil
 	})
 }
 
-func GitAliasers() sourcerer.Option {
-	return sourcerer.Aliasers(map[string][]string{
-		"gp": {"g", "p"},
-		// Don't include 'gl' since that is an alias of goleep
-		"gpl":  {"g", "pl"},
-		"gs":   {"g", "s"},
-		"guco": {"g", "uco"},
-		"gb":   {"g", "b"},
-		"gc":   {"g", "c"},
-		"gcnv": {"g", "c", "-n"},
-		"cm":   {"g", "m"},
-		"gcb":  {"g", "ch"},
-		"gnb":  {"g", "ch", "-n"},
-		"gbn":  {"g", "ch", "-n"},
-		"gmm":  {"g", "mm"},
-		"mm":   {"g", "mm"},
-		"gcp":  {"g", "cp"},
-		"gd":   {"g", "d"},
-		"gdm":  {"g", "d", "-m"},
-		"ga":   {"g", "a"},
-		"guc":  {"g", "uc"},
-		"gua":  {"g", "ua"},
-		"ch":   {"g", "ch"},
-		"sq":   {"g", "q"},
-		"gbd":  {"g", "bd"},
-		"glg":  {"g", "lg"},
-		"gam":  {"g", "am"},
-		"gop":  {"g", "op"},
-		"gush": {"g", "ush"},
+const (
+	// recentCommitCompletions is the number of recent commits suggested by
+	// `refCompleter`.
+	recentCommitCompletions = 20
+)
+
+// refCompleter completes branches and tags (and HEAD and recent commits if
+// `commits` is true).
+func refCompleter[T any](commits bool) commander.Completer[T] {
+	return commander.CompleterFromFunc(func(t T, d *command.Data) (*command.Completion, error) {
+		repo := gitRepo(d)
+		branches, err := repo.Branches()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches: %v", err)
+		}
+		tags, err := repo.Tags()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %v", err)
+		}
+
+		var r []string
+		for _, b := range branches {
+			r = append(r, b.Name)
+		}
+		r = append(r, tags...)
+		if commits {
+			shas, err := repo.RecentCommits(recentCommitCompletions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list commits: %v", err)
+			}
+			r = append(append(r, "HEAD"), shas...)
+		}
+		return matchCompletion(t, d, &command.Completion{
+			Suggestions: r,
+		}), nil
+	})
+}
+
+// diffFileCompleter completes files with unstaged (or staged, if `cached` is true) changes.
+func diffFileCompleter[T any](cached bool) commander.Completer[T] {
+	return commander.CompleterFromFunc(func(t T, d *command.Data) (*command.Completion, error) {
+		repo := completionRepo(d)
+		files, err := repo.DiffFiles(cached)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diff files: %v", err)
+		}
+		if files, err = repo.RelativePaths(files); err != nil {
+			return nil, fmt.Errorf("failed to get relative paths: %v", err)
+		}
+		return fileCompletion(t, d, &command.Completion{
+			Suggestions:     files,
+			Distinct:        true,
+			CaseInsensitive: true,
+		}), nil
 	})
 }
 
 type git struct {
-	MainBranches  map[string]string
-	DefaultBranch string
-	changed       bool
+	MainBranches   map[string]string
+	DefaultBranch  string
+	CommitPolicies map[string]*gitrepo.CommitPolicy
+	TicketPatterns map[string]string
+	// Roster is a map from teammate name to email
+	Roster map[string]string
+	// Aliases is a map from user-defined alias to `g` args
+	Aliases map[string][]string
+	// DisabledAliases is the set of built-in aliases that are disabled
+	DisabledAliases map[string]bool
+	// SyncStrategies is a map from repo to how `g sync` integrates the default branch
+	SyncStrategies map[string]string
+	// PullModes is a map from repo to how `g l` integrates the upstream branch
+	PullModes map[string]string
+	// ProtectedBranches is a map from repo to the branches (in addition to the
+	// default branch) that shouldn't be rewritten
+	ProtectedBranches map[string][]string
+	// FuzzyCompletion is whether or not file and branch completions use fuzzy
+	// (subsequence) matching instead of prefix matching
+	FuzzyCompletion bool
+	// CompletionCache is whether or not file completions cache git output
+	CompletionCache bool
+	// BlameIgnoreRevs is a map from repo to the file (relative to the root of
+	// the repo) of commits that `g bl` ignores
+	BlameIgnoreRevs map[string]string
+	changed         bool
 }
 
 func (g *git) Changed() bool {
@@ -205,19 +359,31 @@ func (g *git) GetDefaultBranch(d *command.Data) string {
 	return g.DefaultBranch
 }
 
+func (g *git) showMainBranches(o command.Output) {
+	if len(g.DefaultBranch) == 0 {
+		o.Stdoutln("No global default branch set; using", DefaultDefaultBranch)
+	} else {
+		o.Stdoutln("Global default branch:", g.DefaultBranch)
+	}
+
+	keys := maps.Keys(g.MainBranches)
+	slices.Sort(keys)
+	for _, k := range keys {
+		o.Stdoutf("%s: %s\n", k, g.MainBranches[k])
+	}
+}
+
 func PrefixCompleter[T any](includeUnknown bool, prefixCodes ...*regexp.Regexp) commander.Completer[T] {
+	return statusCompleter[T](includeUnknown, false, prefixCodes...)
+}
+
+// statusCompleter completes files whose status matches one of the prefix
+// codes. Submodules whose only changes are inside the submodule (rather than
+// to its commit) are only included if `includeSubmoduleContent` is true.
+func statusCompleter[T any](includeUnknown, includeSubmoduleContent bool, prefixCodes ...*regexp.Regexp) commander.Completer[T] {
 	return commander.CompleterFromFunc(func(t T, d *command.Data) (*command.Completion, error) {
-		// prefixRegex := regexp.MustCompile(prefixCode)
-		bc := &commander.ShellCommand[[]string]{
-			ArgName:     "opts",
-			CommandName: "git",
-			Args: []string{
-				"status",
-				// Note: this requires that `git config status.relativePaths true`
-				"--porcelain=v2",
-			},
-		}
-		results, err := bc.Run(nil, d)
+		repo := completionRepo(d)
+		entries, err := repo.Status()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get git status: %v", err)
 		}
@@ -231,64 +397,75 @@ func PrefixCompleter[T any](includeUnknown bool, prefixCodes ...*regexp.Regexp)
 			has[s] = true
 			suggestions = append(suggestions, s)
 		}
-		for _, result := range results {
-			// Format is the following for tracked files
-			// 1 .M ... 100644 100644 100644 e1548292489441c42682f38f2590e24d66a8587a e1548292489441c42682f38f2590e24d66a8587a sourcecontrol.go
-
-			// Format is the following for untracked files
-			// ? new_file.go
-
-			parts := strings.Split(result, " ")
+		for _, e := range entries {
+			// Changes inside a submodule (rather than to its commit) can't be
+			// staged or discarded from this repo.
+			if !includeSubmoduleContent && e.IsSubmodule() && !e.SubmoduleCommitChanged() && e.XY[0] == '.' {
+				continue
+			}
 
-			// Check if it's an untracked file
-			if parts[0] == "?" {
-				file := strings.Join(parts[1:], " ")
+			if e.Type == gitrepo.Untracked {
 				if includeUnknown {
-					addSuggesteion(file)
+					addSuggesteion(e.Path)
 				}
 				continue
 			}
 
-			// if file has a space in the name, we need to rejoin int
-			file := strings.Join(parts[8:], " ")
 			for _, rgx := range prefixCodes {
-				if rgx.MatchString(parts[1]) {
-					addSuggesteion(file)
+				if rgx.MatchString(e.XY) {
+					addSuggesteion(e.Path)
 					break
 				}
 			}
 		}
-		return &command.Completion{
+		// Status paths are relative to the root of the repo.
+		if suggestions, err = repo.RelativePaths(suggestions); err != nil {
+			return nil, fmt.Errorf("failed to get relative paths: %v", err)
+		}
+		return fileCompletion(t, d, &command.Completion{
 			Distinct:        true,
 			Suggestions:     suggestions,
 			CaseInsensitive: true,
-		}, nil
+		}), nil
 	})
 }
 
 func (g *git) Node() command.Node {
-	return &commander.BranchNode{
+	commitMessageArg := g.commitMessageArg()
+	coAuthorFlag := g.coAuthorFlag()
+	return commander.SerialNodes(&completionConfig{g}, &commander.BranchNode{
 		Branches: map[string]command.Node{
+			// Aliases
+			"alias": commander.SerialNodes(
+				commander.Description("Alias settings"),
+				g.aliasNode(),
+			),
+
 			// Configs
 			"cfg": commander.SerialNodes(
 				commander.Description("Config settings"),
 				&commander.BranchNode{
+					Default: commander.SerialNodes(
+						&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+							g.showMainBranches(o)
+							g.showCommitPolicies(o)
+							g.showTicketPatterns(o)
+							g.showRoster(o)
+							g.showSyncStrategies(o)
+							g.showPullModes(o)
+							g.showProtectedBranches(o)
+							g.showFuzzyCompletion(o)
+							g.showCompletionCache(o)
+							g.showBlameIgnoreRevs(o)
+							return nil
+						}},
+					),
 					Branches: map[string]command.Node{
 						"main": &commander.BranchNode{
 							Branches: map[string]command.Node{
 								"show": commander.SerialNodes(
 									&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
-										if len(g.DefaultBranch) == 0 {
-											o.Stdoutln("No global default branch set; using", DefaultDefaultBranch)
-										} else {
-											o.Stdoutln("Global default branch:", g.DefaultBranch)
-										}
-
-										keys := maps.Keys(g.MainBranches)
-										slices.Sort(keys)
-										for _, k := range keys {
-											o.Stdoutf("%s: %s\n", k, g.MainBranches[k])
-										}
+										g.showMainBranches(o)
 										return nil
 									}},
 								),
@@ -340,87 +517,73 @@ func (g *git) Node() command.Node {
 									}},
 								),
 							}},
+						"commit":  g.commitPolicyConfigNode(),
+						"ticket":  g.ticketPatternConfigNode(),
+						"roster":  g.rosterConfigNode(),
+						"sync":    g.syncStrategyConfigNode(),
+						"pull":    g.pullModeConfigNode(),
+						"protect": g.protectedBranchConfigNode(),
+						"fuzzy":   g.fuzzyCompletionConfigNode(),
+						"cache":   g.completionCacheConfigNode(),
+						"blame":   g.blameIgnoreRevsConfigNode(),
 					}},
 			),
 
 			// Simple commands
-			"b": commander.SerialNodes(
-				commander.Description("Branch"),
-				commander.SimpleExecutableProcessor("git branch"),
-			),
-			"l": commander.SerialNodes(
-				commander.Description("Pull"),
-				sshNode,
-				commander.SimpleExecutableProcessor(
-					"git pull",
-				),
-			),
-			"p": commander.SerialNodes(
-				commander.Description("Push"),
-				commander.FlagProcessor(pushUpstreamFlag),
-				commander.IfData(pushUpstreamFlag.Name(), currentBranchArg),
-				sshNode,
-				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					if pushUpstreamFlag.Get(d) {
-						pushCmd := fmt.Sprintf("git push --set-upstream origin %q", currentBranchArg.Get(d))
-						o.Stdoutln(pushCmd)
-						return []string{pushCmd}, nil
-					}
-					return []string{"git push"}, nil
-				}),
-			),
+			"b":      g.branchNode(),
+			"bmv":    g.renameBranchNode(),
+			"recent": recentNode(),
+			"l":      g.pullNode(),
+			"p":      g.pushNode(),
 			"pp": commander.SerialNodes(
 				commander.Description("Pull and push"),
 				sshNode,
 				executableJoinByOS(
-					"git pull",
-					"git push",
+					commandString(gitrepo.Pull(nil)),
+					commandString(gitrepo.Push(nil)),
 				),
 				commander.SimpleExecutableProcessor(),
 			),
+			"sync": g.syncNode(),
 			"sh": commander.SerialNodes(
 				commander.Description("Create ssh-agent"),
 				sshNode,
 			),
 			"uco": commander.SerialNodes(
 				commander.Description("Undo commit"),
-				commander.SimpleExecutableProcessor("git reset HEAD~"),
+				g.clearCompletionCache(),
+				commander.FlagProcessor(overrideProtectedFlag),
+				g.protectedBranchGuard("undo a commit"),
+				executableCommands(gitrepo.UndoCommit()),
 			),
 			"f": commander.SerialNodes(
 				commander.Description("Git fetch"),
-				commander.SimpleExecutableProcessor("git fetch"),
+				executableCommands(gitrepo.Fetch()),
 			),
 			"op": commander.SerialNodes(
 				commander.Description("Git stash pop"),
+				g.clearCompletionCache(),
 				stashArgs,
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					var r []string
-					for _, c := range stashArgs.Get(d) {
-						r = append(r, fmt.Sprintf("%q", c))
-					}
-					return []string{
-						fmt.Sprintf("git stash pop %s", strings.Join(r, " ")),
-					}, nil
+					return []string{commandString(gitrepo.StashPop(stashArgs.Get(d)...))}, nil
 				}),
 			),
 			"ush": commander.SerialNodes(
 				commander.Description("Git stash push"),
+				g.clearCompletionCache(),
 				stashArgs,
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					var r []string
-					for _, c := range stashArgs.Get(d) {
-						r = append(r, fmt.Sprintf("%q", c))
-					}
-					return []string{
-						fmt.Sprintf("git stash push %s", strings.Join(r, " ")),
-					}, nil
+					return []string{commandString(gitrepo.StashPush(stashArgs.Get(d)...))}, nil
 				}),
 			),
 
 			// Complex commands
 			"am": commander.SerialNodes(
 				commander.Description("Git amend"),
-				commander.SimpleExecutableProcessor("git commit --amend --no-edit"),
+				g.clearCompletionCache(),
+				commander.FlagProcessor(overrideProtectedFlag),
+				g.protectedBranchGuard("amend"),
+				executableCommands(gitrepo.Commit(&gitrepo.CommitOptions{Amend: true, NoEdit: true})),
 			),
 			// Git log
 			"lg": commander.SerialNodes(
@@ -433,42 +596,49 @@ func (g *git) Node() command.Node {
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
 					if gitLogDiffFlag.Get(d) {
 						return []string{
-							fmt.Sprintf("git diff HEAD~%d %v", gitLogArg.Get(d), whitespaceFlag.Get(d)),
+							commandString(gitrepo.Diff(&gitrepo.DiffOptions{
+								IgnoreWhitespace: whitespaceFlag.Provided(d),
+								Base:             fmt.Sprintf("HEAD~%d", gitLogArg.Get(d)),
+							})),
 						}, nil
 					}
-					return []string{
-						fmt.Sprintf("git log -n %d", gitLogArg.Get(d)),
-					}, nil
+					return []string{commandString(gitrepo.Log(gitLogArg.Get(d)))}, nil
 				}),
 			),
 			// Checkout main
 			"m": commander.SerialNodes(
 				commander.Description("Checkout main"),
+				g.clearCompletionCache(),
 				repoName,
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					return []string{
-						fmt.Sprintf("git checkout %s", g.GetDefaultBranch(d)),
-					}, nil
+					return []string{commandString(gitrepo.Checkout(g.GetDefaultBranch(d), false))}, nil
 				}),
 			),
 			// Merge main
 			"mm": commander.SerialNodes(
 				commander.Description("Merge main"),
+				g.clearCompletionCache(),
 				repoName,
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					return []string{
-						fmt.Sprintf("git merge %s", g.GetDefaultBranch(d)),
-					}, nil
+					return []string{commandString(gitrepo.Merge(g.GetDefaultBranch(d)))}, nil
 				}),
 			),
 			// Commit
 			"c": commander.SerialNodes(
 				commander.Description("Commit"),
+				g.clearCompletionCache(),
 				commander.FlagProcessor(
 					nvFlag,
 					pushFlag,
+					ignorePolicyFlag,
+					coAuthorFlag,
+					trailerFlag,
+					overrideProtectedFlag,
 				),
-				messageArg,
+				g.commitRepoNode(),
+				g.ticketBranchNode(),
+				commitMessageArg,
+				g.protectedBranchGuard("commit"),
 				commander.If(
 					sshNode,
 					func(i *command.Input, d *command.Data) bool {
@@ -476,17 +646,26 @@ func (g *git) Node() command.Node {
 					},
 				),
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+					msg, err := g.commitMessage(o, d)
+					if err != nil {
+						return nil, err
+					}
+					trailers, err := g.commitTrailers(d)
+					if err != nil {
+						return nil, o.Err(err)
+					}
+					commit := gitrepo.Commit(&gitrepo.CommitOptions{
+						Message:  msg,
+						NoVerify: nvFlag.Provided(d),
+						Trailers: trailers,
+					})
 					r := []string{
 						// Replace quoted newlines with actual newlines
-						strings.ReplaceAll(
-							fmt.Sprintf("git commit %s-m %q", nvFlag.Get(d), strings.Join(messageArg.Get(d), " ")),
-							`\n`,
-							"\n",
-						),
+						strings.ReplaceAll(commandString(commit), `\n`, "\n"),
 					}
 					if pushFlag.Get(d) {
 						r = append(r,
-							"git push",
+							commandString(gitrepo.Push(nil)),
 						)
 					}
 					r = append(r, "echo Success!")
@@ -498,15 +677,36 @@ func (g *git) Node() command.Node {
 			// Commit & push
 			"cp": commander.SerialNodes(
 				commander.Description("Commit and push"),
+				g.clearCompletionCache(),
 				commander.FlagProcessor(
 					nvFlag,
+					ignorePolicyFlag,
+					coAuthorFlag,
+					trailerFlag,
+					overrideProtectedFlag,
 				),
-				messageArg,
+				g.commitRepoNode(),
+				g.ticketBranchNode(),
+				commitMessageArg,
+				g.protectedBranchGuard("commit"),
 				sshNode,
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+					msg, err := g.commitMessage(o, d)
+					if err != nil {
+						return nil, err
+					}
+					trailers, err := g.commitTrailers(d)
+					if err != nil {
+						return nil, o.Err(err)
+					}
+					commit := gitrepo.Commit(&gitrepo.CommitOptions{
+						Message:  msg,
+						NoVerify: nvFlag.Provided(d),
+						Trailers: trailers,
+					})
 					return joinByOS(
-						fmt.Sprintf("git commit %s-m %q", nvFlag.Get(d), strings.Join(messageArg.Get(d), " ")),
-						"git push",
+						commandString(commit),
+						commandString(gitrepo.Push(nil)),
 						"echo Success!",
 					)
 				}),
@@ -538,17 +738,14 @@ func (g *git) Node() command.Node {
 			// Checkout branch
 			"ch": commander.SerialNodes(
 				commander.Description("Checkout new branch"),
+				g.clearCompletionCache(),
 				commander.FlagProcessor(
 					newBranchFlag,
 				),
-				branchArg,
+				checkoutBranchArg,
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					flag := ""
-					if newBranchFlag.Get(d) {
-						flag = "-b "
-					}
 					return []string{
-						fmt.Sprintf("git checkout %s%s", flag, branchArg.Get(d)),
+						commandString(gitrepo.Checkout(checkoutBranchArg.Get(d), newBranchFlag.Get(d))),
 					}, nil
 				}),
 			),
@@ -559,12 +756,8 @@ func (g *git) Node() command.Node {
 				commander.FlagProcessor(forceDelete),
 				branchArg,
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					flag := "-d"
-					if forceDelete.Get(d) {
-						flag = "-D"
-					}
 					return []string{
-						fmt.Sprintf("git branch %s %s", flag, branchArg.Get(d)),
+						commandString(gitrepo.DeleteBranch(branchArg.Get(d), forceDelete.Get(d))),
 					}, nil
 				}),
 			),
@@ -580,61 +773,113 @@ func (g *git) Node() command.Node {
 				diffArgs,
 				repoName,
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					branch := "--"
+					var base string
 					if mainFlag.Get(d) {
-						branch = g.GetDefaultBranch(d)
+						base = g.GetDefaultBranch(d)
 					}
 					if prevCommitFlag.Get(d) {
-						branch = `"$(git rev-parse @~1)"`
+						base = "HEAD~1"
 					}
 					return []string{
-						fmt.Sprintf("git diff %s %s %s", whitespaceFlag.Get(d), branch, strings.Join(diffArgs.Get(d), " ")),
+						commandString(gitrepo.Diff(&gitrepo.DiffOptions{
+							IgnoreWhitespace: whitespaceFlag.Provided(d),
+							Base:             base,
+							Paths:            diffArgs.Get(d),
+						})),
 					}, nil
 				}),
 			),
 
+			// Blame
+			"bl": g.blameNode(),
+
+			// Grep
+			"gr": grepNode(),
+
+			// Bisect
+			"bs": commander.SerialNodes(
+				commander.Description("Bisect"),
+				g.bisectNode(),
+			),
+
 			// Undo change
 			"uc": commander.SerialNodes(
 				commander.Description("Undo change"),
+				g.clearCompletionCache(),
+				commander.FlagProcessor(patchFlag),
 				ucArgs,
-				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					return []string{
-						fmt.Sprintf("git checkout -- %s", strings.Join(ucArgs.Get(d), " ")),
-					}, nil
-				}),
+				commander.IfElse(
+					&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+						return applyHunks(o, d, ucArgs.Get(d), "Discard", false, true, &gitrepo.ApplyOptions{Reverse: true})
+					}},
+					commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+						return []string{commandString(gitrepo.Discard(ucArgs.Get(d)...))}, nil
+					}),
+					func(i *command.Input, d *command.Data) bool {
+						return patchFlag.Get(d)
+					},
+				),
 			),
 
 			// Undo add
 			"ua": commander.SerialNodes(
 				commander.Description("Undo add"),
+				g.clearCompletionCache(),
+				commander.FlagProcessor(patchFlag),
 				uaArgs,
-				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					return []string{
-						fmt.Sprintf("git reset -- %s", strings.Join(ucArgs.Get(d), " ")),
-					}, nil
-				}),
+				commander.IfElse(
+					&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+						return applyHunks(o, d, uaArgs.Get(d), "Unstage", true, false, &gitrepo.ApplyOptions{Cached: true, Reverse: true})
+					}},
+					commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+						return []string{commandString(gitrepo.Unstage(uaArgs.Get(d)...))}, nil
+					}),
+					func(i *command.Input, d *command.Data) bool {
+						return patchFlag.Get(d)
+					},
+				),
 			),
 
 			// Status
 			"s": commander.SerialNodes(
-				commander.Description("Status"),
+				commander.Description("Status (including submodule states)"),
 				statusFilesArg,
+				showSubmodules(true),
 				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					return []string{fmt.Sprintf("git status %s", strings.Join(statusFilesArg.Get(d), " "))}, nil
+					return []string{commandString(gitrepo.NewCommand(append([]string{"status"}, statusFilesArg.Get(d)...)...))}, nil
 				}),
 			),
 
+			// Submodules
+			"sm": commander.SerialNodes(
+				commander.Description("Submodules"),
+				submoduleNode(),
+			),
+
+			// Ignore
+			"ignore":  g.ignoreNode(),
+			"ignored": ignoredNode(),
+
+			// Clean
+			"clean": g.cleanNode(),
+
 			// Add
 			"a": commander.SerialNodes(
 				commander.Description("Add"),
+				g.clearCompletionCache(),
+				commander.FlagProcessor(patchFlag),
 				filesArg,
-				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
-					fs := filesArg.Get(d)
-					if len(fs) == 0 {
-						return []string{"git add ."}, nil
-					}
-					return []string{fmt.Sprintf("git add %s", strings.Join(fs, " "))}, nil
-				}),
+				commander.IfElse(
+					&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+						return applyHunks(o, d, filesArg.Get(d), "Stage", false, false, &gitrepo.ApplyOptions{Cached: true})
+					}},
+					commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+						return []string{commandString(gitrepo.Add(filesArg.Get(d)...))}, nil
+					}),
+					func(i *command.Input, d *command.Data) bool {
+						return patchFlag.Get(d)
+					},
+				),
 			),
 
 			// Rebase
@@ -642,12 +887,12 @@ func (g *git) Node() command.Node {
 				Branches: map[string]command.Node{
 					"a": commander.SerialNodes(
 						commander.Description("Abort"),
-						commander.SimpleExecutableProcessor("git rebase --abort"),
+						executableCommands(gitrepo.RebaseAbort()),
 						commander.EchoExecuteData(),
 					),
 					"c": commander.SerialNodes(
 						commander.Description("Continue"),
-						commander.SimpleExecutableProcessor("git rebase --continue"),
+						executableCommands(gitrepo.RebaseContinue()),
 						commander.EchoExecuteData(),
 					),
 				},
@@ -656,5 +901,5 @@ func (g *git) Node() command.Node {
 		Synonyms: commander.BranchSynonyms(map[string][]string{
 			"l": {"pl"},
 		}),
-	}
+	})
 }
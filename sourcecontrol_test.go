@@ -2,8 +2,10 @@ package sourcecontrol
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/leep-frog/command/command"
@@ -11,6 +13,7 @@ import (
 	"github.com/leep-frog/command/commandtest"
 	"github.com/leep-frog/command/sourcerer"
 	"github.com/leep-frog/functional"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
 	"golang.org/x/exp/slices"
 )
 
@@ -25,6 +28,86 @@ func repoRunContents() *commandtest.RunContents {
 	}
 }
 
+func currentBranchRunContents() *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
+	}
+}
+
+func upstreamRunContents(branch string) *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"rev-parse", "--abbrev-ref", "--symbolic-full-name", branch + "@{upstream}"},
+	}
+}
+
+func aheadBehindRunContents(ref, base string) *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"rev-list", "--left-right", "--count", ref + "..." + base},
+	}
+}
+
+func statusRunContents() *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"-c", "status.relativePaths=false", "status", "--porcelain=v2"},
+	}
+}
+
+func submoduleStatusRunContents() *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"submodule", "status", "--recursive"},
+	}
+}
+
+func diffFilesRunContents() *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"diff", "--name-only"},
+	}
+}
+
+func prefixRunContents() *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"rev-parse", "--show-prefix"},
+	}
+}
+
+func reflogRunContents() *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"reflog", "--format=%gs"},
+	}
+}
+
+func branchInfoRunContents() *commandtest.RunContents {
+	return &commandtest.RunContents{
+		Name: "git",
+		Args: []string{"for-each-ref", "--format=%(refname:short)%00%(HEAD)%00%(committerdate:unix)%00%(authorname)%00%(upstream:short)%00%(upstream:track,nobracket)", "refs/heads"},
+	}
+}
+
+func branchInfoRunResponses() []*commandtest.FakeRun {
+	return []*commandtest.FakeRun{
+		{Stdout: []string{
+			"old\x00 \x0050\x00Some One\x00origin/old\x00gone",
+			"feature\x00*\x00200\x00Some One\x00origin/feature\x00ahead 1, behind 2",
+			"main\x00 \x00100\x00Other One\x00origin/main\x00",
+		}},
+		// RefExists
+		{Stdout: []string{"abc123"}},
+		// Merged branches
+		{Stdout: []string{"main", "old"}},
+		{Stdout: []string{"0\t4"}},
+		{Stdout: []string{"3\t0"}},
+		{Stdout: []string{"0\t0"}},
+	}
+}
+
 func TestExecution(t *testing.T) {
 	type osCheck struct {
 		wantExecutable []string
@@ -33,38 +116,193 @@ func TestExecution(t *testing.T) {
 	u := strings.Join([]string{
 		`┓`,
 		`┃   Add`,
-		`┣━━ a [ FILES ... ]`,
+		`┣━━ a [ FILES ... ] --patch|-p`,
+		`┃`,
+		`┃   List aliases`,
+		`┣━━ alias ┓`,
+		`┃   ┏━━━━━┛`,
+		`┃   ┃`,
+		"┃   ┃   Add an alias for a `g` command",
+		`┃   ┣━━ add ALIAS ARGS [ ARGS ... ] --force|-f`,
+		`┃   ┃`,
+		`┃   ┃   Disable a built-in alias`,
+		`┃   ┣━━ disable ALIAS`,
+		`┃   ┃`,
+		`┃   ┃   Re-enable a disabled built-in alias`,
+		`┃   ┣━━ enable ALIAS`,
+		`┃   ┃`,
+		`┃   ┃   Remove an alias`,
+		`┃   ┗━━ rm ALIAS`,
 		`┃`,
 		`┃   Git amend`,
-		`┣━━ am`,
+		`┣━━ am --override-protected|-o`,
 		`┃`,
-		`┃   Branch`,
-		`┣━━ b`,
+		`┃   List local branches with their last commit, upstream state, and state relative to the default branch`,
+		`┣━━ b --json|-j --date|-d`,
 		`┃`,
 		`┃   Delete branch`,
 		`┣━━ bd BRANCH --force-delete|-f`,
 		`┃`,
+		`┃   Blame a file, showing the commit, author, and relative date that last changed each line`,
+		`┣━━ bl FILE [ LINES ] --whitespace|-w`,
+		`┃`,
+		`┃   Rename a branch (and its remote branch and any config that references it)`,
+		`┣━━ bmv OLD [ NEW ] --push|-p`,
+		`┃`,
+		`┃   Show the bisect status and the remaining steps`,
+		`┣━━ bs ┓`,
+		`┃   ┏━━┛`,
+		`┃   ┃`,
+		`┃   ┃   Mark the current commit (or the provided commits) as bad`,
+		`┃   ┣━━ bad [ COMMITS ... ]`,
+		`┃   ┃`,
+		`┃   ┃   Mark the current commit (or the provided commits) as good`,
+		`┃   ┣━━ good [ COMMITS ... ]`,
+		`┃   ┃`,
+		`┃   ┃   Stop bisecting and check out the original branch`,
+		`┃   ┣━━ reset`,
+		`┃   ┃`,
+		`┃   ┃   Bisect automatically by running a command on each commit`,
+		`┃   ┣━━ run CMD [ CMD ... ]`,
+		`┃   ┃`,
+		`┃   ┃   Skip the current commit (or the provided commits) because it can't be tested`,
+		`┃   ┣━━ skip [ COMMITS ... ]`,
+		`┃   ┃`,
+		`┃   ┃   Start bisecting to find the commit between GOOD and BAD that introduced a bug`,
+		`┃   ┗━━ start BAD [ GOOD ]`,
+		`┃`,
 		`┃   Commit`,
-		`┣━━ c MESSAGE [ MESSAGE ... ] --no-verify|-n --push|-p`,
+		`┣━━ c MESSAGE [ MESSAGE ... ] --no-verify|-n --push|-p --ignore-policy|-i --co|-a CO [ CO ... ] --trailer|-t TRAILER [ TRAILER ... ] --override-protected|-o`,
 		`┃`,
 		`┃   Config settings`,
 		`┣━━ cfg ┓`,
 		`┃   ┏━━━┛`,
 		`┃   ┃`,
-		`┃   ┗━━ main ┓`,
-		`┃       ┏━━━━┛`,
+		`┃   ┣━━ blame ┓`,
+		`┃   ┃   ┏━━━━━┛`,
+		`┃   ┃   ┃`,
+		"┃   ┃   ┃   Set the file of commits for `g bl` to ignore in this repo",
+		`┃   ┃   ┣━━ set IGNORE_REVS_FILE`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Show blame ignore-revs files`,
+		`┃   ┃   ┣━━ show`,
+		`┃   ┃   ┃`,
+		"┃   ┃   ┃   Don't ignore any commits in `g bl` in this repo",
+		`┃   ┃   ┗━━ unset`,
+		`┃   ┃`,
+		`┃   ┣━━ cache ┓`,
+		`┃   ┃   ┏━━━━━┛`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Run git for every file completion`,
+		`┃   ┃   ┣━━ off`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Cache git output used by file completions (useful in large repos)`,
+		`┃   ┃   ┣━━ on`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Show whether the completion cache is enabled`,
+		`┃   ┃   ┗━━ show`,
+		`┃   ┃`,
+		`┃   ┣━━ commit ┓`,
+		`┃   ┃   ┏━━━━━━┛`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Enforce conventional commit messages in this repo`,
+		`┃   ┃   ┣━━ set --types|-t TYPES [ TYPES ... ] --max-subject-length|-l MAX_SUBJECT_LENGTH --require-scope|-r`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Show commit policies`,
+		`┃   ┃   ┣━━ show`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Stop enforcing conventional commit messages in this repo`,
+		`┃   ┃   ┗━━ unset`,
+		`┃   ┃`,
+		`┃   ┣━━ fuzzy ┓`,
+		`┃   ┃   ┏━━━━━┛`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Use prefix matching in file and branch completions`,
+		`┃   ┃   ┣━━ off`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Use fuzzy (subsequence) matching in file and branch completions`,
+		`┃   ┃   ┣━━ on`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Show whether fuzzy completion is enabled`,
+		`┃   ┃   ┗━━ show`,
+		`┃   ┃`,
+		`┃   ┣━━ main ┓`,
+		`┃   ┃   ┏━━━━┛`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┣━━ set DEFAULT_BRANCH --global|-g`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┣━━ show`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┗━━ unset --global|-g`,
+		`┃   ┃`,
+		`┃   ┣━━ protect ┓`,
+		`┃   ┃   ┏━━━━━━━┛`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Protect branches in this repo from commits, amends, and force pushes (the default branch is always protected from force pushes)`,
+		`┃   ┃   ┣━━ add BRANCH [ BRANCH ... ]`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Stop protecting branches in this repo`,
+		`┃   ┃   ┣━━ rm BRANCH [ BRANCH ... ]`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Show protected branches (the default branch is protected from commits if none are set, and always from force pushes)`,
+		`┃   ┃   ┗━━ show`,
+		`┃   ┃`,
+		`┃   ┣━━ pull ┓`,
+		`┃   ┃   ┏━━━━┛`,
+		`┃   ┃   ┃`,
+		"┃   ┃   ┃   Set how `g l` integrates the upstream branch in this repo",
+		`┃   ┃   ┣━━ set MODE`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Show pull modes`,
+		`┃   ┃   ┣━━ show`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Use the default pull mode in this repo`,
+		`┃   ┃   ┗━━ unset`,
+		`┃   ┃`,
+		`┃   ┣━━ roster ┓`,
+		`┃   ┃   ┏━━━━━━┛`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Add a teammate to the co-author roster`,
+		`┃   ┃   ┣━━ add NAME EMAIL`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Remove a teammate from the co-author roster`,
+		`┃   ┃   ┣━━ rm NAME`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Show teammates in the co-author roster`,
+		`┃   ┃   ┗━━ show`,
+		`┃   ┃`,
+		`┃   ┣━━ sync ┓`,
+		`┃   ┃   ┏━━━━┛`,
+		`┃   ┃   ┃`,
+		"┃   ┃   ┃   Set how `g sync` integrates the default branch in this repo",
+		`┃   ┃   ┣━━ set STRATEGY`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Show sync strategies`,
+		`┃   ┃   ┣━━ show`,
+		`┃   ┃   ┃`,
+		`┃   ┃   ┃   Use the default sync strategy in this repo`,
+		`┃   ┃   ┗━━ unset`,
+		`┃   ┃`,
+		`┃   ┗━━ ticket ┓`,
+		`┃       ┏━━━━━━┛`,
 		`┃       ┃`,
-		`┃       ┣━━ set DEFAULT_BRANCH --global|-g`,
+		`┃       ┃   Prefix commit messages with the ticket ID in the branch name`,
+		`┃       ┣━━ set TICKET_REGEX`,
 		`┃       ┃`,
+		`┃       ┃   Show ticket patterns`,
 		`┃       ┣━━ show`,
 		`┃       ┃`,
-		`┃       ┗━━ unset --global|-g`,
+		`┃       ┃   Stop prefixing commit messages with ticket IDs`,
+		`┃       ┗━━ unset`,
 		`┃`,
 		`┃   Checkout new branch`,
 		`┣━━ ch BRANCH --new-branch|-n`,
 		`┃`,
+		`┃   Remove untracked files (only lists them unless --force is provided). Removed files are saved to a trash directory in the repo's git directory`,
+		`┣━━ clean [ FILES ... ] --ignored|-i --force|-f`,
+		`┃`,
 		`┃   Commit and push`,
-		`┣━━ cp MESSAGE [ MESSAGE ... ] --no-verify|-n`,
+		`┣━━ cp MESSAGE [ MESSAGE ... ] --no-verify|-n --ignore-policy|-i --co|-a CO [ CO ... ] --trailer|-t TRAILER [ TRAILER ... ] --override-protected|-o`,
 		`┃`,
 		`┃   Diff`,
 		`┣━━ d [ FILE ... ] --main|-m --commit|-c --whitespace|-w`,
@@ -72,8 +310,17 @@ func TestExecution(t *testing.T) {
 		`┃   Git fetch`,
 		`┣━━ f`,
 		`┃`,
+		"┃   Search tracked files, printing each match as `file:line:text`",
+		`┣━━ gr PATTERN [ PATHS ... ] --ignore-case|-i --word|-w --fixed|-f --extended|-e --ref|-r REF`,
+		`┃`,
+		`┃   Ignore files by adding patterns to the .gitignore in the current directory (or to .git/info/exclude with --local)`,
+		`┣━━ ignore PATTERN [ PATTERN ... ] --local|-l`,
+		`┃`,
+		`┃   List ignored files and the rules that ignore them`,
+		`┣━━ ignored`,
+		`┃`,
 		`┃   Pull`,
-		`┣━━ [l|pl]`,
+		`┣━━ [l|pl] --rebase|-r --ff-only|-f --merge|-m`,
 		`┃`,
 		`┃   Git log`,
 		`┣━━ lg [ N ] --diff|-d --whitespace|-w`,
@@ -87,8 +334,8 @@ func TestExecution(t *testing.T) {
 		`┃   Git stash pop`,
 		`┣━━ op [ STASH_ARGS ... ]`,
 		`┃`,
-		`┃   Push`,
-		`┣━━ p --upstream|-u`,
+		`┃   Push (and set the upstream if the branch doesn't have one)`,
+		`┣━━ p --force|-f`,
 		`┃`,
 		`┃   Pull and push`,
 		`┣━━ pp`,
@@ -102,46 +349,113 @@ func TestExecution(t *testing.T) {
 		`┃   ┃   Continue`,
 		`┃   ┗━━ c`,
 		`┃`,
-		`┃   Status`,
+		`┃   List recently checked out branches (most recent first)`,
+		`┣━━ recent [ N ]`,
+		`┃`,
+		`┃   Status (including submodule states)`,
 		`┣━━ s [ FILES ... ]`,
 		`┃`,
 		`┃   Create ssh-agent`,
 		`┣━━ sh`,
 		`┃`,
+		`┃   Show submodule states`,
+		`┣━━ sm ┓`,
+		`┃   ┏━━┛`,
+		`┃   ┃`,
+		`┃   ┃   Update every submodule to the latest commit of its remote branch (recursively)`,
+		`┃   ┣━━ pull`,
+		`┃   ┃`,
+		`┃   ┃   Sync submodule remote URLs from .gitmodules (recursively)`,
+		`┃   ┣━━ sync [ SUBMODULES ... ]`,
+		`┃   ┃`,
+		`┃   ┃   Initialize and update submodules (recursively)`,
+		`┃   ┗━━ update [ SUBMODULES ... ]`,
+		`┃`,
+		`┃   Fetch, fast-forward the default branch, merge or rebase it into the current branch, and push`,
+		`┣━━ sync --strategy|-s STRATEGY`,
+		`┃`,
 		`┃   Undo add`,
-		`┣━━ ua FILE [ FILE ... ]`,
+		`┣━━ ua FILE [ FILE ... ] --patch|-p`,
 		`┃`,
 		`┃   Undo change`,
-		`┣━━ uc FILE [ FILE ... ]`,
+		`┣━━ uc FILE [ FILE ... ] --patch|-p`,
 		`┃`,
 		`┃   Undo commit`,
-		`┣━━ uco`,
+		`┣━━ uco --override-protected|-o`,
 		`┃`,
 		`┃   Git stash push`,
 		`┗━━ ush [ STASH_ARGS ... ]`,
 		``,
 		`Arguments:`,
-		`  BRANCH: Branch`,
+		`  ALIAS: Name of the alias`,
+		"  ARGS: Args to pass to the `g` command",
+		`  BAD: Commit that has the bug`,
+		`  BRANCH: Branch (or - for the previously checked out branch)`,
+		`  CMD: Command to run on each commit (exit code 0 is good, 125 is skip, and anything else below 128 is bad)`,
+		`  COMMITS: Commits to mark (defaults to the current commit)`,
 		`  DEFAULT_BRANCH: Default branch for this git repo`,
+		`  EMAIL: Email of the teammate`,
+		`    Contains("@")`,
 		`  FILE: Files to un-change`,
 		`  FILES: Files to add`,
+		`  GOOD: Commit that doesn't have the bug (defaults to the merge-base of BAD and the default branch)`,
+		"  IGNORE_REVS_FILE: File (relative to the root of the repo) that lists commits for `g bl` to ignore (e.g. formatting changes)",
+		`  LINES: Lines to blame (START or START:END)`,
+		`    MatchesRegex([^[1-9][0-9]*(:[1-9][0-9]*)?$])`,
 		`  MESSAGE: Commit message`,
-		`  N: Number of git logs to display`,
-		`    Default: 1`,
-		`    NonNegative()`,
+		"  MODE: How `g l` integrates the upstream branch",
+		`    InList([merge rebase ff-only])`,
+		`  N: Number of recent branches to list`,
+		`    Default: 10`,
+		`    Positive()`,
+		`  NAME: Name of the teammate`,
+		`  NEW: New branch name`,
+		`  OLD: Branch to rename (or the new name of the current branch if NEW isn't provided)`,
+		`  PATHS: Files and directories to search (defaults to the current directory)`,
+		`  PATTERN: Patterns to ignore (relative to the current directory)`,
 		"  STASH_ARGS: Args to pass to `git stash push/pop`",
+		`  STRATEGY: How to integrate the default branch into the current branch`,
+		`    InList([merge rebase])`,
+		`  SUBMODULES: Submodules (defaults to all submodules)`,
+		`  TICKET_REGEX: Regex that extracts a ticket ID from a branch name (the first capture group is used if one exists)`,
+		`    IsRegex()`,
 		``,
 		`Flags:`,
+		"  [a] co: Co-authors (from the roster or in `Name <email>` format) to add as commit trailers",
 		`  [c] commit: Whether to diff against the previous commit`,
+		`  [d] date: Whether or not to sort the branches by last commit date (most recent first)`,
 		`  [d] diff: Whether or not to diff the current changes against N commits prior`,
+		`  [e] extended: Whether or not PATTERN is an extended regex`,
+		`  [f] ff-only: Whether or not to only pull if the branch can be fast-forwarded (overrides the repo setting)`,
+		`  [f] fixed: Whether or not to match PATTERN as a literal string instead of a regex`,
+		`  [f] force: Whether or not to force push (only if the remote branch hasn't changed since the last fetch)`,
 		`  [f] force-delete: force delete the branch`,
 		`  [g] global: Whether or not to change the global setting`,
+		`  [i] ignore-case: Whether or not to ignore case differences`,
+		`  [i] ignore-policy: Whether or not to skip the repo's commit message policy checks`,
+		`  [i] ignored: Whether or not to include ignored files`,
+		`  [j] json: Whether or not to print the branches as JSON`,
+		`  [l] local: Whether to add the patterns to .git/info/exclude (which isn't committed) instead of .gitignore`,
 		`  [m] main: Whether to diff against main branch or just local diffs`,
+		`  [l] max-subject-length: Maximum length of the commit message header`,
+		`    Default: 72`,
+		`    Positive()`,
+		`  [m] merge: Whether or not to merge the upstream branch (overrides the repo setting)`,
 		`  [n] new-branch: Whether or not to checkout a new branch`,
 		`  [n] no-verify: Whether or not to run pre-commit checks`,
+		`  [o] override-protected: Whether or not to allow changing commits on a protected branch`,
+		`  [p] patch: Whether or not to interactively choose hunks`,
 		`  [p] push: Whether or not to push afterwards`,
-		`  [u] upstream: If set, push branch to upstream`,
+		`  [r] rebase: Whether or not to rebase onto the upstream branch (overrides the repo setting)`,
+		`  [r] ref: Branch or tag to search instead of the working tree`,
+		`  [r] require-scope: Whether or not commit messages must include a scope`,
+		`  [s] strategy: How to integrate the default branch into the current branch (overrides the repo setting)`,
+		`    InList([merge rebase])`,
+		"  [t] trailer: Trailers (in `KEY=VALUE` format) to add to the commit",
+		`    MatchesRegex([^[A-Za-z0-9-]+=.+$])`,
+		`  [t] types: Allowed commit types`,
 		`  [w] whitespace: Whether or not to show whitespace in diffs`,
+		`  [w] word: Whether or not to only match whole words`,
 	}, "\n")
 
 	for _, curOS := range []sourcerer.OS{sourcerer.Linux(), sourcerer.Windows()} {
@@ -151,22 +465,114 @@ func TestExecution(t *testing.T) {
 			want     *git
 			etc      *commandtest.ExecuteTestCase
 			osChecks map[string]*osCheck
+			// stdin is the input for interactive prompts.
+			stdin string
 		}{
 			// TODO: Config tests
 			// Simple command tests
 			{
-				name: "branch",
+				name: "branch lists branches",
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"b"},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{"git branch"},
+					Args:         []string{"b"},
+					RunResponses: branchInfoRunResponses(),
+					WantRunContents: []*commandtest.RunContents{
+						branchInfoRunContents(),
+						{Name: "git", Args: []string{"rev-parse", "--verify", "--quiet", "main"}},
+						{Name: "git", Args: []string{"for-each-ref", "--format=%(refname:short)", "--merged=main", "refs/heads"}},
+						aheadBehindRunContents("old", "main"),
+						aheadBehindRunContents("feature", "main"),
+						aheadBehindRunContents("main", "main"),
+					},
+					WantStdout: strings.Join([]string{
+						"  BRANCH   LAST COMMIT       AUTHOR     UPSTREAM              VS main  MERGED",
+						"  old      1970-01-01 00:00  Some One   origin/old gone       +0 -4    yes",
+						"* feature  1970-01-01 00:03  Some One   origin/feature +1 -2  +3 -0    no",
+						"  main     1970-01-01 00:01  Other One  origin/main           +0 -0    yes",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "branch sorts by date",
+				etc: &commandtest.ExecuteTestCase{
+					Args:         []string{"b", "-d"},
+					RunResponses: branchInfoRunResponses(),
+					WantRunContents: []*commandtest.RunContents{
+						branchInfoRunContents(),
+						{Name: "git", Args: []string{"rev-parse", "--verify", "--quiet", "main"}},
+						{Name: "git", Args: []string{"for-each-ref", "--format=%(refname:short)", "--merged=main", "refs/heads"}},
+						aheadBehindRunContents("old", "main"),
+						aheadBehindRunContents("feature", "main"),
+						aheadBehindRunContents("main", "main"),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						branchDateFlag.Name(): true,
+					}},
+					WantStdout: strings.Join([]string{
+						"  BRANCH   LAST COMMIT       AUTHOR     UPSTREAM              VS main  MERGED",
+						"* feature  1970-01-01 00:03  Some One   origin/feature +1 -2  +3 -0    no",
+						"  main     1970-01-01 00:01  Other One  origin/main           +0 -0    yes",
+						"  old      1970-01-01 00:00  Some One   origin/old gone       +0 -4    yes",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "branch uses the repo's default branch and prints JSON",
+				g: &git{
+					MainBranches: map[string]string{
+						"some-repo": "trunk",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"b", "--json"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-repo"}},
+						{Stdout: []string{"feature\x00*\x00200\x00Some One\x00\x00"}},
+						{Err: fmt.Errorf("unknown ref")},
 					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						branchInfoRunContents(),
+						{Name: "git", Args: []string{"rev-parse", "--verify", "--quiet", "trunk"}},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						branchJSONFlag.Name(): true,
+						repoName.Name():       "some-repo",
+					}},
+					WantStdout: strings.Join([]string{
+						"[",
+						"  {",
+						`    "name": "feature",`,
+						`    "current": true,`,
+						`    "lastCommit": "1970-01-01T00:03:20Z",`,
+						`    "author": "Some One",`,
+						`    "upstreamGone": false,`,
+						`    "ahead": 0,`,
+						`    "behind": 0,`,
+						`    "baseAhead": 0,`,
+						`    "baseBehind": 0,`,
+						`    "merged": false`,
+						"  }",
+						"]",
+						"",
+					}, "\n"),
 				},
 			},
 			{
 				name: "pull",
 				etc: &commandtest.ExecuteTestCase{
 					Args: []string{"l"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"0\t3"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						aheadBehindRunContents("some-branch", "origin/some-branch"),
+					},
 					WantExecuteData: &command.ExecuteData{
 						FunctionWrap: true,
 						Executable: []string{
@@ -192,6 +598,10 @@ func TestExecution(t *testing.T) {
 				name: "git amend succeeds",
 				etc: &commandtest.ExecuteTestCase{
 					Args: []string{"am"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
 							`git commit --amend --no-edit`,
@@ -199,6 +609,93 @@ func TestExecution(t *testing.T) {
 					},
 				},
 			},
+			{
+				name: "undo commit refuses protected branch",
+				g: &git{
+					ProtectedBranches: map[string][]string{
+						"test-repo": {"release"},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"uco"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"release"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "test-repo",
+					}},
+					WantStdout: "release is a protected branch; enter a new branch name to undo a commit on instead (or nothing to cancel): \n",
+					WantStderr: "refusing to undo a commit on protected branch release (use --override-protected to override)\n",
+					WantErr:    fmt.Errorf("refusing to undo a commit on protected branch release (use --override-protected to override)"),
+				},
+			},
+			{
+				name: "undo commit on unprotected branch",
+				g: &git{
+					ProtectedBranches: map[string][]string{
+						"test-repo": {"release"},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"uco"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"feature"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "test-repo",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git reset HEAD~"},
+					},
+				},
+			},
+			{
+				name: "undo commit on default branch when other branches are protected",
+				g: &git{
+					ProtectedBranches: map[string][]string{
+						"test-repo": {"release"},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"uco"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"main"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "test-repo",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git reset HEAD~"},
+					},
+				},
+			},
+			{
+				name: "undo commit fails if can't get branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"uco"},
+					RunResponses: []*commandtest.FakeRun{{
+						Err: fmt.Errorf("oops"),
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantStderr:      "failed to get current branch: failed to execute shell command: oops\n",
+					WantErr:         fmt.Errorf("failed to get current branch: failed to execute shell command: oops"),
+				},
+			},
 			// Git log
 			{
 				name: "git log with no args",
@@ -238,7 +735,7 @@ func TestExecution(t *testing.T) {
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							"git diff HEAD~1 ",
+							"git diff HEAD~1 --",
 						},
 					},
 				},
@@ -253,7 +750,7 @@ func TestExecution(t *testing.T) {
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							"git diff HEAD~7 ",
+							"git diff HEAD~7 --",
 						},
 					},
 				},
@@ -269,7 +766,7 @@ func TestExecution(t *testing.T) {
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							"git diff HEAD~1 -w",
+							"git diff -w HEAD~1 --",
 						},
 					},
 				},
@@ -285,7 +782,7 @@ func TestExecution(t *testing.T) {
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							"git diff HEAD~7 -w",
+							"git diff -w HEAD~7 --",
 						},
 					},
 				},
@@ -297,7 +794,7 @@ func TestExecution(t *testing.T) {
 					Args: []string{"ush"},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							"git stash push ",
+							"git stash push",
 						},
 					},
 				},
@@ -308,7 +805,7 @@ func TestExecution(t *testing.T) {
 					Args: []string{"op"},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							"git stash pop ",
+							"git stash pop",
 						},
 					},
 				},
@@ -322,7 +819,7 @@ func TestExecution(t *testing.T) {
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git stash push "abc" "123"`,
+							"git stash push abc 123",
 						},
 					},
 				},
@@ -336,7 +833,7 @@ func TestExecution(t *testing.T) {
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git stash pop "def" "456"`,
+							"git stash pop def 456",
 						},
 					},
 				},
@@ -516,138 +1013,463 @@ func TestExecution(t *testing.T) {
 			{
 				name: "push",
 				etc: &commandtest.ExecuteTestCase{
-					Args:            []string{"p"},
+					Args: []string{"p"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"2\t0"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						aheadBehindRunContents("some-branch", "origin/some-branch"),
+					},
 					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git push"}, FunctionWrap: true},
 				},
 			},
 			{
-				name: "push upstream fails if can't get branch",
+				name: "push warns when behind upstream",
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"p", "--upstream"},
-					WantRunContents: []*commandtest.RunContents{{
-						Name: "git",
-						Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
-					}},
-					RunResponses: []*commandtest.FakeRun{{
-						Stdout: []string{"some-branch"},
-						Err:    fmt.Errorf("failed to get some-branch"),
-					}},
-					WantStderr: "failed to execute shell command: failed to get some-branch\n",
-					WantErr:    fmt.Errorf("failed to execute shell command: failed to get some-branch"),
-					WantData: &command.Data{Values: map[string]interface{}{
-						pushUpstreamFlag.Name(): true,
-					}},
+					Args: []string{"p"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"2\t3"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						aheadBehindRunContents("some-branch", "origin/some-branch"),
+					},
+					WantStderr:      "Warning: some-branch is 3 commits behind origin/some-branch (as of the last fetch), so the push may be rejected; run `g l` first\n",
+					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git push"}, FunctionWrap: true},
 				},
 			},
 			{
-				name: "push upstream succeeds",
+				name: "push fails if can't get branch",
 				etc: &commandtest.ExecuteTestCase{
-					Args:            []string{"p", "--upstream"},
-					WantExecuteData: &command.ExecuteData{Executable: []string{"", `git push --set-upstream origin "some-branch"`}, FunctionWrap: true},
-					WantRunContents: []*commandtest.RunContents{{
-						Name: "git",
-						Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
-					}},
+					Args:            []string{"p"},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
 					RunResponses: []*commandtest.FakeRun{{
-						Stdout: []string{"some-branch"},
-					}},
-					WantData: &command.Data{Values: map[string]interface{}{
-						pushUpstreamFlag.Name(): true,
-						"CURRENT_BRANCH":        "some-branch",
+						Err: fmt.Errorf("failed to get some-branch"),
 					}},
-					WantStdout: "git push --set-upstream origin \"some-branch\"\n",
+					WantStderr:      "failed to get current branch: failed to execute shell command: failed to get some-branch\n",
+					WantErr:         fmt.Errorf("failed to get current branch: failed to execute shell command: failed to get some-branch"),
+					WantExecuteData: &command.ExecuteData{Executable: []string{""}, FunctionWrap: true},
 				},
 			},
 			{
-				name: "pull",
+				name: "push fails if can't compare with upstream",
 				etc: &commandtest.ExecuteTestCase{
-					Args:            []string{"l"},
-					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git pull"}, FunctionWrap: true},
+					Args: []string{"p"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Err: fmt.Errorf("oops")},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						aheadBehindRunContents("some-branch", "origin/some-branch"),
+					},
+					WantStderr:      "failed to compare some-branch with origin/some-branch: failed to execute shell command: oops\n",
+					WantErr:         fmt.Errorf("failed to compare some-branch with origin/some-branch: failed to execute shell command: oops"),
+					WantExecuteData: &command.ExecuteData{Executable: []string{""}, FunctionWrap: true},
 				},
 			},
 			{
-				name: "pull and push",
-				osChecks: map[string]*osCheck{
-					"windows": {
-						wantExecutable: []string{
-							"",
-							wCmd("git pull"),
-							wCmd("git push"),
-						},
+				name: "push sets upstream for new branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"p"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-branch"}},
+						{Err: fmt.Errorf("no upstream configured")},
 					},
-					"linux": {
-						wantExecutable: []string{
-							"",
-							"git pull && git push",
-						},
+					WantRunContents: []*commandtest.RunContents{
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
 					},
-				},
-				etc: &commandtest.ExecuteTestCase{
-					Args:            []string{"pp"},
-					WantExecuteData: &command.ExecuteData{FunctionWrap: true},
+					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git push --set-upstream origin some-branch"}, FunctionWrap: true},
+					WantStdout:      "git push --set-upstream origin some-branch\n",
 				},
 			},
-			// Commit
 			{
-				name: "commit requires args",
+				name: "force push",
 				etc: &commandtest.ExecuteTestCase{
-					Args:       []string{"c"},
-					WantStderr: "Argument \"MESSAGE\" requires at least 1 argument, got 0\n",
-					WantErr:    fmt.Errorf(`Argument "MESSAGE" requires at least 1 argument, got 0`),
+					Args: []string{"p", "--force"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"abc123"}},
+						{Stdout: []string{"def456 Their change", "789abc Other change"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--verify", "origin/some-branch"},
+						},
+						{
+							Name: "git",
+							Args: []string{"log", "--format=%h %s", "some-branch..origin/some-branch"},
+						},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():      "test-repo",
+						forcePushFlag.Name(): true,
+					}},
+					WantStdout: strings.Join([]string{
+						"Force pushing will overwrite 2 commits in origin/some-branch:",
+						"  def456 Their change",
+						"  789abc Other change",
+						"",
+					}, "\n"),
+					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git push --force-with-lease=some-branch:abc123 origin HEAD:some-branch"}, FunctionWrap: true},
 				},
 			},
 			{
-				name: "simple commit",
-				osChecks: map[string]*osCheck{
-					"windows": {
-						wantExecutable: []string{
-							wCmd(`git commit -m "did things"`),
-							wCmd("echo Success!"),
+				name: "force push with nothing to overwrite",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"p", "-f"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"abc123"}},
+						{},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--verify", "origin/some-branch"},
+						},
+						{
+							Name: "git",
+							Args: []string{"log", "--format=%h %s", "some-branch..origin/some-branch"},
 						},
 					},
-				},
-				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"c", "did", "things"},
 					WantData: &command.Data{Values: map[string]interface{}{
-						messageArg.Name(): []string{"did", "things"},
+						repoName.Name():      "test-repo",
+						forcePushFlag.Name(): true,
 					}},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git commit -m "did things" && echo Success!`,
-						},
-					},
+					WantStdout:      "No commits in origin/some-branch will be overwritten\n",
+					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git push --force-with-lease=some-branch:abc123 origin HEAD:some-branch"}, FunctionWrap: true},
 				},
 			},
 			{
-				name: "commit no verify",
-				osChecks: map[string]*osCheck{
-					"windows": {
-						wantExecutable: []string{
-							wCmd(`git commit --no-verify -m "did things"`),
-							wCmd("echo Success!"),
-						},
+				name: "force push refuses default branch",
+				g: &git{
+					MainBranches: map[string]string{
+						"test-repo": "trunk",
 					},
 				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"c", "did", "things", "-n"},
+					Args: []string{"p", "-f"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"trunk"}},
+						{Stdout: []string{"origin/trunk"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						upstreamRunContents("trunk"),
+					},
 					WantData: &command.Data{Values: map[string]interface{}{
-						messageArg.Name(): []string{"did", "things"},
-						nvFlag.Name():     nvFlag.TrueValue(),
+						repoName.Name():      "test-repo",
+						forcePushFlag.Name(): true,
 					}},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git commit --no-verify -m "did things" && echo Success!`,
-						},
-					},
+					WantStderr:      "refusing to force push protected branch trunk\n",
+					WantErr:         fmt.Errorf("refusing to force push protected branch trunk"),
+					WantExecuteData: &command.ExecuteData{Executable: []string{""}, FunctionWrap: true},
 				},
 			},
 			{
-				name: "commit push",
-				osChecks: map[string]*osCheck{
-					"windows": {
-						wantExecutable: []string{
+				name: "force push refuses protected branch",
+				g: &git{
+					ProtectedBranches: map[string][]string{
+						"test-repo": {"release"},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"p", "-f"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"release"}},
+						{Stdout: []string{"origin/release"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						upstreamRunContents("release"),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():      "test-repo",
+						forcePushFlag.Name(): true,
+					}},
+					WantStderr:      "refusing to force push protected branch release\n",
+					WantErr:         fmt.Errorf("refusing to force push protected branch release"),
+					WantExecuteData: &command.ExecuteData{Executable: []string{""}, FunctionWrap: true},
+				},
+			},
+			{
+				name: "force push refuses default branch when other branches are protected",
+				g: &git{
+					ProtectedBranches: map[string][]string{
+						"test-repo": {"release"},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"p", "-f"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"main"}},
+						{Stdout: []string{"origin/main"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						upstreamRunContents("main"),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():      "test-repo",
+						forcePushFlag.Name(): true,
+					}},
+					WantStderr:      "refusing to force push protected branch main\n",
+					WantErr:         fmt.Errorf("refusing to force push protected branch main"),
+					WantExecuteData: &command.ExecuteData{Executable: []string{""}, FunctionWrap: true},
+				},
+			},
+			{
+				name: "pull with flag",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"l", "-r"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"1\t1"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						aheadBehindRunContents("some-branch", "origin/some-branch"),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						pullRebaseFlag.Name(): true,
+					}},
+					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git pull --rebase"}, FunctionWrap: true},
+				},
+			},
+			{
+				name: "pull with repo mode",
+				g: &git{
+					PullModes: map[string]string{
+						"test-repo": ffOnlyPullMode,
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"l"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"0\t1"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						aheadBehindRunContents("some-branch", "origin/some-branch"),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "test-repo",
+					}},
+					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git pull --ff-only"}, FunctionWrap: true},
+				},
+			},
+			{
+				name: "pull warns when fast-forward only pull may fail",
+				g: &git{
+					PullModes: map[string]string{
+						"test-repo": ffOnlyPullMode,
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"l"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"2\t1"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						aheadBehindRunContents("some-branch", "origin/some-branch"),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "test-repo",
+					}},
+					WantStderr:      "Warning: some-branch and origin/some-branch have diverged (as of the last fetch), so a fast-forward only pull may fail\n",
+					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git pull --ff-only"}, FunctionWrap: true},
+				},
+			},
+			{
+				name: "pull flag overrides repo mode",
+				g: &git{
+					PullModes: map[string]string{
+						"test-repo": ffOnlyPullMode,
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"l", "--merge"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"some-branch"}},
+						{Stdout: []string{"origin/some-branch"}},
+						{Stdout: []string{"2\t1"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+						aheadBehindRunContents("some-branch", "origin/some-branch"),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():      "test-repo",
+						pullMergeFlag.Name(): true,
+					}},
+					WantExecuteData: &command.ExecuteData{Executable: []string{"", "git pull"}, FunctionWrap: true},
+				},
+			},
+			{
+				name: "pull fails with multiple modes",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"l", "-r", "-f"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						pullRebaseFlag.Name(): true,
+						pullFFOnlyFlag.Name(): true,
+					}},
+					WantStderr:      "only one of --rebase, --ff-only, and --merge can be provided\n",
+					WantErr:         fmt.Errorf("only one of --rebase, --ff-only, and --merge can be provided"),
+					WantExecuteData: &command.ExecuteData{Executable: []string{""}, FunctionWrap: true},
+				},
+			},
+			{
+				name: "pull fails without upstream",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"l"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-branch"}},
+						{Err: fmt.Errorf("no upstream configured")},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						currentBranchRunContents(),
+						upstreamRunContents("some-branch"),
+					},
+					WantStderr:      "some-branch has no upstream branch (run `g p` to push it and set one)\n",
+					WantErr:         fmt.Errorf("some-branch has no upstream branch (run `g p` to push it and set one)"),
+					WantExecuteData: &command.ExecuteData{Executable: []string{""}, FunctionWrap: true},
+				},
+			},
+			{
+				name: "pull and push",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							"",
+							wCmd("git pull"),
+							wCmd("git push"),
+						},
+					},
+					"linux": {
+						wantExecutable: []string{
+							"",
+							"git pull && git push",
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"pp"},
+					WantExecuteData: &command.ExecuteData{FunctionWrap: true},
+				},
+			},
+			// Commit
+			{
+				name: "commit requires args",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"c"},
+					WantStderr: "Argument \"MESSAGE\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "MESSAGE" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "simple commit",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'did things'`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"c", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						messageArg.Name(): []string{"did", "things"},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git commit -m "did things" && echo Success!`,
+						},
+					},
+				},
+			},
+			{
+				name: "commit no verify",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit --no-verify -m 'did things'`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"c", "did", "things", "-n"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						messageArg.Name(): []string{"did", "things"},
+						nvFlag.Name():     nvFlag.TrueValue(),
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git commit --no-verify -m "did things" && echo Success!`,
+						},
+					},
+				},
+			},
+			{
+				name: "commit push",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
 							createSSHAgentCommand,
-							wCmd(`git commit -m "did things"`),
+							wCmd(`git commit -m 'did things'`),
 							wCmd(`git push`),
 							wCmd("echo Success!"),
 						},
@@ -655,6 +1477,10 @@ func TestExecution(t *testing.T) {
 				},
 				etc: &commandtest.ExecuteTestCase{
 					Args: []string{"c", "did", "things", "-p"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
 						messageArg.Name(): []string{"did", "things"},
 						pushFlag.Name():   true,
@@ -674,7 +1500,7 @@ func TestExecution(t *testing.T) {
 					"windows": {
 						wantExecutable: []string{
 							createSSHAgentCommand,
-							wCmd(`git commit --no-verify -m "did things"`),
+							wCmd(`git commit --no-verify -m 'did things'`),
 							wCmd(`git push`),
 							wCmd("echo Success!"),
 						},
@@ -682,6 +1508,10 @@ func TestExecution(t *testing.T) {
 				},
 				etc: &commandtest.ExecuteTestCase{
 					Args: []string{"c", "did", "things", "--no-verify", "--push"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
 						messageArg.Name(): []string{"did", "things"},
 						nvFlag.Name():     nvFlag.TrueValue(),
@@ -702,7 +1532,7 @@ func TestExecution(t *testing.T) {
 					"windows": {
 						wantExecutable: []string{
 							createSSHAgentCommand,
-							wCmd(`git commit --no-verify -m "did things"`),
+							wCmd(`git commit --no-verify -m 'did things'`),
 							wCmd(`git push`),
 							wCmd("echo Success!"),
 						},
@@ -710,6 +1540,10 @@ func TestExecution(t *testing.T) {
 				},
 				etc: &commandtest.ExecuteTestCase{
 					Args: []string{"c", "-np", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
 						messageArg.Name(): []string{"did", "things"},
 						nvFlag.Name():     nvFlag.TrueValue(),
@@ -730,10 +1564,10 @@ func TestExecution(t *testing.T) {
 					"windows": {
 						wantExecutable: []string{
 							wCmd(strings.Join([]string{
-								`git commit -m "did`,
+								`git commit -m 'did`,
 								`things and`,
 								``,
-								`other things too"`,
+								`other things too'`,
 							}, "\n")),
 							wCmd("echo Success!"),
 						},
@@ -741,6 +1575,10 @@ func TestExecution(t *testing.T) {
 				},
 				etc: &commandtest.ExecuteTestCase{
 					Args: []string{"c", "did\nthings", "and\n\nother things too"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
 						messageArg.Name(): []string{"did\nthings", "and\n\nother things too"},
 					}},
@@ -756,600 +1594,2745 @@ func TestExecution(t *testing.T) {
 					},
 				},
 			},
-			// Commit & push
-			{
-				name: "commit and push requires args",
-				etc: &commandtest.ExecuteTestCase{
-					Args:       []string{"cp"},
-					WantStderr: "Argument \"MESSAGE\" requires at least 1 argument, got 0\n",
-					WantErr:    fmt.Errorf(`Argument "MESSAGE" requires at least 1 argument, got 0`),
-				},
-			},
 			{
-				name: "simple commit and push",
+				name: "commit message with shell expansions",
 				osChecks: map[string]*osCheck{
 					"windows": {
 						wantExecutable: []string{
-							createSSHAgentCommand,
-							wCmd(`git commit -m "did things"`),
-							wCmd(`git push`),
+							wCmd("git commit -m 'costs $5 with `x`'"),
 							wCmd("echo Success!"),
 						},
 					},
 				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cp", "did", "things"},
+					Args: []string{"c", "costs", "$5", "with", "`x`"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						messageArg.Name(): []string{"did", "things"},
+						messageArg.Name(): []string{"costs", "$5", "with", "`x`"},
 					}},
 					WantExecuteData: &command.ExecuteData{
-						FunctionWrap: true,
 						Executable: []string{
-							createSSHAgentCommand,
-							`git commit -m "did things" && git push && echo Success!`,
+							"git commit -m \"costs \\$5 with \\`x\\`\" && echo Success!",
 						},
 					},
 				},
 			},
+			// Commit policy
 			{
-				name: "commit and push no verify",
+				name: "commit fails commit policy",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"test-repo": {MaxSubjectLength: 72},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"c", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"feature"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():   "test-repo",
+						messageArg.Name(): []string{"did", "things"},
+					}},
+					WantStderr: "commit message violates the commit policy for this repo (use --ignore-policy to commit anyway): commit message header \"did things\" is not of the form \"type(scope): subject\"\n",
+					WantErr:    fmt.Errorf(`commit message violates the commit policy for this repo (use --ignore-policy to commit anyway): commit message header "did things" is not of the form "type(scope): subject"`),
+				},
+			},
+			{
+				name: "commit satisfies commit policy",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"test-repo": {MaxSubjectLength: 72},
+					},
+				},
 				osChecks: map[string]*osCheck{
 					"windows": {
 						wantExecutable: []string{
-							createSSHAgentCommand,
-							wCmd(`git commit --no-verify -m "did things"`),
-							wCmd(`git push`),
+							wCmd(`git commit -m 'fix(cli): did things'`),
 							wCmd("echo Success!"),
 						},
 					},
 				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cp", "did", "things", "-n"},
+					Args: []string{"c", "fix(cli):", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"test-repo"},
+					}, {
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents(), currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						messageArg.Name(): []string{"did", "things"},
-						nvFlag.Name():     nvFlag.TrueValue(),
+						repoName.Name():   "test-repo",
+						messageArg.Name(): []string{"fix(cli):", "did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
-						FunctionWrap: true,
 						Executable: []string{
-							createSSHAgentCommand,
-							`git commit --no-verify -m "did things" && git push && echo Success!`,
+							`git commit -m "fix(cli): did things" && echo Success!`,
 						},
 					},
 				},
 			},
-			// Checkout new branch
 			{
-				name: "checkout branch requires arg",
-				etc: &commandtest.ExecuteTestCase{
-					Args:       []string{"ch"},
-					WantStderr: "Argument \"BRANCH\" requires at least 1 argument, got 0\n",
-					WantErr:    fmt.Errorf(`Argument "BRANCH" requires at least 1 argument, got 0`),
+				name: "commit ignores commit policy with flag",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"test-repo": {MaxSubjectLength: 72},
+					},
 				},
-			},
-			{
-				name: "checkout branch requires one arg",
-				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"ch", "tree", "limb"},
-					WantData: &command.Data{Values: map[string]interface{}{
-						branchArg.Name(): "tree",
-					}},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git checkout tree`,
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'did things'`),
+							wCmd("echo Success!"),
 						},
 					},
-					WantStderr: fmt.Sprintf("Unprocessed extra args: [limb]\n\n%s\n%s\n", "======= Command Usage =======", u),
-					WantErr:    fmt.Errorf(`Unprocessed extra args: [limb]`),
 				},
-			},
-			{
-				name: "checks out a branch",
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"ch", "tree"},
+					Args: []string{"c", "did", "things", "-i"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"test-repo"},
+					}, {
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents(), currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						branchArg.Name(): "tree",
+						repoName.Name():         "test-repo",
+						messageArg.Name():       []string{"did", "things"},
+						ignorePolicyFlag.Name(): true,
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git checkout tree`,
+							`git commit -m "did things" && echo Success!`,
 						},
 					},
 				},
 			},
 			{
-				name: "checks out a new branch",
-				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"ch", "tree", "-n"},
+				name: "commit ignores commit policy for other repos",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"other-repo": {MaxSubjectLength: 72},
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'did things'`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"c", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"test-repo"},
+					}, {
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents(), currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						branchArg.Name():     "tree",
-						newBranchFlag.Name(): true,
+						repoName.Name():   "test-repo",
+						messageArg.Name(): []string{"did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git checkout -b tree`,
+							`git commit -m "did things" && echo Success!`,
 						},
 					},
 				},
 			},
-			// Delete new branch
 			{
-				name: "delete branch requires arg",
-				etc: &commandtest.ExecuteTestCase{
-					Args:       []string{"bd"},
-					WantStderr: "Argument \"BRANCH\" requires at least 1 argument, got 0\n",
-					WantErr:    fmt.Errorf(`Argument "BRANCH" requires at least 1 argument, got 0`),
+				name: "commit ignores commit policy if no remote",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"test-repo": {MaxSubjectLength: 72},
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'did things'`),
+							wCmd("echo Success!"),
+						},
+					},
 				},
-			},
-			{
-				name: "delete branch requires one arg",
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"bd", "tree", "limb"},
+					Args: []string{"c", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{{
+						Err: fmt.Errorf("no remote"),
+					}, {
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents(), currentBranchRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						branchArg.Name(): "tree",
+						messageArg.Name(): []string{"did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git branch -d tree`,
+							`git commit -m "did things" && echo Success!`,
 						},
 					},
-					WantStderr: fmt.Sprintf("Unprocessed extra args: [limb]\n\n%s\n%s\n", "======= Command Usage =======", u),
-					WantErr:    fmt.Errorf(`Unprocessed extra args: [limb]`),
 				},
 			},
 			{
-				name: "deletes a branch",
+				name: "commit and push fails commit policy",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"test-repo": {MaxSubjectLength: 10},
+					},
+				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"bd", "tree"},
+					Args: []string{"cp", "fix:", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"feature"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+					},
 					WantData: &command.Data{Values: map[string]interface{}{
-						branchArg.Name(): "tree",
+						repoName.Name():   "test-repo",
+						messageArg.Name(): []string{"fix:", "did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git branch -d tree`,
-						},
+						FunctionWrap: true,
+						Executable:   []string{createSSHAgentCommand},
 					},
+					WantStderr: "commit message violates the commit policy for this repo (use --ignore-policy to commit anyway): commit message header is 15 characters long (max 10)\n",
+					WantErr:    fmt.Errorf(`commit message violates the commit policy for this repo (use --ignore-policy to commit anyway): commit message header is 15 characters long (max 10)`),
 				},
 			},
+			// Ticket prefix
 			{
-				name: "force deletes a branch",
+				name: "commit adds ticket prefix from branch",
+				g: &git{
+					TicketPatterns: map[string]string{
+						"test-repo": "^[A-Z]+-[0-9]+",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'ABC-123: did things'`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"bd", "-f", "tree"},
+					Args: []string{"c", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"ABC-123-fix-thing"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
+						},
+					},
 					WantData: &command.Data{Values: map[string]interface{}{
-						branchArg.Name():   "tree",
-						forceDelete.Name(): true,
+						repoName.Name():         "test-repo",
+						currentBranchArg.Name(): "ABC-123-fix-thing",
+						messageArg.Name():       []string{"did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git branch -D tree`,
+							`git commit -m "ABC-123: did things" && echo Success!`,
 						},
 					},
 				},
 			},
-			// Undo add
 			{
-				name: "undo requires args",
-				etc: &commandtest.ExecuteTestCase{
-					Args:       []string{"ua"},
-					WantStderr: "Argument \"FILE\" requires at least 1 argument, got 0\n",
-					WantErr:    fmt.Errorf(`Argument "FILE" requires at least 1 argument, got 0`),
+				name: "commit on protected branch uses ticket from the new branch",
+				g: &git{
+					TicketPatterns: map[string]string{
+						"test-repo": "^[A-Z]+-[0-9]+",
+					},
+					ProtectedBranches: map[string][]string{
+						"test-repo": {"release"},
+					},
+				},
+				stdin: "ABC-7-fix\n",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'ABC-7: did things'`),
+							wCmd("echo Success!"),
+						},
+					},
 				},
-			},
-			{
-				name: "undo resets files",
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"ua", "file.one", "some/where/file.2"},
-					WantData: &command.Data{Values: map[string]interface{}{
-						uaArgs.Name(): []string{
-							"file.one",
-							"some/where/file.2",
+					Args: []string{"c", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"release"}},
+						{},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						currentBranchRunContents(),
+						{
+							Name: "git",
+							Args: []string{"checkout", "-b", "ABC-7-fix"},
 						},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():         "test-repo",
+						currentBranchArg.Name(): "ABC-7-fix",
+						messageArg.Name():       []string{"did", "things"},
 					}},
+					WantStdout: "release is a protected branch; enter a new branch name to commit on instead (or nothing to cancel): Switched to new branch ABC-7-fix\n",
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git reset -- file.one some/where/file.2`,
+							`git commit -m "ABC-7: did things" && echo Success!`,
 						},
 					},
 				},
 			},
-			// Undo change
 			{
-				name: "undo change requires args",
-				etc: &commandtest.ExecuteTestCase{
-					Args:       []string{"uc"},
-					WantStderr: "Argument \"FILE\" requires at least 1 argument, got 0\n",
-					WantErr:    fmt.Errorf(`Argument "FILE" requires at least 1 argument, got 0`),
+				name: "commit uses ticket pattern capture group",
+				g: &git{
+					TicketPatterns: map[string]string{
+						"test-repo": "^user/([A-Z]+-[0-9]+)",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'XY-9: did things'`),
+							wCmd("echo Success!"),
+						},
+					},
 				},
-			},
-			{
-				name: "undo change undoes changed files",
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"uc", "file.one", "some/where/file.2"},
-					WantData: &command.Data{Values: map[string]interface{}{
-						ucArgs.Name(): []string{
-							"file.one",
-							"some/where/file.2",
+					Args: []string{"c", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"user/XY-9-stuff"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
 						},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():         "test-repo",
+						currentBranchArg.Name(): "user/XY-9-stuff",
+						messageArg.Name():       []string{"did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git checkout -- file.one some/where/file.2`,
+							`git commit -m "XY-9: did things" && echo Success!`,
 						},
 					},
 				},
 			},
-			// Status
 			{
-				name: "status with no args",
-				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"s"},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git status `,
+				name: "commit doesn't duplicate ticket prefix",
+				g: &git{
+					TicketPatterns: map[string]string{
+						"test-repo": "^[A-Z]+-[0-9]+",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'ABC-123: did things'`),
+							wCmd("echo Success!"),
 						},
 					},
 				},
-			},
-			{
-				name: "status with args args",
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"s", "file.one", "some/where/file.2"},
-					WantData: &command.Data{Values: map[string]interface{}{
-						filesArg.Name(): []string{
-							"file.one",
-							"some/where/file.2",
+					Args: []string{"c", "ABC-123:", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"ABC-123-fix-thing"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
 						},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():         "test-repo",
+						currentBranchArg.Name(): "ABC-123-fix-thing",
+						messageArg.Name():       []string{"ABC-123:", "did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git status file.one some/where/file.2`,
+							`git commit -m "ABC-123: did things" && echo Success!`,
 						},
 					},
 				},
 			},
-			// Add
 			{
-				name: "add with no args",
+				name: "commit doesn't add ticket prefix if branch doesn't match",
+				g: &git{
+					TicketPatterns: map[string]string{
+						"test-repo": "^[A-Z]+-[0-9]+",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'did things'`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"a"},
+					Args: []string{"c", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"fix-thing"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
+						},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():         "test-repo",
+						currentBranchArg.Name(): "fix-thing",
+						messageArg.Name():       []string{"did", "things"},
+					}},
 					WantExecuteData: &command.ExecuteData{
 						Executable: []string{
-							`git add .`,
+							`git commit -m "did things" && echo Success!`,
 						},
 					},
 				},
 			},
 			{
-				name: "add with args args",
+				name: "commit and push adds ticket prefix and checks commit policy",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"test-repo": {MaxSubjectLength: 24},
+					},
+					TicketPatterns: map[string]string{
+						"test-repo": "^[A-Z]+-[0-9]+",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							createSSHAgentCommand,
+							wCmd(`git commit -m 'ABC-123: fix: did things'`),
+							wCmd(`git push`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"a", "file.one", "some/where/file.2"},
-					WantData: &command.Data{Values: map[string]interface{}{
-						filesArg.Name(): []string{
-							"file.one",
-							"some/where/file.2",
+					Args: []string{"cp", "fix:", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"ABC-123-fix-thing"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
 						},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():         "test-repo",
+						currentBranchArg.Name(): "ABC-123-fix-thing",
+						messageArg.Name():       []string{"fix:", "did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
+						FunctionWrap: true,
 						Executable: []string{
-							`git add file.one some/where/file.2`,
+							createSSHAgentCommand,
+							`git commit -m "ABC-123: fix: did things" && git push && echo Success!`,
 						},
 					},
 				},
 			},
-			// Diff
 			{
-				name: "diff with no args",
+				name: "commit and push counts ticket prefix toward the commit policy length",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"test-repo": {MaxSubjectLength: 20},
+					},
+					TicketPatterns: map[string]string{
+						"test-repo": "^[A-Z]+-[0-9]+",
+					},
+				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"d"},
-					RunResponses: []*commandtest.FakeRun{{
-						Stdout: []string{"test-repo"},
-					}},
-					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					Args: []string{"cp", "fix:", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"ABC-123-fix-thing"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
+						},
+					},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name(): "test-repo",
+						repoName.Name():         "test-repo",
+						currentBranchArg.Name(): "ABC-123-fix-thing",
+						messageArg.Name():       []string{"fix:", "did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git diff  -- `,
-						},
+						FunctionWrap: true,
+						Executable:   []string{createSSHAgentCommand},
 					},
+					WantStderr: "commit message violates the commit policy for this repo (use --ignore-policy to commit anyway): commit message header is 24 characters long (max 20)\n",
+					WantErr:    fmt.Errorf(`commit message violates the commit policy for this repo (use --ignore-policy to commit anyway): commit message header is 24 characters long (max 20)`),
 				},
 			},
 			{
-				name: "diff with args",
+				name: "commit and push prefixes a ticket that only matches the start of another ticket",
+				g: &git{
+					TicketPatterns: map[string]string{
+						"test-repo": "^[A-Z]+-[0-9]+",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							createSSHAgentCommand,
+							wCmd(`git commit -m 'ABC-1: ABC-12: did things'`),
+							wCmd(`git push`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"d", "this.file", "that/file/txt"},
-					RunResponses: []*commandtest.FakeRun{{
-						Stdout: []string{"test-repo"},
-					}},
-					WantRunContents: []*commandtest.RunContents{repoRunContents()},
-					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name(): "test-repo",
-						diffArgs.Name(): []string{
-							"this.file",
-							"that/file/txt",
+					Args: []string{"cp", "ABC-12:", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"test-repo"}},
+						{Stdout: []string{"ABC-1-fix-thing"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--abbrev-ref", "HEAD"},
 						},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():         "test-repo",
+						currentBranchArg.Name(): "ABC-1-fix-thing",
+						messageArg.Name():       []string{"ABC-12:", "did", "things"},
 					}},
 					WantExecuteData: &command.ExecuteData{
+						FunctionWrap: true,
 						Executable: []string{
-							`git diff  -- this.file that/file/txt`,
+							createSSHAgentCommand,
+							`git commit -m "ABC-1: ABC-12: did things" && git push && echo Success!`,
 						},
 					},
 				},
 			},
+			// Commit trailers
 			{
-				name: "diff against main branch",
-				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"d", "-m"},
+				name: "commit with co-author from roster",
+				g: &git{
+					Roster: map[string]string{
+						"Jane": "jane@example.com",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit -m 'did things' --trailer 'Co-authored-by: Jane <jane@example.com>'`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"c", "did", "things", "--co", "Jane"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						messageArg.Name(): []string{"did", "things"},
+						coAuthorFlagName:  []string{"Jane"},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git commit -m "did things" --trailer "Co-authored-by: Jane <jane@example.com>" && echo Success!`,
+						},
+					},
+				},
+			},
+			{
+				name: "commit with multiple co-authors and trailers",
+				g: &git{
+					Roster: map[string]string{
+						"Jane": "jane@example.com",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							wCmd(`git commit --no-verify -m 'did things' --trailer 'Co-authored-by: Jane <jane@example.com>' --trailer 'Co-authored-by: Bob Smith <bob@example.com>' --trailer 'Reviewed-by: Alice' --trailer 'Fixes: #12'`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"c", "did", "things", "-a", "Jane", "Bob Smith <bob@example.com>", "-n", "--trailer", "Reviewed-by=Alice", "Fixes=#12"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						messageArg.Name():  []string{"did", "things"},
+						coAuthorFlagName:   []string{"Jane", "Bob Smith <bob@example.com>"},
+						trailerFlag.Name(): []string{"Reviewed-by=Alice", "Fixes=#12"},
+						nvFlag.Name():      nvFlag.TrueValue(),
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git commit --no-verify -m "did things" --trailer "Co-authored-by: Jane <jane@example.com>" --trailer "Co-authored-by: Bob Smith <bob@example.com>" --trailer "Reviewed-by: Alice" --trailer "Fixes: #12" && echo Success!`,
+						},
+					},
+				},
+			},
+			{
+				name: "commit fails for unknown co-author",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"c", "did", "things", "--co", "Jane"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						messageArg.Name(): []string{"did", "things"},
+						coAuthorFlagName:  []string{"Jane"},
+					}},
+					WantStderr: "unknown co-author \"Jane\"; add them to the roster with `g cfg roster add` or use the `Name <email>` format\n",
+					WantErr:    fmt.Errorf("unknown co-author \"Jane\"; add them to the roster with `g cfg roster add` or use the `Name <email>` format"),
+				},
+			},
+			{
+				name: "commit fails for invalid trailer",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"c", "did", "things", "-t", "Reviewed-by"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						trailerFlag.Name(): []string{"Reviewed-by"},
+					}},
+					WantStderr: "validation for \"trailer\" failed: [MatchesRegex] value \"Reviewed-by\" doesn't match regex \"^[A-Za-z0-9-]+=.+$\"\n",
+					WantErr:    fmt.Errorf("validation for \"trailer\" failed: [MatchesRegex] value \"Reviewed-by\" doesn't match regex \"^[A-Za-z0-9-]+=.+$\""),
+				},
+			},
+			{
+				name: "commit and push with co-author",
+				g: &git{
+					Roster: map[string]string{
+						"Jane": "jane@example.com",
+					},
+				},
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							createSSHAgentCommand,
+							wCmd(`git commit -m 'did things' --trailer 'Co-authored-by: Jane <jane@example.com>'`),
+							wCmd(`git push`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cp", "did", "things", "--co", "Jane"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						messageArg.Name(): []string{"did", "things"},
+						coAuthorFlagName:  []string{"Jane"},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						FunctionWrap: true,
+						Executable: []string{
+							createSSHAgentCommand,
+							`git commit -m "did things" --trailer "Co-authored-by: Jane <jane@example.com>" && git push && echo Success!`,
+						},
+					},
+				},
+			},
+			// Commit & push
+			{
+				name: "commit and push requires args",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cp"},
+					WantStderr: "Argument \"MESSAGE\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "MESSAGE" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "simple commit and push",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							createSSHAgentCommand,
+							wCmd(`git commit -m 'did things'`),
+							wCmd(`git push`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cp", "did", "things"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						messageArg.Name(): []string{"did", "things"},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						FunctionWrap: true,
+						Executable: []string{
+							createSSHAgentCommand,
+							`git commit -m "did things" && git push && echo Success!`,
+						},
+					},
+				},
+			},
+			{
+				name: "commit and push no verify",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{
+							createSSHAgentCommand,
+							wCmd(`git commit --no-verify -m 'did things'`),
+							wCmd(`git push`),
+							wCmd("echo Success!"),
+						},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cp", "did", "things", "-n"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"feature"},
+					}},
+					WantRunContents: []*commandtest.RunContents{currentBranchRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						messageArg.Name(): []string{"did", "things"},
+						nvFlag.Name():     nvFlag.TrueValue(),
+					}},
+					WantExecuteData: &command.ExecuteData{
+						FunctionWrap: true,
+						Executable: []string{
+							createSSHAgentCommand,
+							`git commit --no-verify -m "did things" && git push && echo Success!`,
+						},
+					},
+				},
+			},
+			// Checkout new branch
+			{
+				name: "checkout branch requires arg",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"ch"},
+					WantStderr: "Argument \"BRANCH\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "BRANCH" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "checkout branch requires one arg",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"ch", "tree", "limb"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						branchArg.Name(): "tree",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git checkout tree`,
+						},
+					},
+					WantStderr: fmt.Sprintf("Unprocessed extra args: [limb]\n\n%s\n%s\n", "======= Command Usage =======", u),
+					WantErr:    fmt.Errorf(`Unprocessed extra args: [limb]`),
+				},
+			},
+			{
+				name: "checks out a branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"ch", "tree"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						branchArg.Name(): "tree",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git checkout tree`,
+						},
+					},
+				},
+			},
+			{
+				name: "checks out the previous branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"ch", "-"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						checkoutBranchArg.Name(): "-",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git checkout -`,
+						},
+					},
+				},
+			},
+			{
+				name: "recent lists recent branches",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"recent", "2"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"  b-1", "* b-2", "  b-3", "  b-4"}},
+						{Stdout: []string{
+							"checkout: moving from b-4 to b-2",
+							"checkout: moving from b-3 to b-4",
+							"checkout: moving from b-1 to b-3",
+						}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{Name: "git", Args: []string{"branch", "--list"}},
+						reflogRunContents(),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						recentCountArg.Name(): 2,
+					}},
+					WantStdout: "b-4\nb-3\n",
+				},
+			},
+			{
+				name: "recent fails if the reflog can't be read",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"recent"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"* b-1"}},
+						{Err: fmt.Errorf("oops")},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{Name: "git", Args: []string{"branch", "--list"}},
+						reflogRunContents(),
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						recentCountArg.Name(): 10,
+					}},
+					WantStderr: "failed to get recent branches: failed to execute shell command: oops\n",
+					WantErr:    fmt.Errorf("failed to get recent branches: failed to execute shell command: oops"),
+				},
+			},
+			{
+				name: "checks out a new branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"ch", "tree", "-n"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						branchArg.Name():     "tree",
+						newBranchFlag.Name(): true,
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git checkout -b tree`,
+						},
+					},
+				},
+			},
+			// Delete new branch
+			{
+				name: "delete branch requires arg",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"bd"},
+					WantStderr: "Argument \"BRANCH\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "BRANCH" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "delete branch requires one arg",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bd", "tree", "limb"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						branchArg.Name(): "tree",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git branch -d tree`,
+						},
+					},
+					WantStderr: fmt.Sprintf("Unprocessed extra args: [limb]\n\n%s\n%s\n", "======= Command Usage =======", u),
+					WantErr:    fmt.Errorf(`Unprocessed extra args: [limb]`),
+				},
+			},
+			{
+				name: "deletes a branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bd", "tree"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						branchArg.Name(): "tree",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git branch -d tree`,
+						},
+					},
+				},
+			},
+			{
+				name: "force deletes a branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bd", "-f", "tree"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						branchArg.Name():   "tree",
+						forceDelete.Name(): true,
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git branch -D tree`,
+						},
+					},
+				},
+			},
+			// Undo add
+			{
+				name: "undo requires args",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"ua"},
+					WantStderr: "Argument \"FILE\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "FILE" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "undo resets files",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"ua", "file.one", "some/where/file.2"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						uaArgs.Name(): []string{
+							"file.one",
+							"some/where/file.2",
+						},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git reset -- file.one some/where/file.2`,
+						},
+					},
+				},
+			},
+			// Undo change
+			{
+				name: "undo change requires args",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"uc"},
+					WantStderr: "Argument \"FILE\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "FILE" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "undo change undoes changed files",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"uc", "file.one", "some/where/file.2"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						ucArgs.Name(): []string{
+							"file.one",
+							"some/where/file.2",
+						},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git checkout -- file.one some/where/file.2`,
+						},
+					},
+				},
+			},
+			// Status
+			{
+				name: "status with no args",
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"s"},
+					RunResponses:    []*commandtest.FakeRun{{}},
+					WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git status",
+						},
+					},
+				},
+			},
+			{
+				name: "status shows submodule states",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"s"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{
+							" 0123456789abcdef0123456789abcdef01234567 lib/one (heads/main)",
+							"+fedcba9876543210fedcba9876543210fedcba98 lib/two (v1.2.3)",
+							"-1111111111111111111111111111111111111111 vendor/three",
+							"U2222222222222222222222222222222222222222 vendor/four",
+						},
+					}},
+					WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
+					WantStdout: strings.Join([]string{
+						"Submodules:",
+						"  lib/one: up to date (0123456)",
+						"  lib/two: new commits (fedcba9)",
+						"  vendor/three: not initialized (1111111)",
+						"  vendor/four: merge conflicts (2222222)",
+						"",
+						"",
+					}, "\n"),
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git status",
+						},
+					},
+				},
+			},
+			{
+				name: "status warns if submodule status fails",
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"s"},
+					RunResponses:    []*commandtest.FakeRun{{Err: fmt.Errorf("oops")}},
+					WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
+					WantStderr:      "Warning: failed to list submodules: failed to execute shell command: oops\n",
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git status",
+						},
+					},
+				},
+			},
+			{
+				name: "status with args args",
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"s", "file.one", "some/where/file.2"},
+					RunResponses:    []*commandtest.FakeRun{{}},
+					WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						filesArg.Name(): []string{
+							"file.one",
+							"some/where/file.2",
+						},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git status file.one some/where/file.2`,
+						},
+					},
+				},
+			},
+			// Blame
+			{
+				name: "blame requires a file",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"bl"},
+					WantStderr: "Argument \"FILE\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "FILE" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "blame a file",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{`git blame --date=relative -- 'some file.go'`},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bl", "some file.go"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						blameFileArg.Name(): "some file.go",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{`git blame --date=relative -- "some file.go"`},
+					},
+				},
+			},
+			{
+				name: "blame from a line ignoring whitespace",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{"git blame --date=relative -w -L '12,' -- a.go"},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bl", "a.go", "12", "-w"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						blameFileArg.Name():   "a.go",
+						blameLinesArg.Name():  "12",
+						whitespaceFlag.Name(): "-w",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git blame --date=relative -w -L 12, -- a.go"},
+					},
+				},
+			},
+			{
+				name: "blame a line range",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{"git blame --date=relative -L '12,20' -- a.go"},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bl", "a.go", "12:20"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						blameFileArg.Name():  "a.go",
+						blameLinesArg.Name(): "12:20",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git blame --date=relative -L 12,20 -- a.go"},
+					},
+				},
+			},
+			{
+				name: "blame fails for a malformed line range",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bl", "a.go", "12-20"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						blameFileArg.Name():  "a.go",
+						blameLinesArg.Name(): "12-20",
+					}},
+					WantStderr: "validation for \"LINES\" failed: [MatchesRegex] value \"12-20\" doesn't match regex \"^[1-9][0-9]*(:[1-9][0-9]*)?$\"\n",
+					WantErr:    fmt.Errorf("validation for \"LINES\" failed: [MatchesRegex] value \"12-20\" doesn't match regex \"^[1-9][0-9]*(:[1-9][0-9]*)?$\""),
+				},
+			},
+			{
+				name: "blame fails for a backwards line range",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bl", "a.go", "20:12"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						blameFileArg.Name():  "a.go",
+						blameLinesArg.Name(): "20:12",
+					}},
+					WantStderr: "invalid line range \"20:12\": END is before START\n",
+					WantErr:    fmt.Errorf(`invalid line range "20:12": END is before START`),
+				},
+			},
+			{
+				name: "blame uses the repo's ignore-revs file",
+				g: &git{
+					BlameIgnoreRevs: map[string]string{
+						"some-repo": "config/ignore-revs",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bl", "a.go"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-repo"}},
+						{Stdout: []string{"/path/to/repo"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{Name: "git", Args: []string{"rev-parse", "--show-toplevel"}},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						blameFileArg.Name(): "a.go",
+						repoName.Name():     "some-repo",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git blame --date=relative --ignore-revs-file /path/to/repo/config/ignore-revs -- a.go"},
+					},
+				},
+			},
+			{
+				name: "blame ignores other repos' ignore-revs files",
+				g: &git{
+					BlameIgnoreRevs: map[string]string{
+						"other-repo": "config/ignore-revs",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"bl", "a.go"},
+					RunResponses:    []*commandtest.FakeRun{{Stdout: []string{"some-repo"}}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						blameFileArg.Name(): "a.go",
+						repoName.Name():     "some-repo",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git blame --date=relative -- a.go"},
+					},
+				},
+			},
+			// Grep
+			{
+				name: "grep requires a pattern",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"gr"},
+					WantStderr: "Argument \"PATTERN\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "PATTERN" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "grep for a pattern",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"gr", "TODO"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						grepPatternArg.Name(): "TODO",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git grep -n -I -e TODO --"},
+					},
+				},
+			},
+			{
+				name: "grep in paths with flags",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{`git grep -n -I -i -w -F -e 'func (g' -- a.go 'sub dir'`},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"gr", "-i", "func (g", "-w", "a.go", "sub dir", "-f"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						grepPatternArg.Name():     "func (g",
+						grepPathsArg.Name():       []string{"a.go", "sub dir"},
+						grepIgnoreCaseFlag.Name(): true,
+						grepWordFlag.Name():       true,
+						grepFixedFlag.Name():      true,
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{`git grep -n -I -i -w -F -e "func (g" -- a.go "sub dir"`},
+					},
+				},
+			},
+			{
+				name: "grep for an extended regex",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{`git grep -n -I -E -e '^(a|b)$' --`},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"gr", "-e", "^(a|b)$"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						grepPatternArg.Name():   "^(a|b)$",
+						grepExtendedFlag.Name(): true,
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{`git grep -n -I -E -e "^(a|b)\$" --`},
+					},
+				},
+			},
+			{
+				name: "grep fails for fixed and extended patterns",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"gr", "-f", "-e", "x"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						grepPatternArg.Name():   "x",
+						grepFixedFlag.Name():    true,
+						grepExtendedFlag.Name(): true,
+					}},
+					WantStderr: "--fixed and --extended can't both be provided\n",
+					WantErr:    fmt.Errorf("--fixed and --extended can't both be provided"),
+				},
+			},
+			{
+				name: "grep in a ref",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"gr", "TODO", "sub", "--ref", "v1.2"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						grepPatternArg.Name(): "TODO",
+						grepPathsArg.Name():   []string{"sub"},
+						grepRefFlag.Name():    "v1.2",
+					}},
+					RunResponses: []*commandtest.FakeRun{
+						{},
+						{Stdout: []string{
+							"v1.2:sub/a.go:3:// TODO: fix",
+							"v1.2:sub/b:c.txt:7:TODO",
+						}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--verify", "--quiet", "v1.2"},
+						},
+						{
+							Name: "git",
+							Args: []string{"grep", "-n", "-I", "-e", "TODO", "v1.2", "--", "sub"},
+						},
+					},
+					WantStdout: strings.Join([]string{
+						"sub/a.go:3:// TODO: fix",
+						"sub/b:c.txt:7:TODO",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "grep in a ref with no matches",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"gr", "TODO", "--ref", "v1.2"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						grepPatternArg.Name(): "TODO",
+						grepRefFlag.Name():    "v1.2",
+					}},
+					RunResponses: []*commandtest.FakeRun{
+						{},
+						{Err: fmt.Errorf("exit status 1")},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--verify", "--quiet", "v1.2"},
+						},
+						{
+							Name: "git",
+							Args: []string{"grep", "-n", "-I", "-e", "TODO", "v1.2", "--"},
+						},
+					},
+					WantStderr: "No matches in v1.2\n",
+					WantErr:    fmt.Errorf("No matches in v1.2"),
+				},
+			},
+			{
+				name: "grep in a ref fails for an invalid pattern",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"gr", "a[", "--ref", "v1.2"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						grepPatternArg.Name(): "a[",
+						grepRefFlag.Name():    "v1.2",
+					}},
+					RunResponses: []*commandtest.FakeRun{
+						{},
+						{Err: fmt.Errorf("exit status 128")},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{
+							Name: "git",
+							Args: []string{"rev-parse", "--verify", "--quiet", "v1.2"},
+						},
+						{
+							Name: "git",
+							Args: []string{"grep", "-n", "-I", "-e", "a[", "v1.2", "--"},
+						},
+					},
+					WantStderr: "failed to grep v1.2: failed to execute shell command: exit status 128\n",
+					WantErr:    fmt.Errorf("failed to grep v1.2: failed to execute shell command: exit status 128"),
+				},
+			},
+			{
+				name: "grep fails for a missing ref",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"gr", "TODO", "--ref", "v9"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						grepPatternArg.Name(): "TODO",
+						grepRefFlag.Name():    "v9",
+					}},
+					RunResponses: []*commandtest.FakeRun{{Err: fmt.Errorf("exit status 1")}},
+					WantRunContents: []*commandtest.RunContents{{
+						Name: "git",
+						Args: []string{"rev-parse", "--verify", "--quiet", "v9"},
+					}},
+					WantStderr: "v9 does not exist\n",
+					WantErr:    fmt.Errorf("v9 does not exist"),
+				},
+			},
+			// Bisect
+			{
+				name: "bisect shows when not bisecting",
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"bs"},
+					RunResponses:    []*commandtest.FakeRun{{}},
+					WantRunContents: []*commandtest.RunContents{{Name: "git", Args: []string{"for-each-ref", "--format=%(objectname) %(refname)", "refs/bisect/"}}},
+					WantStdout:      "Not bisecting\n",
+				},
+			},
+			{
+				name: "bisect shows when waiting for a good commit",
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"bs"},
+					RunResponses:    []*commandtest.FakeRun{{Stdout: []string{"0123456789abcdef refs/bisect/bad"}}},
+					WantRunContents: []*commandtest.RunContents{{Name: "git", Args: []string{"for-each-ref", "--format=%(objectname) %(refname)", "refs/bisect/"}}},
+					WantStdout: strings.Join([]string{
+						"Waiting for a good commit (run `g bs good`)",
+						"  Bad: 0123456",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "bisect shows the remaining steps",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{
+							"0123456789abcdef refs/bisect/bad",
+							"1111111111111111 refs/bisect/good-1111111111111111",
+							"2222222222222222 refs/bisect/good-2222222222222222",
+							"3333333333333333 refs/bisect/skip-3333333333333333",
+						}},
+						{Stdout: []string{
+							"bisect_rev='4444444444444444'",
+							"bisect_nr=3",
+							"bisect_good=3",
+							"bisect_bad=2",
+							"bisect_all=7",
+							"bisect_steps=2",
+						}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{Name: "git", Args: []string{"for-each-ref", "--format=%(objectname) %(refname)", "refs/bisect/"}},
+						{Name: "git", Args: []string{"rev-list", "--bisect-vars", "refs/bisect/bad", "--not", "refs/bisect/good-1111111111111111", "refs/bisect/good-2222222222222222"}},
+					},
+					WantStdout: strings.Join([]string{
+						"Testing 4444444: 3 commits left to test after this (roughly 2 steps)",
+						"  Bad: 0123456",
+						"  Good: 1111111, 2222222",
+						"  Skipped: 3333333",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "bisect shows the first bad commit",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{
+							"0123456789abcdef refs/bisect/bad",
+							"1111111111111111 refs/bisect/good-1111111111111111",
+						}},
+						{Stdout: []string{
+							"bisect_rev='0123456789abcdef'",
+							"bisect_nr=0",
+							"bisect_all=1",
+							"bisect_steps=0",
+						}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{Name: "git", Args: []string{"for-each-ref", "--format=%(objectname) %(refname)", "refs/bisect/"}},
+						{Name: "git", Args: []string{"rev-list", "--bisect-vars", "refs/bisect/bad", "--not", "refs/bisect/good-1111111111111111"}},
+					},
+					WantStdout: strings.Join([]string{
+						"Found the first bad commit: 0123456",
+						"  Bad: 0123456",
+						"  Good: 1111111",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "bisect status fails",
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"bs"},
+					RunResponses:    []*commandtest.FakeRun{{Err: fmt.Errorf("oops")}},
+					WantRunContents: []*commandtest.RunContents{{Name: "git", Args: []string{"for-each-ref", "--format=%(objectname) %(refname)", "refs/bisect/"}}},
+					WantStderr:      "failed to get bisect state: failed to execute shell command: oops\n",
+					WantErr:         fmt.Errorf("failed to get bisect state: failed to execute shell command: oops"),
+				},
+			},
+			{
+				name: "bisect start requires a bad commit",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"bs", "start"},
+					WantStderr: "Argument \"BAD\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "BAD" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "bisect start with a good commit",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "start", "HEAD", "v1.0"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						bisectBadArg.Name():  "HEAD",
+						bisectGoodArg.Name(): "v1.0",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git bisect start HEAD v1.0"},
+					},
+				},
+			},
+			{
+				name: "bisect start defaults to the merge-base with the default branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "start", "my-branch"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"1111111111111111"}},
+						{Stdout: []string{"0123456789abcdef"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{Name: "git", Args: []string{"merge-base", "my-branch", "main"}},
+						{Name: "git", Args: []string{"rev-parse", "--verify", "my-branch"}},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						bisectBadArg.Name(): "my-branch",
+					}},
+					WantStdout: "Using the merge-base of my-branch and main (1111111) as the good commit\n",
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git bisect start my-branch 1111111111111111"},
+					},
+				},
+			},
+			{
+				name: "bisect start uses the repo's default branch",
+				g: &git{
+					MainBranches: map[string]string{
+						"some-repo": "trunk",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "start", "HEAD"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"some-repo"}},
+						{Stdout: []string{"1111111111111111"}},
+						{Stdout: []string{"0123456789abcdef"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						repoRunContents(),
+						{Name: "git", Args: []string{"merge-base", "HEAD", "trunk"}},
+						{Name: "git", Args: []string{"rev-parse", "--verify", "HEAD"}},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						bisectBadArg.Name(): "HEAD",
+						repoName.Name():     "some-repo",
+					}},
+					WantStdout: "Using the merge-base of HEAD and trunk (1111111) as the good commit\n",
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git bisect start HEAD 1111111111111111"},
+					},
+				},
+			},
+			{
+				name: "bisect start fails if the bad commit is in the default branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "start", "HEAD~2"},
+					RunResponses: []*commandtest.FakeRun{
+						{Stdout: []string{"1111111111111111"}},
+						{Stdout: []string{"1111111111111111"}},
+					},
+					WantRunContents: []*commandtest.RunContents{
+						{Name: "git", Args: []string{"merge-base", "HEAD~2", "main"}},
+						{Name: "git", Args: []string{"rev-parse", "--verify", "HEAD~2"}},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						bisectBadArg.Name(): "HEAD~2",
+					}},
+					WantStderr: "HEAD~2 is already in main, so a GOOD commit must be provided\n",
+					WantErr:    fmt.Errorf("HEAD~2 is already in main, so a GOOD commit must be provided"),
+				},
+			},
+			{
+				name: "bisect start fails if the merge-base can't be found",
+				etc: &commandtest.ExecuteTestCase{
+					Args:         []string{"bs", "start", "HEAD"},
+					RunResponses: []*commandtest.FakeRun{{Err: fmt.Errorf("oops")}},
+					WantRunContents: []*commandtest.RunContents{
+						{Name: "git", Args: []string{"merge-base", "HEAD", "main"}},
+					},
+					WantData: &command.Data{Values: map[string]interface{}{
+						bisectBadArg.Name(): "HEAD",
+					}},
+					WantStderr: "failed to get merge-base of HEAD and main: failed to execute shell command: oops\n",
+					WantErr:    fmt.Errorf("failed to get merge-base of HEAD and main: failed to execute shell command: oops"),
+				},
+			},
+			{
+				name: "bisect good",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "good"},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git bisect good"},
+					},
+				},
+			},
+			{
+				name: "bisect bad commits",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "bad", "abc123", "def456"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						bisectRevsArg.Name(): []string{"abc123", "def456"},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git bisect bad abc123 def456"},
+					},
+				},
+			},
+			{
+				name: "bisect skip",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "skip"},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git bisect skip"},
+					},
+				},
+			},
+			{
+				name: "bisect run requires a command",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"bs", "run"},
+					WantStderr: "Argument \"CMD\" requires at least 1 argument, got 0\n",
+					WantErr:    fmt.Errorf(`Argument "CMD" requires at least 1 argument, got 0`),
+				},
+			},
+			{
+				name: "bisect run",
+				osChecks: map[string]*osCheck{
+					"windows": {
+						wantExecutable: []string{`git bisect run go test ./... -run 'Test Foo'`},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "run", "go", "test", "./...", "-run", "Test Foo"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						bisectCmdArg.Name(): []string{"go", "test", "./...", "-run", "Test Foo"},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{`git bisect run go test ./... -run "Test Foo"`},
+					},
+				},
+			},
+			{
+				name: "bisect reset",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"bs", "reset"},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{"git bisect reset"},
+					},
+				},
+			},
+			// Submodules
+			{
+				name: "submodules shows states",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"sm"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{
+							" 0123456789abcdef0123456789abcdef01234567 lib/one (heads/main)",
+							"+fedcba9876543210fedcba9876543210fedcba98 lib/two (v1.2.3)",
+							"-1111111111111111111111111111111111111111 vendor/three",
+						},
+					}},
+					WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
+					WantStdout: strings.Join([]string{
+						"Submodules:",
+						"  lib/one: up to date (0123456)",
+						"  lib/two: new commits (fedcba9)",
+						"  vendor/three: not initialized (1111111)",
+						"",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "submodules shows nothing if no submodules",
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"sm"},
+					RunResponses:    []*commandtest.FakeRun{{}},
+					WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
+				},
+			},
+			{
+				name: "submodules fails if submodule status fails",
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"sm"},
+					RunResponses:    []*commandtest.FakeRun{{Err: fmt.Errorf("oops")}},
+					WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
+					WantErr:         fmt.Errorf("failed to list submodules: failed to execute shell command: oops"),
+					WantStderr:      "failed to list submodules: failed to execute shell command: oops\n",
+				},
+			},
+			{
+				name: "submodule update",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"sm", "update"},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"",
+							"git submodule update --init --recursive",
+						},
+						FunctionWrap: true,
+					},
+				},
+			},
+			{
+				name: "submodule update with paths",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"sm", "update", "lib/one", "vendor/three"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						submodulePathsArg.Name(): []string{"lib/one", "vendor/three"},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"",
+							"git submodule update --init --recursive -- lib/one vendor/three",
+						},
+						FunctionWrap: true,
+					},
+				},
+			},
+			{
+				name: "submodule sync",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"sm", "sync", "lib/two"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						submodulePathsArg.Name(): []string{"lib/two"},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git submodule sync --recursive -- lib/two",
+						},
+					},
+				},
+			},
+			{
+				name: "submodule pull",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"sm", "pull"},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"",
+							"git submodule update --remote --recursive",
+						},
+						FunctionWrap: true,
+					},
+				},
+			},
+			// Add
+			{
+				name: "add with no args",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"a"},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git add .`,
+						},
+					},
+				},
+			},
+			{
+				name: "add with args args",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"a", "file.one", "some/where/file.2"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						filesArg.Name(): []string{
+							"file.one",
+							"some/where/file.2",
+						},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git add file.one some/where/file.2`,
+						},
+					},
+				},
+			},
+			// Diff
+			{
+				name: "diff with no args",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"d"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"test-repo"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "test-repo",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git diff --",
+						},
+					},
+				},
+			},
+			{
+				name: "diff with args",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"d", "this.file", "that/file/txt"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"test-repo"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "test-repo",
+						diffArgs.Name(): []string{
+							"this.file",
+							"that/file/txt",
+						},
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git diff -- this.file that/file/txt",
+						},
+					},
+				},
+			},
+			{
+				name: "diff against main branch",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"d", "-m"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"test-repo"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "test-repo",
+						mainFlag.Name(): true,
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git diff main --",
+						},
+					},
+				},
+			},
+			{
+				name: "diff against last commit",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"d", "-c"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"test-repo"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():       "test-repo",
+						prevCommitFlag.Name(): true,
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git diff HEAD~1 --",
+						},
+					},
+				},
+			},
+			{
+				name: "diff with whitespace flag",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"d", "-w"},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"test-repo"},
+					}},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():       "test-repo",
+						whitespaceFlag.Name(): "-w",
+					}},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							"git diff -w --",
+						},
+					},
+				},
+			},
+			// Rebase tests
+			{
+				name: "Rebase abort",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"rb", "a"},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git rebase --abort`,
+						},
+					},
+					WantStdout: "git rebase --abort\n",
+				},
+			},
+			{
+				name: "Rebase abort",
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"rb", "c"},
+					WantExecuteData: &command.ExecuteData{
+						Executable: []string{
+							`git rebase --continue`,
+						},
+					},
+					WantStdout: "git rebase --continue\n",
+				},
+			},
+			// Config tests
+			{
+				name: "Shows empty config",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "main", "show"},
+					WantStdout: "No global default branch set; using main\n",
+				},
+			},
+			{
+				name: "Shows default branch config",
+				g: &git{
+					DefaultBranch: "other-main",
+					MainBranches: map[string]string{
+						"un":   "main-one",
+						"deux": "main-two",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "main", "show"},
+					WantStdout: strings.Join([]string{
+						"Global default branch: other-main",
+						"deux: main-two",
+						"un: main-one",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "Sets default branch",
+				g:    &git{},
+				want: &git{
+					MainBranches: map[string]string{
+						"some-repo": "db",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "main", "set", "db"},
+					WantRunContents: []*commandtest.RunContents{{
+						Name: "git",
+						Args: []string{
+							"config",
+							"--get",
+							"remote.origin.url",
+						},
+					}},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():   "some-repo",
+						defRepoArg.Name(): "db",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Setting default branch for some-repo to db\n",
+				},
+			},
+			{
+				name: "Sets global default branch",
+				g:    &git{},
+				want: &git{
+					DefaultBranch: "Maine",
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "main", "set", "Maine", "-g"},
+					WantRunContents: []*commandtest.RunContents{{
+						Name: "git",
+						Args: []string{
+							"config",
+							"--get",
+							"remote.origin.url",
+						},
+					}},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():     "some-repo",
+						defRepoArg.Name():   "Maine",
+						globalConfig.Name(): true,
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Setting global default branch to Maine\n",
+				},
+			},
+			{
+				name: "Unsets default branch",
+				g: &git{
+					MainBranches: map[string]string{
+						"some-repo": "db",
+						"other":     "heh",
+					},
+				},
+				want: &git{
+					MainBranches: map[string]string{
+						"other": "heh",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "main", "unset"},
+					WantRunContents: []*commandtest.RunContents{{
+						Name: "git",
+						Args: []string{
+							"config",
+							"--get",
+							"remote.origin.url",
+						},
+					}},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Deleting default branch for some-repo\n",
+				},
+			},
+			{
+				name: "Does nothing if no default branch map",
+				g:    &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "main", "unset"},
+					WantRunContents: []*commandtest.RunContents{{
+						Name: "git",
+						Args: []string{
+							"config",
+							"--get",
+							"remote.origin.url",
+						},
+					}},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "No default branch set for this repo\n",
+				},
+			},
+			{
+				name: "Does nothing if no default branch for repo",
+				g: &git{
+					MainBranches: map[string]string{
+						"other": "dflt",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "main", "unset"},
+					WantRunContents: []*commandtest.RunContents{{
+						Name: "git",
+						Args: []string{
+							"config",
+							"--get",
+							"remote.origin.url",
+						},
+					}},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
 					RunResponses: []*commandtest.FakeRun{{
-						Stdout: []string{"test-repo"},
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "No default branch set for this repo\n",
+				},
+			},
+			{
+				name: "Unsets global default branch",
+				g: &git{
+					DefaultBranch: "Maine",
+				},
+				want: &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "main", "unset", "-g"},
+					WantRunContents: []*commandtest.RunContents{{
+						Name: "git",
+						Args: []string{
+							"config",
+							"--get",
+							"remote.origin.url",
+						},
+					}},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():     "some-repo",
+						globalConfig.Name(): true,
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
 					}},
+					WantStdout: "Deleting global default branch\n",
+				},
+			},
+			{
+				name: "Shows empty commit policies",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "commit", "show"},
+					WantStdout: "No commit policies set\n",
+				},
+			},
+			{
+				name: "Shows commit policies",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"un":   {MaxSubjectLength: 50, Types: []string{"feat", "fix"}, RequireScope: true},
+						"deux": {MaxSubjectLength: 72},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "commit", "show"},
+					WantStdout: strings.Join([]string{
+						"deux: types=[build chore ci docs feat fix perf refactor revert style test], max subject length=72",
+						"un: types=[feat fix], max subject length=50, scope required",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "Sets commit policy",
+				g:    &git{},
+				want: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"some-repo": {MaxSubjectLength: 72},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "commit", "set"},
 					WantRunContents: []*commandtest.RunContents{repoRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name(): "test-repo",
-						mainFlag.Name(): true,
+						repoName.Name():             "some-repo",
+						maxSubjectLengthFlag.Name(): 72,
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Setting commit policy for some-repo to types=[build chore ci docs feat fix perf refactor revert style test], max subject length=72\n",
+				},
+			},
+			{
+				name: "Sets commit policy with flags",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"other": {MaxSubjectLength: 72},
+					},
+				},
+				want: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"other":     {MaxSubjectLength: 72},
+						"some-repo": {MaxSubjectLength: 50, Types: []string{"feat", "fix"}, RequireScope: true},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "commit", "set", "-r", "--types", "feat", "fix", "-l", "50"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():               "some-repo",
+						maxSubjectLengthFlag.Name():   50,
+						commitTypesFlag.Name():        []string{"feat", "fix"},
+						requireCommitScopeFlag.Name(): true,
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Setting commit policy for some-repo to types=[feat fix], max subject length=50, scope required\n",
+				},
+			},
+			{
+				name: "Unsets commit policy",
+				g: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"other":     {MaxSubjectLength: 72},
+						"some-repo": {MaxSubjectLength: 72},
+					},
+				},
+				want: &git{
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"other": {MaxSubjectLength: 72},
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "commit", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Deleting commit policy for some-repo\n",
+				},
+			},
+			{
+				name: "Unset does nothing if no commit policy for repo",
+				g:    &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "commit", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "No commit policy set for this repo\n",
+				},
+			},
+			{
+				name: "Shows all config",
+				g: &git{
+					DefaultBranch: "other-main",
+					CommitPolicies: map[string]*gitrepo.CommitPolicy{
+						"un": {MaxSubjectLength: 50},
+					},
+					TicketPatterns: map[string]string{
+						"deux": "^[A-Z]+-[0-9]+",
+					},
+					Roster: map[string]string{
+						"Jane": "jane@example.com",
+					},
+					SyncStrategies: map[string]string{
+						"trois": "rebase",
+					},
+					PullModes: map[string]string{
+						"quatre": "ff-only",
+					},
+					ProtectedBranches: map[string][]string{
+						"cinq": {"release", "staging"},
+					},
+					FuzzyCompletion: true,
+					CompletionCache: true,
+					BlameIgnoreRevs: map[string]string{
+						"six": ".git-blame-ignore-revs",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg"},
+					WantStdout: strings.Join([]string{
+						"Global default branch: other-main",
+						"un: types=[build chore ci docs feat fix perf refactor revert style test], max subject length=50",
+						`deux: ticket ID from branch matching "^[A-Z]+-[0-9]+"`,
+						"Jane <jane@example.com>",
+						"trois: rebase",
+						"quatre: pull with ff-only",
+						"cinq: protecting release, staging",
+						"Fuzzy completion: on",
+						"Completion cache: on",
+						"six: blame ignoring commits in .git-blame-ignore-revs",
+						"",
+					}, "\n"),
+				},
+			},
+			{
+				name: "Shows fuzzy completion",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "fuzzy", "show"},
+					WantStdout: "Fuzzy completion: off\n",
+				},
+			},
+			{
+				name: "Enables fuzzy completion",
+				g:    &git{},
+				want: &git{
+					FuzzyCompletion: true,
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "fuzzy", "on"},
+					WantStdout: "Enabling fuzzy completion\n",
+				},
+			},
+			{
+				name: "Disables fuzzy completion",
+				g: &git{
+					FuzzyCompletion: true,
+				},
+				want: &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "fuzzy", "off"},
+					WantStdout: "Disabling fuzzy completion\n",
+				},
+			},
+			{
+				name: "Shows completion cache",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "cache", "show"},
+					WantStdout: "Completion cache: off\n",
+				},
+			},
+			{
+				name: "Enables completion cache",
+				g:    &git{},
+				want: &git{
+					CompletionCache: true,
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "cache", "on"},
+					WantStdout: "Enabling completion cache\n",
+				},
+			},
+			{
+				name: "Disables completion cache",
+				g: &git{
+					CompletionCache: true,
+				},
+				want: &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "cache", "off"},
+					WantStdout: "Disabling completion cache\n",
+				},
+			},
+			{
+				name: "Shows empty blame ignore-revs files",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "blame", "show"},
+					WantStdout: "No blame ignore-revs files set\n",
+				},
+			},
+			{
+				name: "Sets blame ignore-revs file",
+				g:    &git{},
+				want: &git{
+					BlameIgnoreRevs: map[string]string{
+						"some-repo": ".git-blame-ignore-revs",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "blame", "set", ".git-blame-ignore-revs"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():           "some-repo",
+						blameIgnoreRevsArg.Name(): ".git-blame-ignore-revs",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Setting blame ignore-revs file for some-repo to .git-blame-ignore-revs\n",
+				},
+			},
+			{
+				name: "Unsets blame ignore-revs file",
+				g: &git{
+					BlameIgnoreRevs: map[string]string{
+						"some-repo":  ".git-blame-ignore-revs",
+						"other-repo": "revs.txt",
+					},
+				},
+				want: &git{
+					BlameIgnoreRevs: map[string]string{
+						"other-repo": "revs.txt",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "blame", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Deleting blame ignore-revs file for some-repo\n",
+				},
+			},
+			{
+				name: "Unsets missing blame ignore-revs file",
+				g:    &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "blame", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "No blame ignore-revs file set for this repo\n",
+				},
+			},
+			{
+				name: "Shows empty ticket patterns",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "ticket", "show"},
+					WantStdout: "No ticket patterns set\n",
+				},
+			},
+			{
+				name: "Sets ticket pattern",
+				g:    &git{},
+				want: &git{
+					TicketPatterns: map[string]string{
+						"some-repo": "^[A-Z]+-[0-9]+",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "ticket", "set", "^[A-Z]+-[0-9]+"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():         "some-repo",
+						ticketPatternArg.Name(): "^[A-Z]+-[0-9]+",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Setting ticket pattern for some-repo to \"^[A-Z]+-[0-9]+\"\n",
+				},
+			},
+			{
+				name: "Set ticket pattern fails for invalid regex",
+				g:    &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "ticket", "set", "[A-Z"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():         "some-repo",
+						ticketPatternArg.Name(): "[A-Z",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStderr: "validation for \"TICKET_REGEX\" failed: [IsRegex] value \"[A-Z\" isn't a valid regex: error parsing regexp: missing closing ]: `[A-Z`\n",
+					WantErr:    fmt.Errorf("validation for \"TICKET_REGEX\" failed: [IsRegex] value \"[A-Z\" isn't a valid regex: error parsing regexp: missing closing ]: `[A-Z`"),
+				},
+			},
+			{
+				name: "Unsets ticket pattern",
+				g: &git{
+					TicketPatterns: map[string]string{
+						"some-repo": "^[A-Z]+-[0-9]+",
+					},
+				},
+				want: &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "ticket", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Deleting ticket pattern for some-repo\n",
+				},
+			},
+			{
+				name: "Unset does nothing if no ticket pattern for repo",
+				g:    &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "ticket", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
 					}},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git diff  main `,
-						},
-					},
+					WantStdout: "No ticket pattern set for this repo\n",
 				},
 			},
 			{
-				name: "diff against last commit",
+				name: "Shows empty sync strategies",
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"d", "-c"},
-					RunResponses: []*commandtest.FakeRun{{
-						Stdout: []string{"test-repo"},
-					}},
+					Args:       []string{"cfg", "sync", "show"},
+					WantStdout: "No sync strategies set; using merge\n",
+				},
+			},
+			{
+				name: "Sets sync strategy",
+				g: &git{
+					SyncStrategies: map[string]string{
+						"other": "merge",
+					},
+				},
+				want: &git{
+					SyncStrategies: map[string]string{
+						"other":     "merge",
+						"some-repo": "rebase",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "sync", "set", "rebase"},
 					WantRunContents: []*commandtest.RunContents{repoRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name():       "test-repo",
-						prevCommitFlag.Name(): true,
+						repoName.Name():        "some-repo",
+						syncStrategyArg.Name(): "rebase",
 					}},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git diff  "$(git rev-parse @~1)" `,
-						},
-					},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Setting sync strategy for some-repo to rebase\n",
 				},
 			},
 			{
-				name: "diff with whitespace flag",
+				name: "Set sync strategy fails for unknown strategy",
+				g:    &git{},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"d", "-w"},
-					RunResponses: []*commandtest.FakeRun{{
-						Stdout: []string{"test-repo"},
-					}},
+					Args:            []string{"cfg", "sync", "set", "squash"},
 					WantRunContents: []*commandtest.RunContents{repoRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name():       "test-repo",
-						whitespaceFlag.Name(): "-w",
+						repoName.Name():        "some-repo",
+						syncStrategyArg.Name(): "squash",
 					}},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git diff -w -- `,
-						},
-					},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStderr: "validation for \"STRATEGY\" failed: [InList] argument must be one of [merge rebase]\n",
+					WantErr:    fmt.Errorf("validation for \"STRATEGY\" failed: [InList] argument must be one of [merge rebase]"),
 				},
 			},
-			// Rebase tests
 			{
-				name: "Rebase abort",
-				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"rb", "a"},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git rebase --abort`,
-						},
+				name: "Unsets sync strategy",
+				g: &git{
+					SyncStrategies: map[string]string{
+						"some-repo": "rebase",
 					},
-					WantStdout: "git rebase --abort\n",
+				},
+				want: &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "sync", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Deleting sync strategy for some-repo\n",
 				},
 			},
 			{
-				name: "Rebase abort",
+				name: "Unset does nothing if no sync strategy for repo",
+				g:    &git{},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"rb", "c"},
-					WantExecuteData: &command.ExecuteData{
-						Executable: []string{
-							`git rebase --continue`,
-						},
-					},
-					WantStdout: "git rebase --continue\n",
+					Args:            []string{"cfg", "sync", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "No sync strategy set for this repo\n",
 				},
 			},
-			// Config tests
 			{
-				name: "Shows empty config",
+				name: "Shows empty protected branches",
 				etc: &commandtest.ExecuteTestCase{
-					Args:       []string{"cfg", "main", "show"},
-					WantStdout: "No global default branch set; using main\n",
+					Args:       []string{"cfg", "protect", "show"},
+					WantStdout: "No protected branches set; only default branches are protected\n",
 				},
 			},
 			{
-				name: "Shows default branch config",
+				name: "Adds protected branches",
 				g: &git{
-					DefaultBranch: "other-main",
-					MainBranches: map[string]string{
-						"un":   "main-one",
-						"deux": "main-two",
+					ProtectedBranches: map[string][]string{
+						"some-repo": {"release"},
+					},
+				},
+				want: &git{
+					ProtectedBranches: map[string][]string{
+						"some-repo": {"release", "staging"},
 					},
 				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cfg", "main", "show"},
-					WantStdout: strings.Join([]string{
-						"Global default branch: other-main",
-						"deux: main-two",
-						"un: main-one",
-						"",
-					}, "\n"),
+					Args:            []string{"cfg", "protect", "add", "release", "staging"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():           "some-repo",
+						protectBranchesArg.Name(): []string{"release", "staging"},
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Protecting release, staging in some-repo\n",
 				},
 			},
 			{
-				name: "Sets default branch",
-				g:    &git{},
+				name: "Removes protected branches",
+				g: &git{
+					ProtectedBranches: map[string][]string{
+						"some-repo": {"release", "staging"},
+						"other":     {"release"},
+					},
+				},
 				want: &git{
-					MainBranches: map[string]string{
-						"some-repo": "db",
+					ProtectedBranches: map[string][]string{
+						"some-repo": {"staging"},
+						"other":     {"release"},
 					},
 				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cfg", "main", "set", "db"},
-					WantRunContents: []*commandtest.RunContents{{
-						Name: "git",
-						Args: []string{
-							"config",
-							"--get",
-							"remote.origin.url",
-						},
-					}},
+					Args:            []string{"cfg", "protect", "rm", "release"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name():   "some-repo",
-						defRepoArg.Name(): "db",
+						repoName.Name():           "some-repo",
+						protectBranchesArg.Name(): []string{"release"},
 					}},
 					RunResponses: []*commandtest.FakeRun{{
 						Stdout: []string{"some-repo"},
 					}},
-					WantStdout: "Setting default branch for some-repo to db\n",
+					WantStdout: "No longer protecting release in some-repo\n",
 				},
 			},
 			{
-				name: "Sets global default branch",
-				g:    &git{},
-				want: &git{
-					DefaultBranch: "Maine",
+				name: "Removes last protected branch for repo",
+				g: &git{
+					ProtectedBranches: map[string][]string{
+						"some-repo": {"release"},
+					},
 				},
+				want: &git{},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cfg", "main", "set", "Maine", "-g"},
-					WantRunContents: []*commandtest.RunContents{{
-						Name: "git",
-						Args: []string{
-							"config",
-							"--get",
-							"remote.origin.url",
-						},
+					Args:            []string{"cfg", "protect", "rm", "release"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name():           "some-repo",
+						protectBranchesArg.Name(): []string{"release"},
+					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
 					}},
+					WantStdout: "No longer protecting release in some-repo\n",
+				},
+			},
+			{
+				name: "Remove fails for unprotected branch",
+				g:    &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "protect", "rm", "release"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name():     "some-repo",
-						defRepoArg.Name():   "Maine",
-						globalConfig.Name(): true,
+						repoName.Name():           "some-repo",
+						protectBranchesArg.Name(): []string{"release"},
 					}},
 					RunResponses: []*commandtest.FakeRun{{
 						Stdout: []string{"some-repo"},
 					}},
-					WantStdout: "Setting global default branch to Maine\n",
+					WantStderr: "release is not a protected branch\n",
+					WantErr:    fmt.Errorf("release is not a protected branch"),
 				},
 			},
 			{
-				name: "Unsets default branch",
-				g: &git{
-					MainBranches: map[string]string{
-						"some-repo": "db",
-						"other":     "heh",
-					},
+				name: "Shows empty pull modes",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "pull", "show"},
+					WantStdout: "No pull modes set; using merge\n",
 				},
+			},
+			{
+				name: "Sets pull mode",
+				g:    &git{},
 				want: &git{
-					MainBranches: map[string]string{
-						"other": "heh",
+					PullModes: map[string]string{
+						"some-repo": "ff-only",
 					},
 				},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cfg", "main", "unset"},
-					WantRunContents: []*commandtest.RunContents{{
-						Name: "git",
-						Args: []string{
-							"config",
-							"--get",
-							"remote.origin.url",
-						},
-					}},
+					Args:            []string{"cfg", "pull", "set", "ff-only"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name(): "some-repo",
+						repoName.Name():    "some-repo",
+						pullModeArg.Name(): "ff-only",
 					}},
 					RunResponses: []*commandtest.FakeRun{{
 						Stdout: []string{"some-repo"},
 					}},
-					WantStdout: "Deleting default branch for some-repo\n",
+					WantStdout: "Setting pull mode for some-repo to ff-only\n",
 				},
 			},
 			{
-				name: "Does nothing if no default branch map",
+				name: "Set pull mode fails for unknown mode",
 				g:    &git{},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cfg", "main", "unset"},
-					WantRunContents: []*commandtest.RunContents{{
-						Name: "git",
-						Args: []string{
-							"config",
-							"--get",
-							"remote.origin.url",
-						},
-					}},
+					Args:            []string{"cfg", "pull", "set", "squash"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name(): "some-repo",
+						repoName.Name():    "some-repo",
+						pullModeArg.Name(): "squash",
 					}},
 					RunResponses: []*commandtest.FakeRun{{
 						Stdout: []string{"some-repo"},
 					}},
-					WantStdout: "No default branch set for this repo\n",
+					WantStderr: "validation for \"MODE\" failed: [InList] argument must be one of [merge rebase ff-only]\n",
+					WantErr:    fmt.Errorf("validation for \"MODE\" failed: [InList] argument must be one of [merge rebase ff-only]"),
 				},
 			},
 			{
-				name: "Does nothing if no default branch for repo",
+				name: "Unsets pull mode",
 				g: &git{
-					MainBranches: map[string]string{
-						"other": "dflt",
+					PullModes: map[string]string{
+						"some-repo": "rebase",
 					},
 				},
+				want: &git{},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cfg", "main", "unset"},
-					WantRunContents: []*commandtest.RunContents{{
-						Name: "git",
-						Args: []string{
-							"config",
-							"--get",
-							"remote.origin.url",
-						},
+					Args:            []string{"cfg", "pull", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
+					WantData: &command.Data{Values: map[string]interface{}{
+						repoName.Name(): "some-repo",
 					}},
+					RunResponses: []*commandtest.FakeRun{{
+						Stdout: []string{"some-repo"},
+					}},
+					WantStdout: "Deleting pull mode for some-repo\n",
+				},
+			},
+			{
+				name: "Unset does nothing if no pull mode for repo",
+				g:    &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args:            []string{"cfg", "pull", "unset"},
+					WantRunContents: []*commandtest.RunContents{repoRunContents()},
 					WantData: &command.Data{Values: map[string]interface{}{
 						repoName.Name(): "some-repo",
 					}},
 					RunResponses: []*commandtest.FakeRun{{
 						Stdout: []string{"some-repo"},
 					}},
-					WantStdout: "No default branch set for this repo\n",
+					WantStdout: "No pull mode set for this repo\n",
 				},
 			},
 			{
-				name: "Unsets global default branch",
-				g: &git{
-					DefaultBranch: "Maine",
+				name: "Shows empty roster",
+				etc: &commandtest.ExecuteTestCase{
+					Args:       []string{"cfg", "roster", "show"},
+					WantStdout: "No teammates in roster\n",
+				},
+			},
+			{
+				name: "Adds teammate to roster",
+				g:    &git{},
+				want: &git{
+					Roster: map[string]string{
+						"Jane": "jane@example.com",
+					},
 				},
-				want: &git{},
 				etc: &commandtest.ExecuteTestCase{
-					Args: []string{"cfg", "main", "unset", "-g"},
-					WantRunContents: []*commandtest.RunContents{{
-						Name: "git",
-						Args: []string{
-							"config",
-							"--get",
-							"remote.origin.url",
-						},
+					Args: []string{"cfg", "roster", "add", "Jane", "jane@example.com"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						rosterNameArg.Name():  "Jane",
+						rosterEmailArg.Name(): "jane@example.com",
 					}},
+					WantStdout: "Adding Jane <jane@example.com> to roster\n",
+				},
+			},
+			{
+				name: "Removes teammate from roster",
+				g: &git{
+					Roster: map[string]string{
+						"Jane": "jane@example.com",
+						"Bob":  "bob@example.com",
+					},
+				},
+				want: &git{
+					Roster: map[string]string{
+						"Bob": "bob@example.com",
+					},
+				},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "roster", "rm", "Jane"},
 					WantData: &command.Data{Values: map[string]interface{}{
-						repoName.Name():     "some-repo",
-						globalConfig.Name(): true,
+						rosterNameArg.Name(): "Jane",
 					}},
-					RunResponses: []*commandtest.FakeRun{{
-						Stdout: []string{"some-repo"},
+					WantStdout: "Removing Jane from roster\n",
+				},
+			},
+			{
+				name: "Remove fails for unknown teammate",
+				g:    &git{},
+				etc: &commandtest.ExecuteTestCase{
+					Args: []string{"cfg", "roster", "rm", "Jane"},
+					WantData: &command.Data{Values: map[string]interface{}{
+						rosterNameArg.Name(): "Jane",
 					}},
-					WantStdout: "Deleting global default branch\n",
+					WantStderr: "Jane is not in the roster\n",
+					WantErr:    fmt.Errorf("Jane is not in the roster"),
 				},
 			},
 		} {
 			t.Run(fmt.Sprintf("[%s] %s", curOS.Name(), test.name), func(t *testing.T) {
 				commandtest.StubValue(t, &sourcerer.CurrentOS, curOS)
+				commandtest.StubValue(t, &stdin, io.Reader(strings.NewReader(test.stdin)))
+				commandtest.StubValue(t, &time.Local, time.UTC)
 				if oschk, ok := test.osChecks[curOS.Name()]; ok {
 					if test.etc.WantExecuteData == nil {
 						test.etc.WantExecuteData = &command.ExecuteData{}
@@ -1442,6 +4425,20 @@ var (
 		true,
 		true,
 	}
+	// Submodule with new (uncommitted) changes inside of it.
+	submoduleContentFile = &gitStatusFile{
+		"submodule-content",
+		[]string{"1 .M S.M. 160000 160000 160000 7efc2d1ea4fa9c61329411bae30090ff3d0cf2be 7efc2d1ea4fa9c61329411bae30090ff3d0cf2be submodule-content"},
+		true,
+		false,
+	}
+	// Submodule with a different commit checked out.
+	submoduleCommitFile = &gitStatusFile{
+		"submodule-commit",
+		[]string{"1 .M SC.. 160000 160000 160000 7efc2d1ea4fa9c61329411bae30090ff3d0cf2be 7efc2d1ea4fa9c61329411bae30090ff3d0cf2be submodule-commit"},
+		true,
+		false,
+	}
 
 	allFiles = []*gitStatusFile{
 		modifiedFile,
@@ -1454,6 +4451,8 @@ var (
 		createdCachedFile,
 		createdCachedModifiedFile,
 		createdCachedDeletedFile,
+		submoduleContentFile,
+		submoduleCommitFile,
 	}
 
 	diffNameFiles       = functional.Filter(allFiles, func(f *gitStatusFile) bool { return f.diffNameOnly })
@@ -1476,6 +4475,7 @@ func TestAutocompletePorcelain(t *testing.T) {
 				createdFile,
 				createdCachedModifiedFile,
 				createdCachedDeletedFile,
+				submoduleCommitFile,
 			},
 			ctc: &commandtest.CompleteTestCase{
 				Args:          "cmd a ",
@@ -1492,6 +4492,7 @@ func TestAutocompletePorcelain(t *testing.T) {
 				createdFile,
 				createdCachedModifiedFile,
 				createdCachedDeletedFile,
+				submoduleCommitFile,
 			},
 			ctc: &commandtest.CompleteTestCase{
 				Args:          "cmd uc ",
@@ -1527,6 +4528,8 @@ func TestAutocompletePorcelain(t *testing.T) {
 				createdCachedFile,
 				createdCachedModifiedFile,
 				createdCachedDeletedFile,
+				submoduleContentFile,
+				submoduleCommitFile,
 			},
 			ctc: &commandtest.CompleteTestCase{
 				Args:          "cmd s ",
@@ -1541,13 +4544,14 @@ func TestAutocompletePorcelain(t *testing.T) {
 			for _, f := range allFiles {
 				statuses = append(statuses, f.porcelain...)
 			}
-			test.ctc.RunResponses = []*commandtest.FakeRun{{
-				Stdout: statuses,
-			}}
-			test.ctc.WantRunContents = []*commandtest.RunContents{{
-				Name: "git",
-				Args: []string{"status", "--porcelain=v2"},
-			}}
+			test.ctc.RunResponses = []*commandtest.FakeRun{
+				{Stdout: statuses},
+				{},
+			}
+			test.ctc.WantRunContents = []*commandtest.RunContents{
+				statusRunContents(),
+				prefixRunContents(),
+			}
 
 			test.ctc.Want = &command.Autocompletion{
 				Suggestions: functional.Map[*gitStatusFile, string](test.wantFiles, func(f *gitStatusFile) string { return f.name }),
@@ -1562,6 +4566,7 @@ func TestAutocompletePorcelain(t *testing.T) {
 func TestAutocomplete(t *testing.T) {
 	for _, test := range []struct {
 		name string
+		g    *git
 		ctc  *commandtest.CompleteTestCase
 	}{
 		{
@@ -1572,13 +4577,16 @@ func TestAutocomplete(t *testing.T) {
 				Want: &command.Autocompletion{
 					Suggestions: []string{"abc", "def"},
 				},
-				WantRunContents: []*commandtest.RunContents{{
-					Name: "git",
-					Args: []string{"diff", "--name-only", "--relative"},
-				}},
-				RunResponses: []*commandtest.FakeRun{{
-					Stdout: []string{"abc", "def"},
-				}},
+				WantRunContents: []*commandtest.RunContents{
+					diffFilesRunContents(),
+					prefixRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{"abc", "def"},
+					},
+					{},
+				},
 			},
 		},
 		{
@@ -1589,12 +4597,51 @@ func TestAutocomplete(t *testing.T) {
 				Want: &command.Autocompletion{
 					Suggestions: []string{"abc"},
 				},
-				WantRunContents: []*commandtest.RunContents{{
-					Name: "git",
-					Args: []string{"diff", "--name-only", "--relative"},
+				WantRunContents: []*commandtest.RunContents{
+					diffFilesRunContents(),
+					prefixRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{"abc", "def"},
+					},
+					{},
+				},
+			},
+		},
+		{
+			name: "Submodule completions",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd sm update lib/one ",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"lib/two", "vendor/three"},
+				},
+				WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
+				RunResponses: []*commandtest.FakeRun{{
+					Stdout: []string{
+						" 0123456789abcdef0123456789abcdef01234567 lib/one (heads/main)",
+						"+fedcba9876543210fedcba9876543210fedcba98 lib/two (v1.2.3)",
+						"-1111111111111111111111111111111111111111 vendor/three",
+					},
 				}},
+			},
+		},
+		{
+			name: "Submodule completions for sync",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd sm sync v",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"vendor/three"},
+				},
+				WantRunContents: []*commandtest.RunContents{submoduleStatusRunContents()},
 				RunResponses: []*commandtest.FakeRun{{
-					Stdout: []string{"abc", "def"},
+					Stdout: []string{
+						" 0123456789abcdef0123456789abcdef01234567 lib/one (heads/main)",
+						"+fedcba9876543210fedcba9876543210fedcba98 lib/two (v1.2.3)",
+						"-1111111111111111111111111111111111111111 vendor/three",
+					},
 				}},
 			},
 		},
@@ -1606,15 +4653,238 @@ func TestAutocomplete(t *testing.T) {
 				Want: &command.Autocompletion{
 					Suggestions: []string{"b-1", "b-3"},
 				},
+				WantRunContents: []*commandtest.RunContents{
+					{
+						Name: "git",
+						Args: []string{"branch", "--list"},
+					},
+					reflogRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{"  b-1 ", "* 	b-2", "		b-3		"},
+					},
+					{},
+				},
+			},
+		},
+		{
+			name: "Grep ref completions include branches and tags",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd gr TODO --ref ",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"main", "my-branch", "v1.0", "v1.1"},
+				},
+				WantRunContents: []*commandtest.RunContents{
+					{
+						Name: "git",
+						Args: []string{"branch", "--list"},
+					},
+					{
+						Name: "git",
+						Args: []string{"tag", "--list"},
+					},
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{"  main", "* my-branch"},
+					},
+					{
+						Stdout: []string{"v1.0", "v1.1"},
+					},
+				},
+			},
+		},
+		{
+			name: "Bisect completions include branches, tags, and recent commits",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd bs start ",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"HEAD", "abc1234", "def5678", "main", "my-branch", "v1.0"},
+				},
+				WantRunContents: []*commandtest.RunContents{
+					{
+						Name: "git",
+						Args: []string{"branch", "--list"},
+					},
+					{
+						Name: "git",
+						Args: []string{"tag", "--list"},
+					},
+					{
+						Name: "git",
+						Args: []string{"log", "--format=%h", "-n20"},
+					},
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{"  main", "* my-branch"},
+					},
+					{
+						Stdout: []string{"v1.0"},
+					},
+					{
+						Stdout: []string{"abc1234", "def5678"},
+					},
+				},
+			},
+		},
+		{
+			name: "Branch completions only suggest the most recent branches",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd ch ",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"b-03", "b-04", "b-05", "b-06", "b-07", "b-08", "b-09", "b-10", "b-11", "b-12"},
+				},
+				WantRunContents: []*commandtest.RunContents{
+					{
+						Name: "git",
+						Args: []string{"branch", "--list"},
+					},
+					reflogRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{
+							"* main",
+							"  b-01",
+							"  b-02",
+							"  b-03",
+							"  b-04",
+							"  b-05",
+							"  b-06",
+							"  b-07",
+							"  b-08",
+							"  b-09",
+							"  b-10",
+							"  b-11",
+							"  b-12",
+						},
+					},
+					{
+						Stdout: []string{
+							"checkout: moving from main to b-12",
+							"checkout: moving from main to b-11",
+							"checkout: moving from main to b-10",
+							"checkout: moving from main to b-09",
+							"checkout: moving from main to b-08",
+							"checkout: moving from main to b-07",
+							"checkout: moving from main to b-06",
+							"checkout: moving from main to b-05",
+							"checkout: moving from main to b-04",
+							"checkout: moving from main to b-03",
+							"checkout: moving from main to b-02",
+							"checkout: moving from main to b-01",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Branch completions with a prefix include older branches",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd ch b-0",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"b-01", "b-02", "b-03", "b-04", "b-05", "b-06", "b-07", "b-08", "b-09"},
+				},
+				WantRunContents: []*commandtest.RunContents{{
+					Name: "git",
+					Args: []string{"branch", "--list"},
+				}},
+				RunResponses: []*commandtest.FakeRun{{
+					Stdout: []string{
+						"* main",
+						"  b-01",
+						"  b-02",
+						"  b-03",
+						"  b-04",
+						"  b-05",
+						"  b-06",
+						"  b-07",
+						"  b-08",
+						"  b-09",
+						"  b-10",
+						"  b-11",
+						"  b-12",
+					},
+				}},
+			},
+		},
+		{
+			name: "Branch completions rank recent branches first",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd ch ",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"b-1", "b-4"},
+				},
+				WantRunContents: []*commandtest.RunContents{
+					{
+						Name: "git",
+						Args: []string{"branch", "--list"},
+					},
+					reflogRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{"  b-1", "* b-2", "  b-3", "  b-4"},
+					},
+					{
+						Stdout: []string{
+							"checkout: moving from b-4 to b-2",
+							"checkout: moving from deleted to b-4",
+							"checkout: moving from b-1 to deleted",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Branch completions with a prefix include all branches",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd ch b",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"b-1", "b-3", "b-4"},
+				},
 				WantRunContents: []*commandtest.RunContents{{
 					Name: "git",
 					Args: []string{"branch", "--list"},
 				}},
 				RunResponses: []*commandtest.FakeRun{{
-					Stdout: []string{"  b-1 ", "* 	b-2", "		b-3		"},
+					Stdout: []string{"  b-1", "* b-2", "  b-3", "  b-4"},
 				}},
 			},
 		},
+		{
+			name: "Branch completions ignore reflog errors",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd ch ",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"b-1", "b-3"},
+				},
+				WantRunContents: []*commandtest.RunContents{
+					{
+						Name: "git",
+						Args: []string{"branch", "--list"},
+					},
+					reflogRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{"  b-1", "* b-2", "  b-3"},
+					},
+					{
+						Err: fmt.Errorf("oops"),
+					},
+				},
+			},
+		},
 		{
 			name: "Handles no	branch completions",
 			ctc: &commandtest.CompleteTestCase{
@@ -1639,7 +4909,206 @@ func TestAutocomplete(t *testing.T) {
 				RunResponses: []*commandtest.FakeRun{{
 					Err: fmt.Errorf("oops"),
 				}},
-				WantErr: fmt.Errorf("failed to fetch autocomplete suggestions with shell command: failed to execute shell command: oops"),
+				WantErr: fmt.Errorf("failed to list branches: failed to execute shell command: oops"),
+			},
+		},
+		{
+			name: "Commit type completions",
+			g: &git{
+				CommitPolicies: map[string]*gitrepo.CommitPolicy{
+					"test-repo": {Types: []string{"feat", "fix", "docs"}},
+				},
+			},
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd c f",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions:         []string{"feat(", "feat:", "fix(", "fix:"},
+					SpacelessCompletion: true,
+				},
+				WantRunContents: []*commandtest.RunContents{repoRunContents()},
+				RunResponses: []*commandtest.FakeRun{{
+					Stdout: []string{"test-repo"},
+				}},
+			},
+		},
+		{
+			name: "No commit type completions after first word",
+			g: &git{
+				CommitPolicies: map[string]*gitrepo.CommitPolicy{
+					"test-repo": {},
+				},
+			},
+			ctc: &commandtest.CompleteTestCase{
+				Args:            "cmd cp fix: ",
+				SkipDataCheck:   true,
+				WantRunContents: []*commandtest.RunContents{repoRunContents()},
+				RunResponses: []*commandtest.FakeRun{{
+					Stdout: []string{"test-repo"},
+				}},
+			},
+		},
+		{
+			name: "No commit type completions without commit policy",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd c ",
+				SkipDataCheck: true,
+			},
+		},
+		{
+			name: "Co-author completions",
+			g: &git{
+				Roster: map[string]string{
+					"Jane": "jane@example.com",
+				},
+			},
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd c did things --co ",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{
+						"Bob\\ Smith\\ <bob@example.com>",
+						"Jane",
+						"Jane\\ Doe\\ <jane@example.com>",
+					},
+				},
+				WantRunContents: []*commandtest.RunContents{{
+					Name: "git",
+					Args: []string{"shortlog", "-sne", "HEAD"},
+				}},
+				RunResponses: []*commandtest.FakeRun{{
+					Stdout: []string{
+						"    12\tJane Doe <jane@example.com>",
+						"     3\tBob Smith <bob@example.com>",
+					},
+				}},
+			},
+		},
+		{
+			name: "Fuzzy file completions match subsequences",
+			g:    &git{FuzzyCompletion: true},
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd a srctst",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"sourcecontrol_test.go", "src/test.go"},
+				},
+				WantRunContents: []*commandtest.RunContents{
+					statusRunContents(),
+					prefixRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{
+							"? sourcecontrol.go",
+							"? sourcecontrol_test.go",
+							"? src/test.go",
+						},
+					},
+					{},
+				},
+			},
+		},
+		{
+			name: "Fuzzy file completions rank file name prefixes first",
+			g:    &git{FuzzyCompletion: true},
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd a main",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"cmd/main.go", "pkg/main_test.go"},
+				},
+				WantRunContents: []*commandtest.RunContents{
+					statusRunContents(),
+					prefixRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{
+							"? cmd/main.go",
+							"? domain.go",
+							"? pkg/main_test.go",
+							"? m/a/i/n.go",
+						},
+					},
+					{},
+				},
+			},
+		},
+		{
+			name: "File completions don't fuzzy match by default",
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd a srctst",
+				SkipDataCheck: true,
+				WantRunContents: []*commandtest.RunContents{
+					statusRunContents(),
+					prefixRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{
+							"? sourcecontrol_test.go",
+						},
+					},
+					{},
+				},
+			},
+		},
+		{
+			name: "Fuzzy diff file completions",
+			g:    &git{FuzzyCompletion: true},
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd d a/c",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"a/b/c.go", "a/bc.go"},
+				},
+				WantRunContents: []*commandtest.RunContents{
+					diffFilesRunContents(),
+					prefixRunContents(),
+				},
+				RunResponses: []*commandtest.FakeRun{
+					{
+						Stdout: []string{"a/b/c.go", "a/bc.go", "b/a.go"},
+					},
+					{},
+				},
+			},
+		},
+		{
+			name: "Fuzzy branch completions",
+			g:    &git{FuzzyCompletion: true},
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd bd fix-log",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"abc-123-fix-login-bug"},
+				},
+				WantRunContents: []*commandtest.RunContents{{
+					Name: "git",
+					Args: []string{"branch", "--list"},
+				}},
+				RunResponses: []*commandtest.FakeRun{{
+					Stdout: []string{"  abc-123-fix-login-bug", "* main", "  fix-other-log"},
+				}},
+			},
+		},
+		{
+			name: "Fuzzy checkout completions",
+			g:    &git{FuzzyCompletion: true},
+			ctc: &commandtest.CompleteTestCase{
+				Args:          "cmd ch fxlg",
+				SkipDataCheck: true,
+				Want: &command.Autocompletion{
+					Suggestions: []string{"abc-123-fix-login-bug", "fix-other-log"},
+				},
+				WantRunContents: []*commandtest.RunContents{{
+					Name: "git",
+					Args: []string{"branch", "--list"},
+				}},
+				RunResponses: []*commandtest.FakeRun{{
+					Stdout: []string{"  abc-123-fix-login-bug", "* main", "  fix-other-log"},
+				}},
 			},
 		},
 		{
@@ -1649,7 +5118,7 @@ func TestAutocomplete(t *testing.T) {
 				SkipDataCheck: true,
 				WantRunContents: []*commandtest.RunContents{{
 					Name: "git",
-					Args: []string{"status", "--porcelain=v2"},
+					Args: []string{"-c", "status.relativePaths=false", "status", "--porcelain=v2"},
 				}},
 				RunResponses: []*commandtest.FakeRun{{
 					Err: fmt.Errorf("whoops"),
@@ -1659,8 +5128,10 @@ func TestAutocomplete(t *testing.T) {
 		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			g := &git{}
-			test.ctc.Node = g.Node()
+			if test.g == nil {
+				test.g = &git{}
+			}
+			test.ctc.Node = test.g.Node()
 			commandertest.AutocompleteTest(t, test.ctc)
 		})
 	}
@@ -0,0 +1,96 @@
+package sourcecontrol
+
+import (
+	"fmt"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+)
+
+var (
+	submodulePathsArg = commander.ListArg[string]("SUBMODULES", "Submodules (defaults to all submodules)", 0, command.UnboundedList, submoduleCompleter[[]string]())
+)
+
+// submoduleCompleter completes the paths of the repo's submodules.
+func submoduleCompleter[T any]() commander.Completer[T] {
+	return commander.CompleterFromFunc(func(t T, d *command.Data) (*command.Completion, error) {
+		subs, err := gitRepo(d).Submodules()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list submodules: %v", err)
+		}
+
+		var paths []string
+		for _, s := range subs {
+			paths = append(paths, s.Path)
+		}
+		return matchCompletion(t, d, &command.Completion{
+			Suggestions: paths,
+			Distinct:    true,
+		}), nil
+	})
+}
+
+// showSubmodules returns a processor that prints the state of the repo's
+// submodules. If `bestEffort` is true, then failing to get the submodules
+// (e.g. because of a broken .gitmodules file) only prints a warning.
+func showSubmodules(bestEffort bool) command.Processor {
+	return &commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+		subs, err := gitRepo(d).Submodules()
+		if err != nil {
+			if bestEffort {
+				o.Stderrf("Warning: failed to list submodules: %v\n", err)
+				return nil
+			}
+			return o.Annotatef(err, "failed to list submodules")
+		}
+		if len(subs) == 0 {
+			return nil
+		}
+
+		o.Stdoutln("Submodules:")
+		for _, s := range subs {
+			o.Stdoutf("  %s: %s (%s)\n", s.Path, s.State, shortSHA(s.SHA))
+		}
+		o.Stdoutln()
+		return nil
+	}}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func submoduleNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"update": commander.SerialNodes(
+				commander.Description("Initialize and update submodules (recursively)"),
+				submodulePathsArg,
+				sshNode,
+				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+					return []string{commandString(gitrepo.SubmoduleUpdate(submodulePathsArg.Get(d)...))}, nil
+				}),
+			),
+			"sync": commander.SerialNodes(
+				commander.Description("Sync submodule remote URLs from .gitmodules (recursively)"),
+				submodulePathsArg,
+				commander.ExecutableProcessor(func(o command.Output, d *command.Data) ([]string, error) {
+					return []string{commandString(gitrepo.SubmoduleSync(submodulePathsArg.Get(d)...))}, nil
+				}),
+			),
+			"pull": commander.SerialNodes(
+				commander.Description("Update every submodule to the latest commit of its remote branch (recursively)"),
+				sshNode,
+				executableCommands(gitrepo.SubmodulePull()),
+			),
+		},
+		Default: commander.SerialNodes(
+			commander.Description("Show submodule states"),
+			showSubmodules(false),
+		),
+	}
+}
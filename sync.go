@@ -0,0 +1,187 @@
+package sourcecontrol
+
+import (
+	"fmt"
+
+	"github.com/leep-frog/command/command"
+	"github.com/leep-frog/command/commander"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	syncRemote = "origin"
+
+	mergeStrategy  = "merge"
+	rebaseStrategy = "rebase"
+
+	defaultSyncStrategy = mergeStrategy
+)
+
+var (
+	syncStrategies     = []string{mergeStrategy, rebaseStrategy}
+	syncStrategyOption = []commander.ArgumentOption[string]{
+		commander.SimpleCompleter[string](syncStrategies...),
+		commander.InList(syncStrategies...),
+	}
+	syncStrategyArg  = commander.Arg[string]("STRATEGY", "How to integrate the default branch into the current branch", syncStrategyOption...)
+	syncStrategyFlag = commander.Flag[string]("strategy", 's', "How to integrate the default branch into the current branch (overrides the repo setting)", syncStrategyOption...)
+)
+
+// syncStrategy returns the strategy to use for `g sync` in the current repo.
+func (g *git) syncStrategy(d *command.Data) string {
+	if syncStrategyFlag.Provided(d) {
+		return syncStrategyFlag.Get(d)
+	}
+	if s, ok := g.SyncStrategies[repoName.Get(d)]; ok {
+		return s
+	}
+	return defaultSyncStrategy
+}
+
+func (g *git) syncNode() command.Node {
+	return commander.SerialNodes(
+		commander.Description("Fetch, fast-forward the default branch, merge or rebase it into the current branch, and push"),
+		commander.FlagProcessor(syncStrategyFlag),
+		repoName,
+		&commander.ExecutorProcessor{F: g.sync},
+	)
+}
+
+func (g *git) sync(o command.Output, d *command.Data) error {
+	repo := gitRepo(d)
+	def := g.GetDefaultBranch(d)
+	remoteDef := fmt.Sprintf("%s/%s", syncRemote, def)
+	strategy := g.syncStrategy(d)
+
+	cur, err := repo.CurrentBranch()
+	if err != nil {
+		return o.Annotatef(err, "failed to get current branch")
+	}
+
+	o.Stdoutf("Fetching %s\n", syncRemote)
+	if _, err := repo.Run(gitrepo.FetchRemote(syncRemote)); err != nil {
+		return o.Annotatef(err, "failed to fetch")
+	}
+	if !repo.RefExists(remoteDef) {
+		return o.Stderrf("%s does not exist\n", remoteDef)
+	}
+
+	o.Stdoutf("Fast-forwarding %s to %s\n", def, remoteDef)
+	if cur == def {
+		if _, err := repo.Run(gitrepo.NewCommand("merge", "--ff-only", remoteDef)); err != nil {
+			return o.Annotatef(err, "failed to fast-forward %s (it has diverged from %s)", def, remoteDef)
+		}
+	} else {
+		if err := repo.FastForward(def, remoteDef); err != nil {
+			return o.Annotatef(err, "failed to fast-forward %s (it has diverged from %s)", def, remoteDef)
+		}
+
+		integrate := gitrepo.NewCommand("merge", "--no-edit", def)
+		if strategy == rebaseStrategy {
+			o.Stdoutf("Rebasing %s onto %s\n", cur, def)
+			integrate = gitrepo.Rebase(def)
+		} else {
+			o.Stdoutf("Merging %s into %s\n", def, cur)
+		}
+		if _, err := repo.Run(integrate); err != nil {
+			return syncConflicts(o, repo, strategy, def, err)
+		}
+	}
+
+	if !repo.HasUpstream(cur) {
+		o.Stdoutf("No upstream set for %s; not pushing\n", cur)
+	} else {
+		// Rebasing rewrites commits that may already have been pushed, but
+		// protected branches are never force pushed.
+		force := strategy == rebaseStrategy && cur != def
+		if force && g.isForcePushProtected(d, cur) {
+			force = false
+			o.Stdoutf("Pushing %s (without --force-with-lease since it is a protected branch)\n", cur)
+		} else {
+			o.Stdoutf("Pushing %s\n", cur)
+		}
+		push := gitrepo.Push(&gitrepo.PushOptions{ForceWithLease: force})
+		if _, err := repo.Run(push); err != nil {
+			return o.Annotatef(err, "failed to push")
+		}
+	}
+
+	o.Stdoutf("Synced %s with %s\n", cur, remoteDef)
+	return nil
+}
+
+// syncConflicts prints a summary of the conflicts that stopped the merge (or
+// rebase) and returns an error.
+func syncConflicts(o command.Output, repo *gitrepo.Repo, strategy, def string, err error) error {
+	conflicts, cErr := repo.Conflicts()
+	if cErr != nil || len(conflicts) == 0 {
+		return o.Annotatef(err, "failed to %s %s", strategy, def)
+	}
+
+	o.Stderrf("Failed to %s %s due to conflicts in:\n", strategy, def)
+	for _, c := range conflicts {
+		o.Stderrf("  %s\n", c)
+	}
+	if strategy == rebaseStrategy {
+		return o.Stderrf("Resolve the conflicts, add the files, and run `g rb c` (or `g rb a` to undo the rebase)\n")
+	}
+	return o.Stderrf("Resolve the conflicts, add the files, and run `git merge --continue` (or `git merge --abort` to undo the merge)\n")
+}
+
+func (g *git) showSyncStrategies(o command.Output) {
+	if len(g.SyncStrategies) == 0 {
+		o.Stdoutf("No sync strategies set; using %s\n", defaultSyncStrategy)
+		return
+	}
+
+	keys := maps.Keys(g.SyncStrategies)
+	slices.Sort(keys)
+	for _, k := range keys {
+		o.Stdoutf("%s: %s\n", k, g.SyncStrategies[k])
+	}
+}
+
+func (g *git) syncStrategyConfigNode() command.Node {
+	return &commander.BranchNode{
+		Branches: map[string]command.Node{
+			"show": commander.SerialNodes(
+				commander.Description("Show sync strategies"),
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					g.showSyncStrategies(o)
+					return nil
+				}},
+			),
+			"set": commander.SerialNodes(
+				commander.Description("Set how `g sync` integrates the default branch in this repo"),
+				repoName,
+				syncStrategyArg,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					if g.SyncStrategies == nil {
+						g.SyncStrategies = map[string]string{}
+					}
+					g.SyncStrategies[repoName.Get(d)] = syncStrategyArg.Get(d)
+					g.changed = true
+					o.Stdoutf("Setting sync strategy for %s to %s\n", repoName.Get(d), syncStrategyArg.Get(d))
+					return nil
+				}},
+			),
+			"unset": commander.SerialNodes(
+				commander.Description("Use the default sync strategy in this repo"),
+				repoName,
+				&commander.ExecutorProcessor{F: func(o command.Output, d *command.Data) error {
+					rn := repoName.Get(d)
+					if _, ok := g.SyncStrategies[rn]; !ok {
+						o.Stdoutln("No sync strategy set for this repo")
+						return nil
+					}
+					delete(g.SyncStrategies, rn)
+					g.changed = true
+					o.Stdoutln("Deleting sync strategy for", rn)
+					return nil
+				}},
+			),
+		},
+	}
+}
@@ -0,0 +1,240 @@
+package sourcecontrol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leep-frog/command/commandertest"
+	"github.com/leep-frog/command/commandtest"
+	"github.com/leep-frog/sourcecontrol/gitrepo"
+	"github.com/leep-frog/sourcecontrol/gitrepo/gitrepotest"
+)
+
+// setupSync creates a repo whose remote default branch has a commit (changing
+// `upstreamFile`) that isn't in the local default branch, and checks out a
+// `feature` branch with a commit (changing `featureFile`).
+func setupSync(tr *gitrepotest.TestRepo, upstreamFile, featureFile string, pushFeature bool) {
+	tr.AddRemote("origin")
+	tr.Git("push", "-u", "origin", "main")
+
+	tr.WriteFile(upstreamFile, "upstream\n")
+	tr.Commit("Upstream change")
+	tr.Git("push")
+	tr.Git("reset", "--hard", "HEAD~1")
+
+	tr.Git("checkout", "-b", "feature")
+	tr.WriteFile(featureFile, "feature\n")
+	tr.Commit("Feature change")
+	if pushFeature {
+		tr.Git("push", "-u", "origin", "feature")
+	}
+}
+
+func TestSyncWithRealRepo(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		// strategy is the configured sync strategy for the repo.
+		strategy string
+		// protected are the configured protected branches for the repo.
+		protected  []string
+		setup      func(*gitrepotest.TestRepo)
+		args       []string
+		wantStdout []string
+		wantStderr []string
+		wantErr    error
+		check      func(*testing.T, *gitrepotest.TestRepo)
+	}{
+		{
+			name: "merges default branch and pushes",
+			setup: func(tr *gitrepotest.TestRepo) {
+				setupSync(tr, "upstream.txt", "feature.txt", true)
+			},
+			wantStdout: []string{
+				"Fetching origin",
+				"Fast-forwarding main to origin/main",
+				"Merging main into feature",
+				"Pushing feature",
+				"Synced feature with origin/main",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"Merge branch 'main' into feature", "Upstream change", "Feature change", "Initial commit"}, tr.Git("log", "--topo-order", "--format=%s")); diff != "" {
+					t.Errorf("Sync produced incorrect log (-want, +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(tr.Git("rev-parse", "origin/main"), tr.Git("rev-parse", "main")); diff != "" {
+					t.Errorf("Sync didn't fast-forward main (-want, +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(tr.Git("rev-parse", "HEAD"), tr.Git("rev-parse", "origin/feature")); diff != "" {
+					t.Errorf("Sync didn't push feature (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name:     "rebases when configured for the repo",
+			strategy: rebaseStrategy,
+			setup: func(tr *gitrepotest.TestRepo) {
+				setupSync(tr, "upstream.txt", "feature.txt", true)
+			},
+			wantStdout: []string{
+				"Fetching origin",
+				"Fast-forwarding main to origin/main",
+				"Rebasing feature onto main",
+				"Pushing feature",
+				"Synced feature with origin/main",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"Feature change", "Upstream change", "Initial commit"}, tr.Git("log", "--format=%s")); diff != "" {
+					t.Errorf("Sync produced incorrect log (-want, +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(tr.Git("rev-parse", "HEAD"), tr.Git("rev-parse", "origin/feature")); diff != "" {
+					t.Errorf("Sync didn't push feature (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name:      "rebases a protected branch without force pushing",
+			strategy:  rebaseStrategy,
+			protected: []string{"feature"},
+			setup: func(tr *gitrepotest.TestRepo) {
+				setupSync(tr, "upstream.txt", "feature.txt", false)
+				// Only push the commit that the rebase won't rewrite.
+				tr.Git("push", "origin", "HEAD~1:refs/heads/feature")
+				tr.Git("branch", "-u", "origin/feature")
+			},
+			wantStdout: []string{
+				"Fetching origin",
+				"Fast-forwarding main to origin/main",
+				"Rebasing feature onto main",
+				"Pushing feature (without --force-with-lease since it is a protected branch)",
+				"Synced feature with origin/main",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"Feature change", "Upstream change", "Initial commit"}, tr.Git("log", "--format=%s")); diff != "" {
+					t.Errorf("Sync produced incorrect log (-want, +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(tr.Git("rev-parse", "HEAD"), tr.Git("rev-parse", "origin/feature")); diff != "" {
+					t.Errorf("Sync didn't push feature (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name:     "strategy flag overrides the repo setting",
+			strategy: rebaseStrategy,
+			setup: func(tr *gitrepotest.TestRepo) {
+				setupSync(tr, "upstream.txt", "feature.txt", false)
+			},
+			args: []string{"-s", "merge"},
+			wantStdout: []string{
+				"Fetching origin",
+				"Fast-forwarding main to origin/main",
+				"Merging main into feature",
+				"No upstream set for feature; not pushing",
+				"Synced feature with origin/main",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"Merge branch 'main' into feature", "Upstream change", "Feature change", "Initial commit"}, tr.Git("log", "--topo-order", "--format=%s")); diff != "" {
+					t.Errorf("Sync produced incorrect log (-want, +got):\n%s", diff)
+				}
+				if err := tr.GitErr("rev-parse", "--verify", "--quiet", "origin/feature"); err == nil {
+					t.Errorf("Sync pushed a branch without an upstream")
+				}
+			},
+		},
+		{
+			name: "fast-forwards the default branch when it is checked out",
+			setup: func(tr *gitrepotest.TestRepo) {
+				setupSync(tr, "upstream.txt", "feature.txt", false)
+				tr.Git("checkout", "main")
+			},
+			wantStdout: []string{
+				"Fetching origin",
+				"Fast-forwarding main to origin/main",
+				"Pushing main",
+				"Synced main with origin/main",
+			},
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				if diff := cmp.Diff([]string{"Upstream change", "Initial commit"}, tr.Git("log", "--format=%s")); diff != "" {
+					t.Errorf("Sync produced incorrect log (-want, +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "stops with a summary on merge conflicts",
+			setup: func(tr *gitrepotest.TestRepo) {
+				setupSync(tr, "README.md", "README.md", true)
+			},
+			wantStdout: []string{
+				"Fetching origin",
+				"Fast-forwarding main to origin/main",
+				"Merging main into feature",
+			},
+			wantStderr: []string{
+				"Failed to merge main due to conflicts in:",
+				"  README.md",
+				"Resolve the conflicts, add the files, and run `git merge --continue` (or `git merge --abort` to undo the merge)",
+			},
+			wantErr: fmt.Errorf("Resolve the conflicts, add the files, and run `git merge --continue` (or `git merge --abort` to undo the merge)"),
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Unmerged, XY: "UU", Submodule: "N...", Path: "README.md"},
+				})
+			},
+		},
+		{
+			name: "stops with a summary on rebase conflicts",
+			setup: func(tr *gitrepotest.TestRepo) {
+				setupSync(tr, "README.md", "README.md", true)
+			},
+			args: []string{"--strategy", "rebase"},
+			wantStdout: []string{
+				"Fetching origin",
+				"Fast-forwarding main to origin/main",
+				"Rebasing feature onto main",
+			},
+			wantStderr: []string{
+				"Failed to rebase main due to conflicts in:",
+				"  README.md",
+				"Resolve the conflicts, add the files, and run `g rb c` (or `g rb a` to undo the rebase)",
+			},
+			wantErr: fmt.Errorf("Resolve the conflicts, add the files, and run `g rb c` (or `g rb a` to undo the rebase)"),
+			check: func(t *testing.T, tr *gitrepotest.TestRepo) {
+				wantStatus(t, tr, []*gitrepo.StatusEntry{
+					{Type: gitrepo.Unmerged, XY: "UU", Submodule: "N...", Path: "README.md"},
+				})
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tr := gitrepotest.NewWithCommit(t)
+			test.setup(tr)
+			stubRepo(t, tr)
+
+			g := &git{}
+			if test.strategy != "" {
+				// The repo name is the path of the remote.
+				remote := tr.Git("config", "--get", "remote.origin.url")[0]
+				g.SyncStrategies = map[string]string{remote: test.strategy}
+			}
+			if len(test.protected) > 0 {
+				remote := tr.Git("config", "--get", "remote.origin.url")[0]
+				g.ProtectedBranches = map[string][]string{remote: test.protected}
+			}
+
+			etc := &commandtest.ExecuteTestCase{
+				Node:          g.Node(),
+				Args:          append([]string{"sync"}, test.args...),
+				SkipDataCheck: true,
+				WantErr:       test.wantErr,
+			}
+			if len(test.wantStdout) > 0 {
+				etc.WantStdout = strings.Join(test.wantStdout, "\n") + "\n"
+			}
+			if len(test.wantStderr) > 0 {
+				etc.WantStderr = strings.Join(test.wantStderr, "\n") + "\n"
+			}
+			commandertest.ExecuteTest(t, etc)
+			test.check(t, tr)
+		})
+	}
+}